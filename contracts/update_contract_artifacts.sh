@@ -4,6 +4,9 @@ set -e
 mkdir -p ../nodemgr/internal/lib/reti/artifacts/contracts/
 cp ./contracts/artifacts/*arc32* ../nodemgr/internal/lib/reti/artifacts/contracts/
 
+# Regenerate the typed nodemgr contract clients
+(cd ../nodemgr && go generate ./internal/lib/reti/contracts)
+
 # Update UI contract clients
 rm -rf ../ui/src/contracts/
 mkdir -p ../ui/src/contracts/
@@ -11,6 +11,7 @@ import (
 	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
 	"github.com/joho/godotenv"
@@ -18,9 +19,11 @@ import (
 	"golang.org/x/term"
 
 	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/history"
 	"github.com/TxnLab/reti/internal/lib/misc"
 	"github.com/TxnLab/reti/internal/lib/nfdapi/swagger"
 	"github.com/TxnLab/reti/internal/lib/nfdonchain"
+	"github.com/TxnLab/reti/internal/lib/remotesigner"
 	"github.com/TxnLab/reti/internal/lib/reti"
 )
 
@@ -113,12 +116,102 @@ func initApp() *RetiApp {
 				Destination: &appConfig.retiNodeNum,
 				OnlyOnce:    true,
 			},
+			&cli.StringFlag{
+				Name:    "signer",
+				Usage:   "How transactions are signed: local (mnemonics from *_MNEMONIC env vars), kmd (ALGO_KMD_WALLET/ALGO_KMD_PASSWORD wallet of the node's kmd, or ALGO_KMD_URL/ALGO_KMD_TOKEN) or remote (signing service at --remotesigner)",
+				Value:   "local",
+				Sources: cli.EnvVars("RETI_SIGNER"),
+			},
+			&cli.StringFlag{
+				Name:    "remotesigner",
+				Usage:   "https url of the remote signing service (see 'signer serve') when using the remote signer",
+				Sources: cli.EnvVars("RETI_REMOTE_SIGNER_URL"),
+			},
+			&cli.StringFlag{
+				Name:    "remotesignercert",
+				Usage:   "Client certificate file to authenticate to the remote signing service with",
+				Sources: cli.EnvVars("RETI_REMOTE_SIGNER_CERT"),
+			},
+			&cli.StringFlag{
+				Name:    "remotesignerkey",
+				Usage:   "Key file of the client certificate",
+				Sources: cli.EnvVars("RETI_REMOTE_SIGNER_KEY"),
+			},
+			&cli.StringFlag{
+				Name:    "remotesignerca",
+				Usage:   "CA certificate file the remote signing service's certificate must be signed by",
+				Sources: cli.EnvVars("RETI_REMOTE_SIGNER_CA"),
+			},
+			&cli.StringFlag{
+				Name:    "signingpolicy",
+				Usage:   "Path of a json signing policy restricting the apps, methods, fees and payments each account may sign for - anything else is refused.  Requires --history, where the daily totals are kept",
+				Sources: cli.EnvVars("RETI_SIGNING_POLICY"),
+			},
+			&cli.StringFlag{
+				Name:    "externalsigners",
+				Usage:   "Comma separated addresses which are signed for elsewhere (ie: a cold multisig owner) - transactions they must sign have to be exported w/ --export-unsigned",
+				Sources: cli.EnvVars("RETI_EXTERNAL_SIGNERS"),
+			},
+			&cli.StringFlag{
+				Name:    "keystore",
+				Usage:   "Path of an encrypted keystore (see the keystore command) holding keys to sign with - used alongside the signer",
+				Sources: cli.EnvVars("RETI_KEYSTORE"),
+			},
+			&cli.StringFlag{
+				Name:    "keystorepassfile",
+				Usage:   "File containing the keystore passphrase - it's prompted for if not set",
+				Sources: cli.EnvVars("RETI_KEYSTORE_PASSFILE"),
+			},
+			&cli.StringFlag{
+				Name:    "secretsdir",
+				Usage:   "Directory of file secrets (ie: /run/secrets as mounted by Docker/Kubernetes) - each file a secret named by the file",
+				Sources: cli.EnvVars("RETI_SECRETS_DIR"),
+			},
+			&cli.StringFlag{
+				Name:    "vaultaddr",
+				Usage:   "Address of a Vault (KV v2 compatible) server to fetch secrets from - the token is taken from VAULT_TOKEN",
+				Sources: cli.EnvVars("RETI_VAULT_ADDR", "VAULT_ADDR"),
+			},
+			&cli.StringFlag{
+				Name:    "vaultsecret",
+				Usage:   "Mount and path (ie: secret/reti) of the vault secret whose keys are our secrets",
+				Sources: cli.EnvVars("RETI_VAULT_SECRET"),
+			},
+			&cli.StringFlag{
+				Name:    "secretsfile",
+				Usage:   "Path of an encrypted secrets file (see 'secrets encrypt') to fetch secrets from",
+				Sources: cli.EnvVars("RETI_SECRETS_FILE"),
+			},
+			&cli.StringFlag{
+				Name:    "secretspassfile",
+				Usage:   "File containing the secrets file passphrase - it's prompted for if not set",
+				Sources: cli.EnvVars("RETI_SECRETS_PASSFILE"),
+			},
+			&cli.DurationFlag{
+				Name:    "secretsrefresh",
+				Usage:   "How often secrets are re-fetched from the secret providers (so they can be rotated) - 0 to disable",
+				Value:   5 * time.Minute,
+				Sources: cli.EnvVars("RETI_SECRETS_REFRESH"),
+			},
+			&cli.StringFlag{
+				Name:    "history",
+				Usage:   "Path of the (local) database where actions taken are recorded.  History isn't kept if not set",
+				Sources: cli.EnvVars("RETI_HISTORY_DB"),
+			},
 		},
 		Commands: []*cli.Command{
 			GetDaemonCmdOpts(),
 			GetValidatorCmdOpts(),
 			GetPoolCmdOpts(),
 			GetKeyCmdOpts(),
+			GetLeaseCmdOpts(),
+			GetStakerCmdOpts(),
+			GetRewardsCmdOpts(),
+			GetHistoryCmdOpts(),
+			GetKeystoreCmdOpts(),
+			GetSignerCmdOpts(),
+			GetTxCmdOpts(),
+			GetSecretsCmdOpts(),
 		},
 	}
 	return appConfig
@@ -129,10 +222,13 @@ type RetiApp struct {
 	logger     *slog.Logger
 	signer     algo.MultipleWalletSigner
 	algoClient *algod.Client
+	chain      algo.Chain
 	nfdApi     *swagger.APIClient
 	nfdOnChain *nfdonchain.NfdApi
 
 	retiClient *reti.Reti
+	// history is nil if not enabled
+	history *history.Store
 
 	// just here for flag bootstrapping destination
 	retiAppID       uint64
@@ -152,6 +248,13 @@ func (ac *RetiApp) initClients(ctx context.Context, cmd *cli.Command) error {
 			return err
 		}
 	}
+	// secrets are needed by standalone commands as well (ie: the lease token)
+	if err := ac.initSecrets(ctx, cmd); err != nil {
+		return err
+	}
+	if isStandaloneCommand(cmd) {
+		return nil
+	}
 	// quick validity check on possible network names...
 	switch network {
 	case "sandbox", "fnet", "betanet", "testnet", "mainnet", "voitestnet":
@@ -205,8 +308,9 @@ func (ac *RetiApp) initClients(ctx context.Context, cmd *cli.Command) error {
 		return fmt.Errorf("the id of the Reti Validator contract must be set using either -retiid or RETI_APPID env var!")
 	}
 
-	// This will load and initialize mnemonics from the environment - and handles all 'local' signing for the app
-	ac.signer = algo.NewLocalKeyStore(ac.logger)
+	if err = ac.initSigner(ctx, cmd, cfg); err != nil {
+		return err
+	}
 
 	// Inititialize NFD API (if even used)
 	nfdApiCfg := swagger.NewConfiguration()
@@ -215,6 +319,27 @@ func (ac *RetiApp) initClients(ctx context.Context, cmd *cli.Command) error {
 	_, _ = algoClient, api
 
 	ac.algoClient = algoClient
+	ac.chain = algo.NewAlgodChain(algoClient)
+	// accounts may be rekeyed, so sign w/ the keys of the addresses they're rekeyed to
+	ac.signer = algo.NewRekeyAwareSigner(ac.logger, ac.signer, ac.chain)
+	ac.history, err = getHistoryStore(cmd.String("history"))
+	if err != nil {
+		return err
+	}
+	if policyFile := cmd.String("signingpolicy"); policyFile != "" {
+		policy, err := algo.LoadSigningPolicy(policyFile)
+		if err != nil {
+			return err
+		}
+		if ac.history == nil {
+			return fmt.Errorf("--signingpolicy requires --history, where the policy's daily totals are kept so they still apply after a restart")
+		}
+		misc.Infof(ac.logger, "Signing restricted by the policy in:%s, %d accounts", policyFile, len(policy.Accounts))
+		ac.signer = algo.NewPolicySigner(ac.logger, ac.signer, policy, ac.chain, ac.history)
+	}
+	if external := splitList(cmd.String("externalsigners")); len(external) > 0 {
+		ac.signer = algo.NewExternalSigner(ac.signer, external...)
+	}
 	ac.nfdApi = api
 	nfdOnChain, err := nfdonchain.NewNfdApi(algoClient, cmd.String("network"))
 	if err != nil {
@@ -223,14 +348,90 @@ func (ac *RetiApp) initClients(ctx context.Context, cmd *cli.Command) error {
 	ac.nfdOnChain = nfdOnChain
 
 	// Initialize the 'reti' client
-	retiClient, err := reti.New(ac.retiAppID, ac.logger, ac.algoClient, ac.signer, ac.retiValidatorID, ac.retiNodeNum)
+	retiClient, err := reti.New(ac.retiAppID, ac.logger, ac.chain, ac.signer, ac.retiValidatorID, ac.retiNodeNum)
 	if err != nil {
 		return err
 	}
 	ac.retiClient = retiClient
+
+	if ac.history != nil {
+		retiClient.AddTxnObserver(ac.recordTxnHistory)
+	}
 	return retiClient.LoadState(ctx)
 }
 
+// initSigner initializes the signer selected via --signer (and the keystore, if set)
+func (ac *RetiApp) initSigner(ctx context.Context, cmd *cli.Command, cfg algo.NetworkConfig) error {
+	var err error
+	switch signer := cmd.String("signer"); signer {
+	case "local":
+		// This will load and initialize mnemonics from the environment - and handles all 'local' signing for the app
+		ac.signer = algo.NewLocalKeyStore(ac.logger)
+	case "kmd":
+		ac.signer, err = algo.NewKMDSigner(ac.logger, cfg)
+		if err != nil {
+			return err
+		}
+	case "remote":
+		ac.signer, err = remotesigner.NewClient(ctx, ac.logger, remotesigner.ClientConfig{
+			URL:      cmd.String("remotesigner"),
+			CertFile: cmd.String("remotesignercert"),
+			KeyFile:  cmd.String("remotesignerkey"),
+			CAFile:   cmd.String("remotesignerca"),
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown signer:%s - must be local, kmd or remote", signer)
+	}
+	if cmd.String("keystore") != "" {
+		keystore, err := openKeystore(cmd)
+		if err != nil {
+			return err
+		}
+		ac.signer = algo.NewCombinedSigner(keystore, ac.signer)
+	}
+	return nil
+}
+
+// initSecrets initializes the secret providers secrets are fetched from (after environment variables) - in order,
+// the secrets directory, vault and the encrypted secrets file - refreshing them every --secretsrefresh.
+func (ac *RetiApp) initSecrets(ctx context.Context, cmd *cli.Command) error {
+	var providers []misc.SecretProvider
+	if dir := cmd.String("secretsdir"); dir != "" {
+		providers = append(providers, misc.NewFileSecretProvider(dir))
+	}
+	if vaultAddr := cmd.String("vaultaddr"); vaultAddr != "" {
+		provider, err := misc.NewVaultSecretProvider(misc.VaultConfig{
+			Addr:   vaultAddr,
+			Secret: cmd.String("vaultsecret"),
+			Token:  func() string { return misc.GetSecret("VAULT_TOKEN") },
+		})
+		if err != nil {
+			return err
+		}
+		providers = append(providers, provider)
+	}
+	if secretsFile := cmd.String("secretsfile"); secretsFile != "" {
+		passphrase, err := readPassphrase(cmd, "secretspassfile", "secrets file", false)
+		if err != nil {
+			return err
+		}
+		providers = append(providers, misc.NewEncryptedFileSecretProvider(secretsFile, passphrase))
+	}
+	if len(providers) == 0 {
+		return nil
+	}
+	if err := misc.InitSecrets(ctx, ac.logger, providers...); err != nil {
+		return err
+	}
+	if interval := cmd.Duration("secretsrefresh"); interval > 0 {
+		go misc.RefreshSecretsEvery(ctx, ac.logger, interval)
+	}
+	return nil
+}
+
 func setIntFromEnv(val *uint64, envName string) error {
 	if strVal := os.Getenv(envName); strVal != "" {
 		intVal, err := strconv.ParseUint(strVal, 10, 64)
@@ -242,6 +443,15 @@ func setIntFromEnv(val *uint64, envName string) error {
 	return nil
 }
 
+// standaloneCommand is set as the Metadata of top-level commands which don't talk to algod or the reti contracts
+// at all (ie: helper services) - so client initialization is skipped for them.
+var standaloneCommand = map[string]any{"standalone": true}
+
+func isStandaloneCommand(cmd *cli.Command) bool {
+	subCmd := cmd.Command(cmd.Args().First())
+	return subCmd != nil && subCmd.Metadata["standalone"] == true
+}
+
 func checkConfigured(ctx context.Context, command *cli.Command) error {
 	if !App.retiClient.IsConfigured() {
 		return errors.New("validator not configured")
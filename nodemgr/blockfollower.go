@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+const (
+	// number of recent block timestamps used for the rolling block time average
+	blockTimeWindow = 50
+	// if we fall further behind than this (algod catching up, long outage, etc.) we skip fetching the headers in
+	// between, and just start tracking again from the latest round.
+	maxRoundsToBackfill = blockTimeWindow
+)
+
+var (
+	promLastRound = promauto.NewGauge(prometheus.GaugeOpts{
+		Subsystem: "reti",
+		Name:      "last_round",
+		Help:      "Most recent round seen by the daemon",
+	})
+	promAvgBlockTime = promauto.NewGauge(prometheus.GaugeOpts{
+		Subsystem: "reti",
+		Name:      "avg_block_time_seconds",
+		Help:      "Rolling average block time, based on recent block timestamps",
+	})
+)
+
+// BlockHeader is what's sent to block subscribers for each new round
+type BlockHeader struct {
+	Round     uint64
+	Timestamp time.Time
+}
+
+// blockFollower follows the chain, fetching the header of every new round (once) and passing it along to any
+// subscribers.  It also maintains a rolling block time average from the block timestamps.
+type blockFollower struct {
+	logger *slog.Logger
+	chain  algo.StatusReader
+	ready  chan struct{}
+
+	sync.RWMutex
+	lastRound   uint64
+	headers     []BlockHeader // recent headers, for block time average - most recent last
+	subscribers []chan BlockHeader
+}
+
+func newBlockFollower(logger *slog.Logger, chain algo.StatusReader) *blockFollower {
+	return &blockFollower{
+		logger: logger,
+		chain:  chain,
+		ready:  make(chan struct{}),
+	}
+}
+
+// Subscribe returns a channel which receives each new block header, along with a func to call to unsubscribe.
+// Subscribers which fall behind only receive the most recent header, so they should treat the header round as
+// 'at least' the round they were waiting on.
+func (b *blockFollower) Subscribe() (<-chan BlockHeader, func()) {
+	b.Lock()
+	defer b.Unlock()
+	ch := make(chan BlockHeader, 1)
+	b.subscribers = append(b.subscribers, ch)
+	return ch, func() {
+		b.Lock()
+		defer b.Unlock()
+		b.subscribers = slices.DeleteFunc(b.subscribers, func(sub chan BlockHeader) bool {
+			return sub == ch
+		})
+	}
+}
+
+// Ready returns a channel that's closed once the follower has an initial block time average.
+func (b *blockFollower) Ready() <-chan struct{} {
+	return b.ready
+}
+
+func (b *blockFollower) LastRound() uint64 {
+	b.RLock()
+	defer b.RUnlock()
+	return b.lastRound
+}
+
+// AverageBlockTime returns the average block time over the most recent rounds (0 if not yet known)
+func (b *blockFollower) AverageBlockTime() time.Duration {
+	b.RLock()
+	defer b.RUnlock()
+	if len(b.headers) < 2 {
+		return 0
+	}
+	first, last := b.headers[0], b.headers[len(b.headers)-1]
+	return last.Timestamp.Sub(first.Timestamp) / time.Duration(last.Round-first.Round)
+}
+
+// Run follows the chain until the context is cancelled.
+func (b *blockFollower) Run(ctx context.Context) {
+	b.logger.Info("BlockFollower started")
+	defer b.logger.Info("BlockFollower stopped")
+
+	for ctx.Err() == nil {
+		if err := b.seed(ctx); err != nil {
+			misc.Warnf(b.logger, "unable to fetch initial block times, will retry, err:%v", err)
+			sleepCtx(ctx, 5*time.Second)
+			continue
+		}
+		break
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	close(b.ready)
+	misc.Infof(b.logger, "at round:%d, average block time:%v", b.LastRound(), b.AverageBlockTime())
+
+	for ctx.Err() == nil {
+		// waits for the round after lastRound (algod will time out after 1 minute if a round doesn't arrive)
+		status, err := b.chain.StatusAfterBlock(ctx, b.LastRound())
+		if err != nil {
+			if ctx.Err() == nil {
+				misc.Warnf(b.logger, "unable to fetch node status, will retry, err:%v", err)
+				sleepCtx(ctx, 5*time.Second)
+			}
+			continue
+		}
+		fromRound := b.LastRound() + 1
+		if status.LastRound >= fromRound+maxRoundsToBackfill {
+			misc.Warnf(b.logger, "fell behind from round:%d to %d, skipping to latest", fromRound-1, status.LastRound)
+			fromRound = status.LastRound
+		}
+		for round := fromRound; round <= status.LastRound && ctx.Err() == nil; round++ {
+			header, err := b.chain.BlockHeader(ctx, round)
+			if err != nil {
+				misc.Warnf(b.logger, "unable to fetch block header for round:%d, will retry, err:%v", round, err)
+				sleepCtx(ctx, 5*time.Second)
+				break
+			}
+			b.publish(BlockHeader{Round: round, Timestamp: time.Unix(header.TimeStamp, 0)})
+		}
+	}
+}
+
+// seed fetches the most recent blockTimeWindow headers so we have a block time average right away
+func (b *blockFollower) seed(ctx context.Context) error {
+	status, err := b.chain.Status(ctx)
+	if err != nil {
+		return err
+	}
+	var headers []BlockHeader
+	for round := status.LastRound - min(status.LastRound-1, blockTimeWindow-1); round <= status.LastRound; round++ {
+		header, err := b.chain.BlockHeader(ctx, round)
+		if err != nil {
+			return err
+		}
+		headers = append(headers, BlockHeader{Round: round, Timestamp: time.Unix(header.TimeStamp, 0)})
+	}
+	b.Lock()
+	b.lastRound = status.LastRound
+	b.headers = headers
+	b.Unlock()
+	b.updateMetrics()
+	return nil
+}
+
+func (b *blockFollower) publish(header BlockHeader) {
+	b.Lock()
+	b.lastRound = header.Round
+	b.headers = append(b.headers, header)
+	if len(b.headers) > blockTimeWindow {
+		b.headers = b.headers[len(b.headers)-blockTimeWindow:]
+	}
+	subscribers := slices.Clone(b.subscribers)
+	b.Unlock()
+	b.updateMetrics()
+
+	for _, ch := range subscribers {
+		// never block on a slow subscriber - replace whatever header it hasn't picked up yet w/ the newest
+		select {
+		case ch <- header:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- header:
+			default:
+			}
+		}
+	}
+}
+
+func (b *blockFollower) updateMetrics() {
+	promLastRound.Set(float64(b.LastRound()))
+	if avgBlockTime := b.AverageBlockTime(); avgBlockTime != 0 {
+		promAvgBlockTime.Set(avgBlockTime.Seconds())
+	}
+}
+
+// sleepCtx sleeps for the specified duration, returning early if the context is cancelled
+func sleepCtx(ctx context.Context, duration time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(duration):
+	}
+}
@@ -2,18 +2,18 @@ package main
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"maps"
 	"net/http"
-	"os"
+	"slices"
 	"sort"
 	"sync"
 	"time"
 
-	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
 	"github.com/algorand/go-algorand-sdk/v2/crypto"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 	"github.com/mailgun/holster/v4/syncutil"
@@ -21,7 +21,11 @@ import (
 	"github.com/ssgreg/repeat"
 
 	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/events"
+	"github.com/TxnLab/reti/internal/lib/history"
 	"github.com/TxnLab/reti/internal/lib/misc"
+	"github.com/TxnLab/reti/internal/lib/nfdapi/swagger"
+	"github.com/TxnLab/reti/internal/lib/nfdonchain"
 	"github.com/TxnLab/reti/internal/lib/reti"
 )
 
@@ -29,62 +33,129 @@ const (
 	OnlineStatus             = "Online"
 	GeneratedKeyLengthInDays = 7
 	DaysPriorToExpToRenew    = 1
+	// how often (in rounds) KeyWatcher checks our pools and keys - roughly once a minute
+	keyCheckRoundInterval = 20
+	// how often (in rounds) StakerEvictor checks stakers against the gating criteria - roughly every 5 minutes
+	evictionCheckRoundInterval = 100
+	// how often workers heartbeat even when no new rounds arrive
+	workerHeartbeatInterval = 1 * time.Minute
 )
 
-// Daemon provides a 'little' separation in that we initalize it with some data from the App global set up by
-// the process startup, but the Daemon tries to be fairly retrieval with its data retrieval and use.
+// errDryRun is returned for operations skipped because the daemon is in dry-run mode
+var errDryRun = errors.New("skipped in dry-run mode")
+
+// Daemon runs the background workers for a validator's node.  Everything it uses is passed to it (rather than it
+// using the App global) so it can be run against an in-memory ledger (algo.FakeLedger) as well as a live node.
 type Daemon struct {
-	logger     *slog.Logger
-	algoClient *algod.Client
+	logger *slog.Logger
+	// chain is how the daemon accesses the chain - normally algod, but can be an in-memory ledger (algo.FakeLedger)
+	chain algo.Chain
+	// reti is the réti client, and signer signs for our accounts
+	reti   *reti.Reti
+	signer algo.MultipleWalletSigner
+	blocks *blockFollower
+	// history is nil if actions aren't being recorded
+	history *history.Store
+	// nfd clients, for nfd based entry gating - nil if not needed
+	nfdOnChain *nfdonchain.NfdApi
+	nfdApi     *swagger.APIClient
 
 	listenPort int
+	// keyPolicies defines how participation keys are generated/renewed for each pool
+	keyPolicies KeyPolicies
+	// readyMaxRoundLag is how many rounds behind algod can be for us to be considered ready
+	readyMaxRoundLag uint64
+	// election is nil if this daemon isn't coordinating with other replicas (always active)
+	election *leaderElection
+	// events is nil if notifications aren't configured
+	events *events.Bus
+	// notification thresholds
+	notifyKeyExpiry  time.Duration
+	notifyMinBalance uint64
+	// funds tracks manager account spending (and handles treasury top-ups if enabled)
+	funds *managerFunds
+	// linkNFD is set if our pools should be linked to the validator's NFD
+	linkNFD bool
+	// nfdLinked is the nfd and pools last linked (see linkPoolsToNFD) - only used by KeyWatcher
+	nfdLinked string
+	// whether each pool was online as of the last check - only used by KeyWatcher
+	poolsOnline map[uint64]bool
+	// takenOffline are the pools the daemon itself took offline (their key was missing) since the last check - so
+	// them going offline isn't notified about
+	takenOffline map[uint64]bool
 
 	// embed mutex for locking state for members below the mutex
 	sync.RWMutex
-	avgBlockTime time.Duration
+	// active is true while this instance is running the workers (always if not coordinating w/ other replicas)
+	active bool
+	// daemon activity, tracked for the status api
+	nextEpochRound uint64
+	epochResults   map[uint64]epochResult // keyed by pool id
+	evictions      []evictionAction       // most recent last
+	// health tracking
+	lastStateLoad time.Time
+	heartbeats    map[string]time.Time // keyed by worker name
 }
 
-func newDaemon(listenPort int) *Daemon {
+// newDaemon returns a daemon (w/ default settings) for the validator/node of retiClient, which accesses the chain
+// through chain and signs with signer.
+func newDaemon(chain algo.Chain, retiClient *reti.Reti, signer algo.MultipleWalletSigner) *Daemon {
 	return &Daemon{
-		logger:     App.retiClient.Logger,
-		algoClient: App.algoClient,
-		listenPort: listenPort,
+		logger:           retiClient.Logger,
+		chain:            chain,
+		reti:             retiClient,
+		signer:           signer,
+		blocks:           newBlockFollower(retiClient.Logger, chain),
+		keyPolicies:      KeyPolicies{Default: DefaultKeyPolicy},
+		readyMaxRoundLag: DefaultReadyMaxRoundLag,
+		notifyKeyExpiry:  DefaultNotifyKeyExpiry,
+		notifyMinBalance: algo.AlgosToMicroAlgos(DefaultNotifyMinBalance),
+		funds:            newManagerFunds(nil),
+		poolsOnline:      map[uint64]bool{},
+		takenOffline:     map[uint64]bool{},
+
+		epochResults: map[uint64]epochResult{},
+		// state is loaded as part of daemon startup
+		lastStateLoad: time.Now(),
+		heartbeats:    map[string]time.Time{},
 	}
 }
 
 func (d *Daemon) start(ctx context.Context, wg *sync.WaitGroup, cancel context.CancelFunc) {
 	misc.Infof(d.logger, "Réti daemon, version:%s started", getVersionInfo())
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		// note that KeyWatcher is allowed to cancel the context and cause the daemon to exit
-		// this is so an exit (and presumed restart) can be triggered when the manager address is changed
-		// out from underneath the daemon
-		d.KeyWatcher(ctx, cancel)
-	}()
 
+	// the block follower runs regardless of whether we're active, as block data is also used for metrics, status
+	// and health checks
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		d.EpochUpdater(ctx)
+		d.blocks.Run(ctx)
 	}()
 
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		info := App.retiClient.Info()
-		if info.Config.EntryGatingType == reti.GatingTypeNone {
-			return
-		}
-		d.StakerEvictor(ctx)
-	}()
+	startWorkers := func(ctx context.Context, wg *sync.WaitGroup) {
+		d.startWorkers(ctx, wg, cancel)
+	}
+	if d.election == nil {
+		d.setActive(true)
+		startWorkers(ctx, wg)
+	} else {
+		// Only run the workers (which sign/send transactions) while we hold the lease for our node - standbys
+		// just serve metrics.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.runAsCandidate(ctx, startWorkers)
+		}()
+	}
 
 	wg.Add(1)
 	go func() {
 		defer d.logger.Info("Exiting HTTP server")
 		defer wg.Done()
-		http.Handle("/ready", isReady())
+		http.Handle("/live", d.isLive())
+		http.Handle("/ready", d.isReady())
 		http.Handle("/metrics", promhttp.Handler())
+		d.registerStatusAPI(http.DefaultServeMux)
 
 		host := fmt.Sprintf(":%d", d.listenPort)
 		srv := &http.Server{Addr: host}
@@ -104,10 +175,33 @@ func (d *Daemon) start(ctx context.Context, wg *sync.WaitGroup, cancel context.C
 	}()
 }
 
-func isReady() http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
+// startWorkers starts the goroutines which mutate on-chain state (going online, epoch updates, evictions).
+// cancel is the top-level daemon cancel func, which KeyWatcher is allowed to call.
+func (d *Daemon) startWorkers(ctx context.Context, wg *sync.WaitGroup, cancel context.CancelFunc) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// note that KeyWatcher is allowed to cancel the context and cause the daemon to exit
+		// this is so an exit (and presumed restart) can be triggered when the manager address is changed
+		// out from underneath the daemon
+		d.KeyWatcher(ctx, cancel)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		d.EpochUpdater(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		info := d.reti.Info()
+		if info.Config.EntryGatingType == reti.GatingTypeNone {
+			return
+		}
+		d.StakerEvictor(ctx)
+	}()
 }
 
 // KeyWatcher keeps track of both active pools for this node (updated via configuration file) as well
@@ -115,49 +209,61 @@ func isReady() http.Handler {
 func (d *Daemon) KeyWatcher(ctx context.Context, cancel context.CancelFunc) {
 	defer d.logger.Info("Exiting KeyWatcher")
 	d.logger.Info("Starting KeyWatcher")
+	d.heartbeat("KeyWatcher")
 
-	// make sure avg block time is set first
-	err := d.setAverageBlockTime(ctx)
-	if err != nil {
-		misc.Errorf(d.logger, "unable to fetch blocks to determine block times: %v", err)
-		os.Exit(1)
+	// make sure avg block time is known first, as it's needed to size keys
+	if !d.waitForBlocks(ctx) {
+		return
 	}
 	d.checkPools(ctx)
 
-	checkTime := time.NewTicker(1 * time.Minute)
-	blockTimeUpdate := time.NewTicker(30 * time.Minute)
-	defer checkTime.Stop()
-	defer blockTimeUpdate.Stop()
+	rounds, unsubscribe := d.blocks.Subscribe()
+	defer unsubscribe()
+	heartbeat := time.NewTicker(workerHeartbeatInterval)
+	defer heartbeat.Stop()
+	nextCheck := d.blocks.LastRound() + keyCheckRoundInterval
 
-	// Check our key validity once a minute
+	// Check our key validity every keyCheckRoundInterval rounds
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-checkTime.C:
+		case <-heartbeat.C:
+			d.heartbeat("KeyWatcher")
+		case header := <-rounds:
+			d.heartbeat("KeyWatcher")
+			if header.Round < nextCheck {
+				break
+			}
+			nextCheck = header.Round + keyCheckRoundInterval
 			// Make sure our 'config' is fresh in case the user updated it
 			// they could have added new pools, moved them between nodes, etc.
-			curManager := App.retiClient.Info().Config.Manager
+			curManager := d.reti.Info().Config.Manager
 			err := d.refetchConfig()
 			if err != nil {
 				misc.Warnf(d.logger, "error in fetching configuration, will retry.  err:%v", err)
 				break
 			}
-			if curManager != App.retiClient.Info().Config.Manager {
+			if curManager != d.reti.Info().Config.Manager {
 				d.logger.Warn("Manager account was changed, restarting daemon to ensure proper keys available")
+				d.notify(events.TypeManagerChanged, events.SeverityWarning, 0, "",
+					"manager account changed from %s to %s, restarting daemon", curManager, d.reti.Info().Config.Manager)
 				cancel()
 				return
 			}
 
 			d.updatePoolVersions(ctx)
+			if d.linkNFD {
+				d.linkPoolsToNFD(ctx)
+			}
 			d.checkPools(ctx)
-		case <-blockTimeUpdate.C:
-			_ = d.setAverageBlockTime(ctx)
+			d.checkManagerFunds(ctx)
 		}
 	}
 }
 
 type onlineInfo struct {
+	poolId                    uint64
 	poolAppId                 uint64
 	isOnline                  bool
 	selectionParticipationKey []byte
@@ -167,13 +273,14 @@ type onlineInfo struct {
 func (d *Daemon) checkPools(ctx context.Context) {
 	// get online status and partkey info for all our accounts (ignoring any that don't have balances yet)
 	var poolAccounts = map[string]onlineInfo{}
-	for poolId, poolAppId := range App.retiClient.Info().LocalPools {
-		acctInfo, err := algo.GetBareAccount(ctx, d.algoClient, crypto.GetApplicationAddress(poolAppId).String())
+	for poolId, poolAppId := range d.reti.Info().LocalPools {
+		acctInfo, err := algo.GetBareAccount(ctx, d.chain, crypto.GetApplicationAddress(poolAppId).String())
 		if err != nil {
 			d.logger.Warn("account fetch error", "account", crypto.GetApplicationAddress(poolAppId).String(), "error", err)
 			return
 		}
 		info := onlineInfo{
+			poolId:                    poolId,
 			poolAppId:                 poolAppId,
 			isOnline:                  acctInfo.Status == OnlineStatus,
 			selectionParticipationKey: acctInfo.Participation.SelectionParticipationKey,
@@ -183,8 +290,8 @@ func (d *Daemon) checkPools(ctx context.Context) {
 			poolAccounts[crypto.GetApplicationAddress(poolAppId).String()] = info
 		}
 		// ensure pools were initialized properly (since it's a two-step process - the second step may have been skipped?)
-		err = App.retiClient.CheckAndInitStakingPoolStorage(&reti.ValidatorPoolKey{
-			ID:        App.retiClient.Info().Config.ID,
+		err = d.reti.CheckAndInitStakingPoolStorage(&reti.ValidatorPoolKey{
+			ID:        d.reti.Info().Config.ID,
 			PoolId:    poolId,
 			PoolAppId: poolAppId,
 		})
@@ -194,11 +301,12 @@ func (d *Daemon) checkPools(ctx context.Context) {
 		}
 	}
 	// now get all the current participation keys for our node
-	partKeys, err := algo.GetParticipationKeys(ctx, d.algoClient)
+	partKeys, err := algo.GetParticipationKeys(ctx, d.chain)
 	if err != nil {
 		d.logger.Warn("participation key fetch error", "error", err)
 		return
 	}
+	d.checkPoolsStillOnline(poolAccounts)
 	// first, remove all expired keys ! (regardless if currently for our node or not)
 	anyRemoved, err := d.removeExpiredKeys(ctx, partKeys)
 	if err != nil {
@@ -207,7 +315,7 @@ func (d *Daemon) checkPools(ctx context.Context) {
 	}
 	if anyRemoved {
 		// get part key list again because we removed some...
-		partKeys, err = algo.GetParticipationKeys(ctx, d.algoClient)
+		partKeys, err = algo.GetParticipationKeys(ctx, d.chain)
 		if err != nil {
 			d.logger.Warn("participation key fetch error", "error", err)
 			return
@@ -225,27 +333,28 @@ func (d *Daemon) checkPools(ctx context.Context) {
 		misc.Errorf(d.logger, "error ensuring participation: %v", err)
 		return
 	}
+	d.checkKeyExpiry(poolAccounts, partKeys)
 }
 
 func (d *Daemon) updatePoolVersions(ctx context.Context) {
-	managerAddr, _ := types.DecodeAddress(App.retiClient.Info().Config.Manager)
+	managerAddr, _ := types.DecodeAddress(d.reti.Info().Config.Manager)
 
-	versString, err := algo.GetVersionString(ctx, d.algoClient)
+	versString, err := algo.GetVersionString(ctx, d.chain)
 	if err != nil {
 		misc.Errorf(d.logger, "unable to fetch version string from algod instance, err:%v", err)
 		return
 	}
 	versString = fmt.Sprintf("%s : %s", versString, getVersionInfo())
 
-	for _, poolAppId := range App.retiClient.Info().LocalPools {
-		algodVer, err := App.retiClient.GetAlgodVer(poolAppId)
-		if err != nil && !errors.Is(err, algo.ErrStateKeyNotFound) {
+	for _, poolAppId := range d.reti.Info().LocalPools {
+		snapshot, err := d.reti.GetPoolSnapshot(ctx, poolAppId)
+		if err != nil {
 			misc.Errorf(d.logger, "unable to fetch algod version from staking pool app id:%d, err:%v", poolAppId, err)
 			return
 		}
-		if algodVer != versString {
+		if snapshot.AlgodVer != versString {
 			// Update version in staking pool
-			err = App.retiClient.UpdateAlgodVer(poolAppId, versString, managerAddr)
+			err = d.reti.UpdateAlgodVer(poolAppId, versString, managerAddr)
 			if err != nil {
 				misc.Errorf(d.logger, "unable to update algod version in staking pool app id:%d, err:%v", poolAppId, err)
 				return
@@ -254,27 +363,45 @@ func (d *Daemon) updatePoolVersions(ctx context.Context) {
 	}
 }
 
-func (d *Daemon) AverageBlockTime() time.Duration {
-	d.RLock()
-	defer d.RUnlock()
-	return d.avgBlockTime
+// linkPoolsToNFD links any of our pools which aren't yet verified addresses of the validator's NFD.  It's only
+// checked again when our pools (or the validator's nfd) change, and linking is disabled if it can't be done at all
+// (ie: the nfd owner's keys aren't present).
+func (d *Daemon) linkPoolsToNFD(ctx context.Context) {
+	var poolAppIDs []uint64
+	for _, poolAppId := range d.reti.Info().LocalPools {
+		poolAppIDs = append(poolAppIDs, poolAppId)
+	}
+	slices.Sort(poolAppIDs)
+	linkState := fmt.Sprintf("nfd:%d, pools:%v", d.reti.Info().Config.NFDForInfo, poolAppIDs)
+	if linkState == d.nfdLinked {
+		return
+	}
+	_, err := linkPoolsToNFD(ctx, d.reti, d.nfdOnChain, d.signer, poolAppIDs)
+	if errors.Is(err, errCantLinkNFD) {
+		misc.Warnf(d.logger, "not linking pools to the validator's nfd, %v", err)
+		d.linkNFD = false
+		return
+	}
+	if err != nil {
+		misc.Errorf(d.logger, "error linking pools to nfd, err:%v", err)
+		return
+	}
+	d.nfdLinked = linkState
 }
 
-func (d *Daemon) setAverageBlockTime(ctx context.Context) error {
-	// Get the latest block via the algoClient.Status() call, then
-	// fetch the most recent X blocks - fetching the timestamps from each and
-	// determining the approximate current average block time.
-	const numRounds = 10
+func (d *Daemon) AverageBlockTime() time.Duration {
+	return d.blocks.AverageBlockTime()
+}
 
-	blockTime, err := algo.CalcBlockTimes(ctx, d.algoClient, numRounds)
-	if err != nil {
-		return err
+// waitForBlocks waits until the block follower is tracking rounds (and has a block time average).
+// Returns false if the context was cancelled first.
+func (d *Daemon) waitForBlocks(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-d.blocks.Ready():
+		return true
 	}
-	d.Lock()
-	d.avgBlockTime = blockTime
-	d.Unlock()
-	misc.Infof(d.logger, "average block time set to:%v", d.AverageBlockTime())
-	return nil
 }
 
 func (d *Daemon) refetchConfig() error {
@@ -283,10 +410,11 @@ func (d *Daemon) refetchConfig() error {
 		repeat.Fn(func() error {
 			// Load state refetches our state from the chain and also updates our
 			// in-memory copy of it that everything uses.
-			err = App.retiClient.LoadState(context.Background())
+			err = d.reti.LoadState(context.Background())
 			if err != nil {
 				return repeat.HintTemporary(err)
 			}
+			d.stateLoaded()
 			return nil
 		}),
 		repeat.StopOnSuccess(),
@@ -306,23 +434,62 @@ func (d *Daemon) refetchConfig() error {
 	return err
 }
 
-func (d *Daemon) createPartKey(ctx context.Context, account string, firstValid uint64) (*algo.ParticipationKey, error) {
-	// generate keys good for one month based on current avg block time - nothing is returned until key is actually created
-	status, err := d.algoClient.Status().Do(context.Background())
+// createPartKey generates a key valid for the policy's key length, based on current avg block time - nothing is
+// returned until key is actually created.  Callers are responsible for checking the policy's max pending keys.
+func (d *Daemon) createPartKey(ctx context.Context, account string, firstValid uint64, policy KeyPolicy) (*algo.ParticipationKey, error) {
+	status, err := d.chain.Status(context.Background())
 	if err != nil {
 		return nil, fmt.Errorf("unable to fetch node status: %w", err)
 	}
 	if firstValid == 0 {
 		firstValid = status.LastRound
 	}
-	keyDurationInSeconds := GeneratedKeyLengthInDays * 60 * 60 * 24
-	lastValid := firstValid + uint64(float64(keyDurationInSeconds)/d.AverageBlockTime().Seconds())
-	return algo.GenerateParticipationKey(ctx, d.algoClient, d.logger, account, firstValid, lastValid)
+	lastValid := firstValid + uint64(policy.Length/d.AverageBlockTime())
+	if d.reti.DryRun() {
+		misc.Infof(d.logger, "[DRY-RUN] would have generated part key for account:%s, first/last valid:%d-%d, dilution:%d", account, firstValid, lastValid, policy.Dilution)
+		return nil, errDryRun
+	}
+	// key generation can take many minutes - don't look wedged while waiting on it
+	defer d.keepHeartbeat("KeyWatcher")()
+	key, err := algo.GenerateParticipationKey(ctx, d.chain, d.logger, account, firstValid, lastValid, policy.Dilution)
+	action := d.keyAction(history.TypeKeyCreate, account, fmt.Sprintf("first/last valid:%d-%d, dilution:%d", firstValid, lastValid, policy.Dilution))
+	if err != nil {
+		action.Error = err.Error()
+	} else {
+		action.Detail = fmt.Sprintf("id:%s, %s", key.Id, action.Detail)
+	}
+	d.recordHistory(action)
+	return key, err
+}
+
+// recordHistory records the action in the history database (if enabled)
+func (d *Daemon) recordHistory(action history.Action) {
+	if d.history == nil {
+		return
+	}
+	if err := d.history.Record(action); err != nil {
+		misc.Warnf(d.logger, "unable to record %s action in history, err:%v", action.Type, err)
+	}
+}
+
+// keyAction returns a participation key history action for the pool with the specified account
+func (d *Daemon) keyAction(actionType string, account string, detail string) history.Action {
+	action := history.Action{Type: actionType, Detail: detail}
+	for poolId, poolAppId := range d.reti.Info().LocalPools {
+		if crypto.GetApplicationAddress(poolAppId).String() == account {
+			action.PoolId, action.PoolAppId = poolId, poolAppId
+			break
+		}
+	}
+	if action.PoolId == 0 {
+		action.Detail = fmt.Sprintf("account:%s, %s", account, detail)
+	}
+	return action
 }
 
 // 1) Part key found but expired - delete it
 func (d *Daemon) removeExpiredKeys(ctx context.Context, partKeys algo.PartKeysByAddress) (bool, error) {
-	status, err := d.algoClient.Status().Do(ctx)
+	status, err := d.chain.Status(ctx)
 	if err != nil {
 		return false, fmt.Errorf("unable to fetch node status: %w", err)
 	}
@@ -330,8 +497,17 @@ func (d *Daemon) removeExpiredKeys(ctx context.Context, partKeys algo.PartKeysBy
 	for _, keys := range partKeys {
 		for _, key := range keys {
 			if key.Key.VoteLastValid < status.LastRound {
+				if d.reti.DryRun() {
+					misc.Infof(d.logger, "[DRY-RUN] would have removed expired key:%s for account:%s", key.Id, key.Address)
+					continue
+				}
 				misc.Infof(d.logger, "key:%s for account:%s is expired, removing", key.Id, key.Address)
-				err = algo.DeleteParticipationKey(ctx, d.algoClient, d.logger, key.Id)
+				err = algo.DeleteParticipationKey(ctx, d.chain, d.logger, key.Id)
+				action := d.keyAction(history.TypeKeyDelete, key.Address, fmt.Sprintf("id:%s, expired at round:%d", key.Id, key.Key.VoteLastValid))
+				if err != nil {
+					action.Error = err.Error()
+				}
+				d.recordHistory(action)
 				if err != nil {
 					return false, fmt.Errorf("error deleting participation key for id:%s, err:%w", key.Id, err)
 				}
@@ -345,13 +521,14 @@ func (d *Daemon) removeExpiredKeys(ctx context.Context, partKeys algo.PartKeysBy
 func (d *Daemon) ensureParticipation(ctx context.Context, poolAccounts map[string]onlineInfo, partKeys algo.PartKeysByAddress) error {
 	/** conditions to cover for participation keys / accounts
 	1) account has NO local participation key (online or offline) (ie: they could've moved to new node)
-		Create brand new 'GeneratedKeyLengthInDays' length key - will go online as part of subsequent checks once part.
+		Create brand new key (of the pool's key policy length) - will go online as part of subsequent checks once part.
 		key reaches first valid.
 	2) account is NOT online but has one or more part keys
 		Go online against newest part key - done
-	3) account has ONE local part key AND IS ONLINE
-		Assumed 'steady state' - check lifetime of CURRENT key and if expiring within 1 day
-		If expiring soon, create new key w/ firstValid set to existing key's lastValid - 1 day of rounds.
+	3) account IS ONLINE w/ its key present locally
+		Assumed 'steady state' - check lifetime of the NEWEST key and if expiring within the policy's renewal lead time
+		create new key w/ firstValid set to that key's lastValid - lead time worth of rounds, as long as the account
+		doesn't already have the policy's max pending keys.
 	4) account is online and has multiple local part keys
 		If Online (assumed steady state when a future pending part key has been created)
 			Sort keys descending by first valid
@@ -366,7 +543,7 @@ func (d *Daemon) ensureParticipation(ctx context.Context, poolAccounts map[strin
 	if err := d.ensureParticipationNotOnline(ctx, poolAccounts, partKeys); err != nil {
 		return err
 	}
-	// account has 1 part key, IS ONLINE and might expire soon (needing to generate new key)
+	// account IS ONLINE and its newest key might expire soon (needing to generate new key)
 	if err := d.ensureParticipationCheckNeedsRenewed(ctx, poolAccounts, partKeys); err != nil {
 		return err
 	}
@@ -379,10 +556,10 @@ func (d *Daemon) ensureParticipation(ctx context.Context, poolAccounts map[strin
 
 // Handle: account has NO local participation key (online or offline)
 func (d *Daemon) ensureParticipationNoKeysYet(ctx context.Context, poolAccounts map[string]onlineInfo, partKeys algo.PartKeysByAddress) error {
-	for account := range poolAccounts {
+	for account, info := range poolAccounts {
 		// for accounts w/ no keys at all - we just create keys - we'll go online as part of later checks
 		if _, found := partKeys[account]; !found {
-			_, err := d.createPartKey(ctx, account, 0)
+			_, err := d.createPartKey(ctx, account, 0, d.keyPolicies.ForPool(info.poolId))
 			if err != nil {
 				misc.Errorf(d.logger, "error generating part key for missing account:%s, err:%v", account, err)
 				return nil
@@ -397,7 +574,7 @@ func (d *Daemon) ensureParticipationNoKeysYet(ctx context.Context, poolAccounts
 func (d *Daemon) ensureParticipationNotOnline(_ context.Context, poolAccounts map[string]onlineInfo, partKeys algo.PartKeysByAddress) error {
 	var (
 		err            error
-		managerAddr, _ = types.DecodeAddress(App.retiClient.Info().Config.Manager)
+		managerAddr, _ = types.DecodeAddress(d.reti.Info().Config.Manager)
 	)
 
 	for account, info := range poolAccounts {
@@ -415,7 +592,7 @@ func (d *Daemon) ensureParticipationNotOnline(_ context.Context, poolAccounts ma
 
 			// going offline to online - we pass that info on via the third arg so the extra fees are included to make the
 			// account eligible for payments.
-			err = App.retiClient.GoOnline(info.poolAppId, managerAddr, keyToUse.Key.VoteParticipationKey, keyToUse.Key.SelectionParticipationKey, keyToUse.Key.StateProofKey, keyToUse.Key.VoteFirstValid, keyToUse.Key.VoteLastValid, keyToUse.Key.VoteKeyDilution)
+			err = d.reti.GoOnline(info.poolAppId, managerAddr, keyToUse.Key.VoteParticipationKey, keyToUse.Key.SelectionParticipationKey, keyToUse.Key.StateProofKey, keyToUse.Key.VoteFirstValid, keyToUse.Key.VoteLastValid, keyToUse.Key.VoteKeyDilution)
 			if err != nil {
 				return fmt.Errorf("unable to go online for key:%s, account:%s [pool app id:%d], err:%w", keyToUse.Id, account, info.poolAppId, err)
 			}
@@ -426,14 +603,15 @@ func (d *Daemon) ensureParticipationNotOnline(_ context.Context, poolAccounts ma
 }
 
 /*
-account has 1 part key AND IS ONLINE
+account IS ONLINE w/ its key present locally
 
-	We only allow 1 part key so we don't keep trying to create new key when we're close to expiration.
-	Assumed 'steady state' - check lifetime of key and if expiring within 1 day
-	If expiring soon, create new key w/ firstValid set to existing key's lastValid - 1 day of rounds.  done
+	Assumed 'steady state' - check lifetime of the newest key (the active key, or a replacement already created for
+	it) and if expiring within the pool's renewal lead time, create new key w/ firstValid set to that key's
+	lastValid - lead time worth of rounds.  Keys are only created while the account has fewer than the policy's max
+	pending (not yet valid) keys, so we don't keep creating keys when we're close to expiration.
 */
 func (d *Daemon) ensureParticipationCheckNeedsRenewed(ctx context.Context, poolAccounts map[string]onlineInfo, partKeys algo.PartKeysByAddress) error {
-	status, err := d.algoClient.Status().Do(ctx)
+	status, err := d.chain.Status(ctx)
 	if err != nil {
 		d.logger.Warn("failure in getting current node status w/in getExpiringKeys", "error", err)
 		return nil
@@ -445,29 +623,42 @@ func (d *Daemon) ensureParticipationCheckNeedsRenewed(ctx context.Context, poolA
 		if !info.isOnline {
 			continue
 		}
-		if len(partKeys[account]) != 1 {
+		keysForAccount := partKeys[account]
+		if !slices.ContainsFunc(keysForAccount, func(key algo.ParticipationKey) bool {
+			return bytes.Equal(key.Key.SelectionParticipationKey, info.selectionParticipationKey)
+		}) {
+			// the key the account is online against isn't here - handled by ensureParticipationCheckNeedsSwitched
 			continue
 		}
-		activeKey := partKeys[account][0]
-		if bytes.Compare(activeKey.Key.SelectionParticipationKey, info.selectionParticipationKey) == 0 {
-			if activeKey.EffectiveFirstValid > curRound {
-				// activeKey isn't even in range yet ignore for now
-				continue
-			}
-			expValidDistance := time.Duration(activeKey.Key.VoteLastValid-curRound) * avgBlockTime
-			if expValidDistance.Hours() <= 24*DaysPriorToExpToRenew {
-				oneDayOfBlocks := (24 * time.Hour) / avgBlockTime
-				misc.Infof(d.logger, "activeKey: %s for %s expiring in %v, creating new key with ~1 day lead-time", activeKey.Id, activeKey.Address, expValidDistance)
-				_, err = d.createPartKey(ctx, account, activeKey.Key.VoteLastValid-uint64(oneDayOfBlocks))
-				if err != nil {
-					d.logger.Warn("failure in creating new key w/in ensureParticipationCheckNeedsRenewed", "error", err)
-					continue
-				}
-			}
+		newestKey := slices.MaxFunc(keysForAccount, func(a, b algo.ParticipationKey) int {
+			return cmp.Compare(a.Key.VoteLastValid, b.Key.VoteLastValid)
+		})
+		policy := d.keyPolicies.ForPool(info.poolId)
+		var expValidDistance time.Duration
+		if newestKey.Key.VoteLastValid > curRound {
+			expValidDistance = time.Duration(newestKey.Key.VoteLastValid-curRound) * avgBlockTime
+		}
+		if expValidDistance > policy.RenewLead {
+			continue
+		}
+		if numPending := pendingKeys(keysForAccount, curRound); numPending >= policy.MaxPending {
+			misc.Debugf(d.logger, "key:%s for %s expiring in %v, but account already has %d pending keys (max:%d)", newestKey.Id, account, expValidDistance, numPending, policy.MaxPending)
+			continue
+		}
+		leadTimeBlocks := uint64(policy.RenewLead / avgBlockTime)
+		misc.Infof(d.logger, "key:%s for %s expiring in %v, creating new key with ~%v lead-time", newestKey.Id, account, expValidDistance, policy.RenewLead)
+		// new key can't start in the past though
+		firstValid := curRound
+		if newestKey.Key.VoteLastValid > curRound+leadTimeBlocks {
+			firstValid = newestKey.Key.VoteLastValid - leadTimeBlocks
+		}
+		_, err = d.createPartKey(ctx, account, firstValid, policy)
+		if err != nil {
+			d.logger.Warn("failure in creating new key w/in ensureParticipationCheckNeedsRenewed", "error", err)
+			continue
 		}
 	}
 	return nil
-
 }
 
 /*
@@ -479,9 +670,9 @@ Handle: account is online and has multiple local part keys
 	Go online against this new key - done.  prior key will be removed a week later when it's out of valid range
 */
 func (d *Daemon) ensureParticipationCheckNeedsSwitched(ctx context.Context, poolAccounts map[string]onlineInfo, partKeys algo.PartKeysByAddress) error {
-	managerAddr, _ := types.DecodeAddress(App.retiClient.Info().Config.Manager)
+	managerAddr, _ := types.DecodeAddress(d.reti.Info().Config.Manager)
 
-	status, err := d.algoClient.Status().Do(ctx)
+	status, err := d.chain.Status(ctx)
 	if err != nil {
 		d.logger.Warn("failure in getting current node status w/in getExpiringKeys", "error", err)
 		return nil
@@ -510,10 +701,11 @@ func (d *Daemon) ensureParticipationCheckNeedsSwitched(ctx context.Context, pool
 			// the key it's online against isn't present - so have the account go offline and then we can start over with
 			// the keys we have or don't have on next pass.
 			misc.Errorf(d.logger, "account:%s is online but its part. key isn't present locally! - offlining account", account)
-			err = App.retiClient.GoOffline(info.poolAppId, managerAddr)
+			err = d.reti.GoOffline(info.poolAppId, managerAddr)
 			if err != nil {
 				return fmt.Errorf("unable to go offline for account:%s [pool app id:%d], err: %w", account, info.poolAppId, err)
 			}
+			d.takenOffline[info.poolId] = true
 			return nil
 		}
 		// sort the part keys by whichever has highest firstValid
@@ -531,7 +723,7 @@ func (d *Daemon) ensureParticipationCheckNeedsSwitched(ctx context.Context, pool
 		}
 		// Ok, we're already online but its time to switch to the new key - it's in valid range
 		misc.Infof(d.logger, "account:%s going online against newest of %d part keys, id:%s", account, len(keysForAccount), keyToCheck.Id)
-		err = App.retiClient.GoOnline(info.poolAppId, managerAddr, keyToCheck.Key.VoteParticipationKey, keyToCheck.Key.SelectionParticipationKey, keyToCheck.Key.StateProofKey, keyToCheck.Key.VoteFirstValid, keyToCheck.Key.VoteLastValid, keyToCheck.Key.VoteKeyDilution)
+		err = d.reti.GoOnline(info.poolAppId, managerAddr, keyToCheck.Key.VoteParticipationKey, keyToCheck.Key.SelectionParticipationKey, keyToCheck.Key.StateProofKey, keyToCheck.Key.VoteFirstValid, keyToCheck.Key.VoteLastValid, keyToCheck.Key.VoteKeyDilution)
 		if err != nil {
 			return fmt.Errorf("unable to go online for account:%s [pool app id:%d], err: %w", account, info.poolAppId, err)
 		}
@@ -543,57 +735,72 @@ func (d *Daemon) ensureParticipationCheckNeedsSwitched(ctx context.Context, pool
 func (d *Daemon) EpochUpdater(ctx context.Context) {
 	d.logger.Info("EpochUpdater started")
 	defer d.logger.Info("EpochUpdater stopped")
+	d.heartbeat("EpochUpdater")
 
-	status, err := d.algoClient.Status().Do(context.Background())
-	if err != nil {
-		misc.Errorf(d.logger, "failed to get algod status at start: %v", err)
-		os.Exit(1)
+	if !d.waitForBlocks(ctx) {
+		return
 	}
-	curRound := status.LastRound
-	epochRoundLength := uint64(App.retiClient.Info().Config.EpochRoundLength)
+	rounds, unsubscribe := d.blocks.Subscribe()
+	defer unsubscribe()
+	heartbeat := time.NewTicker(workerHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	curRound := d.blocks.LastRound()
+	epochRoundLength := uint64(d.reti.Info().Config.EpochRoundLength)
 	// First we need to see if we MISSED an epoch in ANY of our pools - across all of our pools determine which
-	// we need to stop at first (could be in past - which will be instant fallthrough in waitUntilBlock)
+	// we need to stop at first (could be in past - which will be instant fallthrough on the next round)
 	stopAtRound := d.getFirstEligibleEpochRound(curRound, epochRoundLength)
+	d.setNextEpochRound(stopAtRound)
 
 	misc.Infof(d.logger, "at round:%d, with epoch length:%d, first epoch check at %d", curRound, epochRoundLength, stopAtRound)
 
-	signerAddr, _ := types.DecodeAddress(App.retiClient.Info().Config.Manager)
+	signerAddr, _ := types.DecodeAddress(d.reti.Info().Config.Manager)
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case blockWaitResult := <-d.waitUntilBlock(ctx, stopAtRound):
-			if blockWaitResult.err != nil {
-				misc.Errorf(d.logger, "error waiting for block:%d, err:%v", stopAtRound, blockWaitResult.err)
-				continue
+		case <-heartbeat.C:
+			d.heartbeat("EpochUpdater")
+		case header := <-rounds:
+			d.heartbeat("EpochUpdater")
+			if header.Round < stopAtRound {
+				break
 			}
-			stopAtRound = nextEpoch(blockWaitResult.atRound, epochRoundLength)
+			stopAtRound = nextEpoch(header.Round, epochRoundLength)
+			d.setNextEpochRound(stopAtRound)
 
 			var (
 				wg   syncutil.WaitGroup
-				info = App.retiClient.Info()
+				info = d.reti.Info()
 			)
 			for i, pool := range info.Pools {
 				if _, found := info.LocalPools[uint64(i+1)]; !found {
 					continue
 				}
 				wg.Run(func(val any) error {
-					if !accountHasAtLeast(ctx, App.algoClient, info.Config.Manager, 100_000 /* .1 spendable */) {
-						return errors.New("manager account should have at least .1 ALGO spendable.  Aborting epochUpdate call")
+					var skipped bool
+					if !accountHasAtLeast(ctx, d.chain, info.Config.Manager, 100_000 /* .1 spendable */) {
+						err := errors.New("manager account should have at least .1 ALGO spendable.  Aborting epochUpdate call")
+						d.recordEpochResult(uint64(i+1), epochResult{Round: header.Round, Time: time.Now(), Error: err.Error()})
+						d.notify(events.TypeEpochUpdateFailed, events.SeverityCritical, uint64(i+1), "",
+							"epoch update at round:%d failed, err:%v", header.Round, err)
+						return err
 					}
 
 					// Retry up to 5 times - waiting 5 seconds between each try
 					err := repeat.Repeat(
 						repeat.Fn(func() error {
-							lastPayout, err := App.retiClient.GetLastPayout(pool.PoolAppId)
+							snapshot, err := d.reti.GetPoolSnapshot(ctx, pool.PoolAppId)
 							if err != nil {
 								return repeat.HintTemporary(fmt.Errorf("error fetching payout from pool:%d, app id:%d, err:%w", i+1, pool.PoolAppId, err))
 							}
-							if lastPayout != 0 && lastPayout-(lastPayout%epochRoundLength) == blockWaitResult.atRound-(blockWaitResult.atRound%epochRoundLength) {
-								misc.Infof(d.logger, "already ran epoch update for this epoch on pool:%d, round:%d", i+1, blockWaitResult.atRound)
+							lastPayout := snapshot.LastPayout
+							if lastPayout != 0 && lastPayout-(lastPayout%epochRoundLength) == header.Round-(header.Round%epochRoundLength) {
+								misc.Infof(d.logger, "already ran epoch update for this epoch on pool:%d, round:%d", i+1, header.Round)
+								skipped = true
 								return nil
 							}
-							err = App.retiClient.EpochBalanceUpdate(i+1, pool.PoolAppId, signerAddr)
+							err = d.reti.EpochBalanceUpdate(i+1, pool.PoolAppId, signerAddr)
 							if err != nil {
 								// Assume epoch update failed because it's just 'slightly' too early?
 								return repeat.HintTemporary(fmt.Errorf("epoch balance update failed for pool app id:%d, err:%w", i+1, err))
@@ -613,6 +820,13 @@ func (d *Daemon) EpochUpdater(ctx context.Context) {
 							}).Set(),
 						),
 					)
+					result := epochResult{Round: header.Round, Time: time.Now(), Skipped: skipped}
+					if err != nil {
+						result.Error = err.Error()
+						d.notify(events.TypeEpochUpdateFailed, events.SeverityCritical, uint64(i+1), "",
+							"epoch update at round:%d failed after retries, err:%v", header.Round, err)
+					}
+					d.recordEpochResult(uint64(i+1), result)
 					return err
 				}, nil)
 			}
@@ -624,51 +838,9 @@ func (d *Daemon) EpochUpdater(ctx context.Context) {
 	}
 }
 
-type BlockOrError struct {
-	atRound uint64
-	err     error
-}
-
-func (d *Daemon) waitUntilBlock(ctx context.Context, round uint64) chan BlockOrError {
-	chReturn := make(chan BlockOrError, 2)
-	go func() {
-		defer close(chReturn)
-
-		status, err := d.algoClient.Status().Do(context.Background())
-		if err != nil {
-			chReturn <- BlockOrError{err: fmt.Errorf("unable to fetch node status: %w", err)}
-			return
-		}
-		misc.Debugf(d.logger, "at round:%d, waiting until round %d", status.LastRound, round)
-		for {
-			select {
-			case <-ctx.Done():
-				chReturn <- BlockOrError{err: ctx.Err()}
-				return
-			default:
-				curRound := status.LastRound
-				if curRound >= round {
-					chReturn <- BlockOrError{atRound: curRound}
-					return
-				}
-				// Since the call is wait AFTER block X we wait until 'after' round - 1
-				// We'll wait up to 10 blocks at a time (since StatusAfterBlock has fixed 1m timeout)
-				curRound = min(round-1, curRound+10)
-				status, err = d.algoClient.StatusAfterBlock(curRound).Do(context.Background())
-				if err != nil {
-					chReturn <- BlockOrError{err: fmt.Errorf("unable to fetch node status: %w", err)}
-					return
-				}
-				// on next cycle we'll exit if round matches
-			}
-		}
-	}()
-	return chReturn
-}
-
 func (d *Daemon) getFirstEligibleEpochRound(curRound uint64, epochRoundLength uint64) uint64 {
 	var (
-		info               = App.retiClient.Info()
+		info               = d.reti.Info()
 		curRoundEpochStart = curRound - (curRound % epochRoundLength)
 		earliestEpochToUse = curRoundEpochStart
 	)
@@ -676,9 +848,9 @@ func (d *Daemon) getFirstEligibleEpochRound(curRound uint64, epochRoundLength ui
 		if _, found := info.LocalPools[uint64(i+1)]; !found {
 			continue
 		}
-		lastPayout, err := App.retiClient.GetLastPayout(pool.PoolAppId)
+		snapshot, err := d.reti.GetPoolSnapshot(context.Background(), pool.PoolAppId)
 		if err == nil {
-			earliestEpochToUse = min(earliestEpochToUse, nextEpoch(lastPayout, epochRoundLength))
+			earliestEpochToUse = min(earliestEpochToUse, nextEpoch(snapshot.LastPayout, epochRoundLength))
 		}
 	}
 	return earliestEpochToUse
@@ -687,12 +859,29 @@ func (d *Daemon) getFirstEligibleEpochRound(curRound uint64, epochRoundLength ui
 func (d *Daemon) StakerEvictor(ctx context.Context) {
 	d.logger.Info("StakerEvictor started")
 	defer d.logger.Info("StakerEvictor stopped")
+	d.heartbeat("StakerEvictor")
+
+	if !d.waitForBlocks(ctx) {
+		return
+	}
+	rounds, unsubscribe := d.blocks.Subscribe()
+	defer unsubscribe()
+	heartbeat := time.NewTicker(workerHeartbeatInterval)
+	defer heartbeat.Stop()
+	nextCheck := d.blocks.LastRound() + evictionCheckRoundInterval
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-time.After(5 * time.Minute):
+		case <-heartbeat.C:
+			d.heartbeat("StakerEvictor")
+		case header := <-rounds:
+			d.heartbeat("StakerEvictor")
+			if header.Round < nextCheck {
+				break
+			}
+			nextCheck = header.Round + evictionCheckRoundInterval
 			err := d.checkForEvictions(ctx)
 			if err != nil {
 				misc.Errorf(d.logger, "error in eviction check: checking for evictions, err:%v", err)
@@ -703,8 +892,8 @@ func (d *Daemon) StakerEvictor(ctx context.Context) {
 
 // accountHasAtLeast checks if an account has at least a certain amount of microAlgos (spendable)
 // Errors are just treated as failures
-func accountHasAtLeast(ctx context.Context, algoClient *algod.Client, accountAddr string, microAlgos uint64) bool {
-	acctInfo, err := algo.GetBareAccount(ctx, algoClient, accountAddr)
+func accountHasAtLeast(ctx context.Context, accounts algo.AccountReader, accountAddr string, microAlgos uint64) bool {
+	acctInfo, err := algo.GetBareAccount(ctx, accounts, accountAddr)
 	if err != nil {
 		return false
 	}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/mnemonic"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/events"
+	"github.com/TxnLab/reti/internal/lib/reti"
+)
+
+const (
+	testRetiAppID   = 1000
+	testBlockTime   = 3 * time.Second
+	testStartRound  = 1000
+	testPoolAppID   = 2001
+	testPoolAppID2  = 2002
+	testEpochLength = 100
+)
+
+type daemonTest struct {
+	ledger  *algo.FakeLedger
+	daemon  *Daemon
+	manager string
+}
+
+// newDaemonTest returns a daemon for validator 1, node 1 (with local pools 1 and 2) running against a fake ledger at
+// round testStartRound.
+func newDaemonTest(t *testing.T) *daemonTest {
+	t.Helper()
+	manager := crypto.GenerateAccount()
+	managerMnemonic, err := mnemonic.FromPrivateKey(manager.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_MANAGER_MNEMONIC", managerMnemonic)
+	// the fake ledger generates keys right away
+	pollInterval := algo.PartKeyPollInterval
+	algo.PartKeyPollInterval = time.Millisecond
+	t.Cleanup(func() { algo.PartKeyPollInterval = pollInterval })
+
+	var (
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+		ledger = algo.NewFakeLedger()
+	)
+	ledger.BlockTime = testBlockTime
+	ledger.AdvanceRound(testStartRound - ledger.Round())
+	ledger.SetAccount(models.Account{Address: manager.Address.String(), Amount: 100e6})
+	for _, poolAppID := range []uint64{testPoolAppID, testPoolAppID2} {
+		ledger.SetAccount(models.Account{
+			Address:           crypto.GetApplicationAddress(poolAppID).String(),
+			Amount:            1000e6,
+			MinBalance:        1e5,
+			IncentiveEligible: true,
+		})
+	}
+
+	retiClient, err := reti.New(testRetiAppID, logger, ledger, algo.NewLocalKeyStore(logger), 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	retiClient.SetInfo(reti.ValidatorInfo{
+		Config: reti.ValidatorConfig{
+			ID:               1,
+			Owner:            manager.Address.String(),
+			Manager:          manager.Address.String(),
+			EpochRoundLength: testEpochLength,
+		},
+		Pools:      []reti.PoolInfo{{PoolAppId: testPoolAppID}, {PoolAppId: testPoolAppID2}},
+		LocalPools: map[uint64]uint64{1: testPoolAppID, 2: testPoolAppID2},
+	})
+
+	d := newDaemon(ledger, retiClient, algo.NewLocalKeyStore(logger))
+	if err = d.blocks.seed(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return &daemonTest{ledger: ledger, daemon: d, manager: manager.Address.String()}
+}
+
+// generateKey adds a participation key for the account, valid for the specified rounds
+func (dt *daemonTest) generateKey(t *testing.T, account string, first, last uint64) algo.ParticipationKey {
+	t.Helper()
+	if err := dt.ledger.GenerateParticipationKeys(context.Background(), account, algo.GenerateParticipationKeysParams{First: first, Last: last}); err != nil {
+		t.Fatal(err)
+	}
+	keys, _ := dt.ledger.ParticipationKeys(context.Background())
+	return keys[len(keys)-1]
+}
+
+// appCalls returns the args of the sent app calls of the specified method
+func (dt *daemonTest) appCalls(t *testing.T, methodSig string) [][][]byte {
+	t.Helper()
+	method, err := abi.MethodFromSignature(methodSig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls [][][]byte
+	for _, stxn := range dt.ledger.Submitted() {
+		if stxn.Txn.Type == types.ApplicationCallTx && len(stxn.Txn.ApplicationArgs) > 0 &&
+			bytes.Equal(stxn.Txn.ApplicationArgs[0], method.GetSelector()) {
+			calls = append(calls, stxn.Txn.ApplicationArgs)
+		}
+	}
+	return calls
+}
+
+const (
+	goOnlineMethod  = "goOnline(pay,byte[],byte[],byte[],uint64,uint64,uint64)void"
+	goOfflineMethod = "goOffline()void"
+)
+
+func TestEnsureParticipation(t *testing.T) {
+	type keyRange struct{ first, last uint64 }
+	const (
+		offline    = -1
+		missingKey = -2
+	)
+	policy := KeyPolicy{Length: 3000 * testBlockTime, RenewLead: 300 * testBlockTime, MaxPending: 1}
+	tests := []struct {
+		name string
+		// keys the pool already has, and which of them it's online against (or offline / missingKey)
+		keys     []keyRange
+		onlineAt int
+		// maxPending overrides the policy's max pending keys if set
+		maxPending int
+		// keys which should be created
+		wantNewKeys []keyRange
+		// key (index into keys) the pool should go online against, or -1
+		wantOnline  int
+		wantOffline bool
+	}{
+		{
+			name:        "no keys creates a key",
+			onlineAt:    offline,
+			wantNewKeys: []keyRange{{testStartRound, testStartRound + 3000}},
+			wantOnline:  -1,
+		},
+		{
+			name:       "offline goes online against the newest key",
+			keys:       []keyRange{{900, 3900}, {500, 3500}},
+			onlineAt:   offline,
+			wantOnline: 0,
+		},
+		{
+			name:       "online and not expiring does nothing",
+			keys:       []keyRange{{500, 3500}},
+			onlineAt:   0,
+			wantOnline: -1,
+		},
+		{
+			name:        "online and expiring creates a renewal key",
+			keys:        []keyRange{{100, 1200}},
+			onlineAt:    0,
+			wantNewKeys: []keyRange{{testStartRound, testStartRound + 3000}},
+			wantOnline:  -1,
+		},
+		{
+			name:       "expiring with max pending keys does nothing",
+			keys:       []keyRange{{100, 1200}, {1100, 1250}},
+			onlineAt:   0,
+			wantOnline: -1,
+		},
+		{
+			name:        "expiring pending key under max pending creates another key",
+			keys:        []keyRange{{100, 1200}, {1100, 1250}},
+			onlineAt:    0,
+			maxPending:  2,
+			wantNewKeys: []keyRange{{testStartRound, testStartRound + 3000}},
+			wantOnline:  -1,
+		},
+		{
+			name:       "newer key now valid switches to it",
+			keys:       []keyRange{{100, 1200}, {900, 3900}},
+			onlineAt:   0,
+			wantOnline: 1,
+		},
+		{
+			name:       "newer key not yet valid does nothing",
+			keys:       []keyRange{{100, 3000}, {1100, 4000}},
+			onlineAt:   0,
+			wantOnline: -1,
+		},
+		{
+			name:        "online against a missing key goes offline",
+			keys:        []keyRange{{500, 3500}},
+			onlineAt:    missingKey,
+			wantOnline:  -1,
+			wantOffline: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				ctx         = context.Background()
+				dt          = newDaemonTest(t)
+				poolAccount = crypto.GetApplicationAddress(testPoolAppID).String()
+				keys        []algo.ParticipationKey
+			)
+			dt.daemon.keyPolicies = KeyPolicies{Default: policy}
+			if tt.maxPending != 0 {
+				dt.daemon.keyPolicies.Default.MaxPending = tt.maxPending
+			}
+			for _, key := range tt.keys {
+				keys = append(keys, dt.generateKey(t, poolAccount, key.first, key.last))
+			}
+			info := onlineInfo{poolId: 1, poolAppId: testPoolAppID, isOnline: tt.onlineAt != offline}
+			switch {
+			case tt.onlineAt >= 0:
+				info.selectionParticipationKey = keys[tt.onlineAt].Key.SelectionParticipationKey
+				info.firstValid = keys[tt.onlineAt].Key.VoteFirstValid
+			case tt.onlineAt == missingKey:
+				info.selectionParticipationKey = bytes.Repeat([]byte{1}, 32)
+			}
+			partKeys, err := algo.GetParticipationKeys(ctx, dt.ledger)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err = dt.daemon.ensureParticipation(ctx, map[string]onlineInfo{poolAccount: info}, partKeys); err != nil {
+				t.Fatalf("ensureParticipation: %v", err)
+			}
+
+			allKeys, _ := dt.ledger.ParticipationKeys(ctx)
+			newKeys := allKeys[len(keys):]
+			if len(newKeys) != len(tt.wantNewKeys) {
+				t.Fatalf("created %d keys, want %d", len(newKeys), len(tt.wantNewKeys))
+			}
+			for i, key := range newKeys {
+				if key.Address != poolAccount || key.Key.VoteFirstValid != tt.wantNewKeys[i].first || key.Key.VoteLastValid != tt.wantNewKeys[i].last {
+					t.Errorf("created key for %s valid %d-%d, want %d-%d", key.Address, key.Key.VoteFirstValid, key.Key.VoteLastValid, tt.wantNewKeys[i].first, tt.wantNewKeys[i].last)
+				}
+			}
+
+			onlineCalls := dt.appCalls(t, goOnlineMethod)
+			switch {
+			case tt.wantOnline == -1 && len(onlineCalls) != 0:
+				t.Errorf("went online %d times, want none", len(onlineCalls))
+			case tt.wantOnline >= 0 && len(onlineCalls) != 1:
+				t.Errorf("went online %d times, want once", len(onlineCalls))
+			case tt.wantOnline >= 0:
+				// args: selector, vote, selection, state proof, ... (abi encoded byte[] have a 2 byte length prefix)
+				wantKey := keys[tt.wantOnline]
+				if !bytes.Equal(onlineCalls[0][2][2:], wantKey.Key.SelectionParticipationKey) {
+					t.Errorf("went online against the wrong key, want key:%s", wantKey.Id)
+				}
+			}
+			if offlineCalls := dt.appCalls(t, goOfflineMethod); (len(offlineCalls) == 1) != tt.wantOffline {
+				t.Errorf("went offline %d times, want offline:%v", len(offlineCalls), tt.wantOffline)
+			}
+		})
+	}
+}
+
+func TestGetFirstEligibleEpochRound(t *testing.T) {
+	tests := []struct {
+		name        string
+		lastPayouts []uint64 // per pool - 0 means the pool has no state
+		curRound    uint64
+		want        uint64
+	}{
+		{name: "no pool state uses the current epoch", curRound: 1050, want: 1000},
+		{name: "paid out pools use the current epoch", lastPayouts: []uint64{1010, 1020}, curRound: 1050, want: 1000},
+		{name: "a pool behind uses its next epoch", lastPayouts: []uint64{1010, 850}, curRound: 1050, want: 900},
+		{name: "oldest pool wins", lastPayouts: []uint64{720, 850}, curRound: 1050, want: 800},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dt := newDaemonTest(t)
+			for i, lastPayout := range tt.lastPayouts {
+				if lastPayout == 0 {
+					continue
+				}
+				dt.ledger.SetApplication(models.Application{
+					Id: dt.daemon.reti.Info().Pools[i].PoolAppId,
+					Params: models.ApplicationParams{GlobalState: []models.TealKeyValue{{
+						Key:   base64.StdEncoding.EncodeToString([]byte(reti.StakePoolLastPayout)),
+						Value: models.TealValue{Type: 2, Uint: lastPayout},
+					}}},
+				})
+			}
+			if got := dt.daemon.getFirstEligibleEpochRound(tt.curRound, testEpochLength); got != tt.want {
+				t.Errorf("getFirstEligibleEpochRound(%d) = %d, want %d", tt.curRound, got, tt.want)
+			}
+		})
+	}
+}
+
+// captureSink records the events sent to it
+type captureSink struct {
+	sync.Mutex
+	events []events.Event
+}
+
+func (c *captureSink) Name() string { return "capture" }
+
+func (c *captureSink) Send(_ context.Context, event events.Event) error {
+	c.Lock()
+	defer c.Unlock()
+	c.events = append(c.events, event)
+	return nil
+}
+
+func TestPoolOfflineNotification(t *testing.T) {
+	tests := []struct {
+		name         string
+		takenOffline bool
+		wantNotified bool
+	}{
+		{name: "pool going offline is notified", wantNotified: true},
+		{name: "pool we took offline isn't notified", takenOffline: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				ctx         = context.Background()
+				dt          = newDaemonTest(t)
+				poolAccount = crypto.GetApplicationAddress(testPoolAppID).String()
+				sink        = &captureSink{}
+				info        = onlineInfo{poolId: 1, poolAppId: testPoolAppID, isOnline: true}
+			)
+			dt.daemon.events = events.NewBus(dt.daemon.logger)
+			dt.daemon.events.AddSink(sink, events.SeverityInfo, 0)
+
+			// first check sees the pool online, w/ a key other than the one it's online against
+			dt.generateKey(t, poolAccount, 500, 3500)
+			info.selectionParticipationKey = bytes.Repeat([]byte{1}, 32)
+			dt.daemon.checkPoolsStillOnline(map[string]onlineInfo{poolAccount: info})
+			if tt.takenOffline {
+				partKeys, _ := algo.GetParticipationKeys(ctx, dt.ledger)
+				if err := dt.daemon.ensureParticipation(ctx, map[string]onlineInfo{poolAccount: info}, partKeys); err != nil {
+					t.Fatal(err)
+				}
+				if len(dt.appCalls(t, goOfflineMethod)) != 1 {
+					t.Fatal("pool wasn't taken offline")
+				}
+			}
+			info.isOnline = false
+			dt.daemon.checkPoolsStillOnline(map[string]onlineInfo{poolAccount: info})
+			dt.daemon.events.Close(time.Second)
+
+			notified := slices.ContainsFunc(sink.events, func(event events.Event) bool { return event.Type == events.TypePoolOffline })
+			if notified != tt.wantNotified {
+				t.Errorf("pool offline notified:%v, want %v", notified, tt.wantNotified)
+			}
+		})
+	}
+}
@@ -7,23 +7,25 @@ import (
 	"maps"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 	"github.com/antihax/optional"
 	"github.com/mailgun/holster/v4/syncutil"
 
+	"github.com/TxnLab/reti/internal/lib/events"
 	"github.com/TxnLab/reti/internal/lib/misc"
 	"github.com/TxnLab/reti/internal/lib/nfdapi/swagger"
 	"github.com/TxnLab/reti/internal/lib/reti"
 )
 
 func (d *Daemon) checkForEvictions(ctx context.Context) error {
-	info := App.retiClient.Info()
+	info := d.reti.Info()
 	if info.Config.EntryGatingType == reti.GatingTypeNone {
 		return nil
 	}
-	signer, err := App.signer.FindFirstSigner([]string{info.Config.Owner, info.Config.Manager})
+	signer, err := d.signer.FindFirstSigner([]string{info.Config.Owner, info.Config.Manager})
 	if err != nil {
 		return fmt.Errorf("neither owner or manager address for your validator has local keys present")
 	}
@@ -40,11 +42,18 @@ func (d *Daemon) checkForEvictions(ctx context.Context) error {
 	for _, staker := range ineligible {
 		for _, pool := range stakersAndPools[staker] {
 			stakerAddr, _ := types.DecodeAddress(staker)
-			err = App.retiClient.RemoveStake(pool, signerAddr, stakerAddr, 0 /* all stake */)
+			err = d.reti.RemoveStake(pool, signerAddr, stakerAddr, 0 /* all stake */)
+			action := evictionAction{Time: time.Now(), Staker: staker, PoolId: pool.PoolId, PoolAppId: pool.PoolAppId}
+			if err != nil {
+				action.Error = err.Error()
+			}
+			d.recordEviction(action)
 			if err != nil {
 				return fmt.Errorf("error removing stake for pool %d, appid:%d: %v", pool.PoolId, pool.PoolAppId, err)
 			}
 			misc.Infof(d.logger, "[EVICTION] Staker:%s removed from pool %d because no longer meeting gating criteria", staker, pool.PoolId)
+			d.notify(events.TypeStakerEvicted, events.SeverityWarning, pool.PoolId, staker,
+				"staker:%s removed from pool because no longer meeting gating criteria", staker)
 		}
 	}
 	return nil
@@ -55,7 +64,7 @@ func (d *Daemon) collectStakersAndPools(info reti.ValidatorInfo) (map[string][]r
 	stakersAndPools := make(map[string][]reti.ValidatorPoolKey)
 
 	for poolIdx, pool := range info.Pools {
-		ledger, err := App.retiClient.GetLedgerForPool(pool.PoolAppId)
+		ledger, err := d.reti.GetLedgerForPool(pool.PoolAppId)
 		if err != nil {
 			if strings.Contains(err.Error(), "box not found") {
 				continue
@@ -114,11 +123,11 @@ func (d *Daemon) getIneligibleStakers(ctx context.Context, accounts iter.Seq[str
 }
 
 func (d *Daemon) isAccountEligible(ctx context.Context, account string) (bool, error) {
-	info := App.retiClient.Info()
+	info := d.reti.Info()
 	gatingMinBalance := info.Config.GatingAssetMinBalance
 
 	// get all assets held by the staking account first
-	accountInfo, err := d.algoClient.AccountInformation(account).Do(ctx)
+	accountInfo, err := d.chain.AccountInformation(ctx, account)
 	if err != nil {
 		return false, fmt.Errorf("error getting account info for account %s: %v", account, err)
 	}
@@ -143,7 +152,7 @@ func (d *Daemon) isAccountEligible(ctx context.Context, account string) (bool, e
 		valToVerify = d.findValueToVerify(heldAssets, gatingAssetIds, gatingMinBalance)
 	case reti.GatingTypeCreatedByNFDAddresses:
 		nfdAppId := info.Config.EntryGatingAssets[0]
-		nfd, err := App.nfdOnChain.GetNFD(ctx, nfdAppId, true)
+		nfd, err := d.nfdOnChain.GetNFD(ctx, nfdAppId, true)
 		if err != nil {
 			return false, fmt.Errorf("error getting nfd info for appid %d: %v", nfdAppId, err)
 		}
@@ -156,7 +165,7 @@ func (d *Daemon) isAccountEligible(ctx context.Context, account string) (bool, e
 		}
 		return d.findValueToVerify(heldAssets, createdAssetIds, gatingMinBalance) > 0, nil
 	case reti.GatingTypeSegmentOfNFD:
-		nfds, _, err := App.nfdApi.NfdApi.NfdSearchV2(ctx, &swagger.NfdApiNfdSearchV2Opts{
+		nfds, _, err := d.nfdApi.NfdApi.NfdSearchV2(ctx, &swagger.NfdApiNfdSearchV2Opts{
 			State:       optional.NewInterface("owned"),
 			Owner:       optional.NewString(account),
 			ParentAppID: optional.NewInt64(int64(info.Config.EntryGatingAssets[0])),
@@ -179,7 +188,7 @@ func (d *Daemon) isAccountEligible(ctx context.Context, account string) (bool, e
 func (d *Daemon) collectCreatedAssets(ctx context.Context, addresses []string) ([]uint64, error) {
 	assetIdMap := make(map[uint64]bool)
 	for _, address := range addresses {
-		creatorAccountInfo, err := d.algoClient.AccountInformation(address).Do(ctx)
+		creatorAccountInfo, err := d.chain.AccountInformation(ctx, address)
 		if err != nil {
 			return nil, fmt.Errorf("error getting account info for creator address %s: %v", address, err)
 		}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/reti"
+)
+
+// stakerLedgerBox returns the staker ledger box contents for the stakers (w/ an empty slot at the end)
+func stakerLedgerBox(stakers ...types.Address) []byte {
+	var box []byte
+	for _, staker := range append(stakers, types.ZeroAddress) {
+		record := make([]byte, 64)
+		copy(record, staker[:])
+		binary.BigEndian.PutUint64(record[32:40], 1000e6)
+		box = append(box, record...)
+	}
+	return box
+}
+
+func TestEvictionDecisions(t *testing.T) {
+	const gatingAsset = 500
+	var (
+		holder   = crypto.GenerateAccount().Address
+		tooFew   = crypto.GenerateAccount().Address
+		other    = crypto.GenerateAccount().Address
+		none     = crypto.GenerateAccount().Address
+		bothPool = crypto.GenerateAccount().Address
+	)
+	tests := []struct {
+		name           string
+		minBalance     uint64
+		holdings       map[types.Address][]models.AssetHolding
+		wantIneligible []types.Address
+	}{
+		{
+			name: "stakers must hold the gating asset",
+			holdings: map[types.Address][]models.AssetHolding{
+				holder:   {{AssetId: gatingAsset, Amount: 20}},
+				tooFew:   {{AssetId: gatingAsset, Amount: 5}},
+				other:    {{AssetId: gatingAsset + 1, Amount: 100}},
+				bothPool: {{AssetId: gatingAsset + 1, Amount: 1}, {AssetId: gatingAsset, Amount: 1}},
+			},
+			wantIneligible: []types.Address{other, none},
+		},
+		{
+			name:       "stakers must hold the minimum balance of the gating asset",
+			minBalance: 10,
+			holdings: map[types.Address][]models.AssetHolding{
+				holder:   {{AssetId: gatingAsset, Amount: 20}},
+				tooFew:   {{AssetId: gatingAsset, Amount: 5}},
+				other:    {{AssetId: gatingAsset + 1, Amount: 100}},
+				bothPool: {{AssetId: gatingAsset, Amount: 10}},
+			},
+			wantIneligible: []types.Address{tooFew, other, none},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dt := newDaemonTest(t)
+			info := dt.daemon.reti.Info()
+			info.Config.EntryGatingType = reti.GatingTypeAssetId
+			info.Config.EntryGatingAssets = []uint64{gatingAsset, 0, 0, 0}
+			info.Config.GatingAssetMinBalance = tt.minBalance
+			dt.daemon.reti.SetInfo(info)
+
+			dt.ledger.SetBox(testPoolAppID, reti.GetStakerLedgerBoxName(), stakerLedgerBox(holder, tooFew, bothPool))
+			dt.ledger.SetBox(testPoolAppID2, reti.GetStakerLedgerBoxName(), stakerLedgerBox(other, none, bothPool))
+			for staker, holdings := range tt.holdings {
+				dt.ledger.SetAccount(models.Account{Address: staker.String(), Amount: 1e6, Assets: holdings})
+			}
+
+			stakersAndPools, err := dt.daemon.collectStakersAndPools(info)
+			if err != nil {
+				t.Fatalf("collectStakersAndPools: %v", err)
+			}
+			if len(stakersAndPools) != 5 {
+				t.Errorf("collected %d stakers, want 5", len(stakersAndPools))
+			}
+			if pools := stakersAndPools[bothPool.String()]; len(pools) != 2 || pools[0].PoolId != 1 || pools[1].PoolId != 2 {
+				t.Errorf("staker in both pools collected with pools %v, want pool 1 and 2", pools)
+			}
+
+			ineligible, err := dt.daemon.getIneligibleStakers(context.Background(), maps.Keys(stakersAndPools))
+			if err != nil {
+				t.Fatalf("getIneligibleStakers: %v", err)
+			}
+			var want []string
+			for _, staker := range tt.wantIneligible {
+				want = append(want, staker.String())
+			}
+			slices.Sort(ineligible)
+			slices.Sort(want)
+			if !slices.Equal(ineligible, want) {
+				t.Errorf("ineligible stakers %v, want %v", ineligible, want)
+			}
+		})
+	}
+}
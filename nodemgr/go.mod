@@ -11,8 +11,10 @@ require (
 	github.com/prometheus/client_golang v1.20.4
 	github.com/ssgreg/repeat v1.5.1
 	github.com/urfave/cli/v3 v3.0.0-alpha9.1
+	go.etcd.io/bbolt v1.3.11
 	golang.org/x/crypto v0.28.0
 	golang.org/x/oauth2 v0.23.0
+	golang.org/x/sys v0.26.0
 	golang.org/x/term v0.25.0
 )
 
@@ -28,7 +30,5 @@ require (
 	github.com/prometheus/client_model v0.6.1 // indirect
 	github.com/prometheus/common v0.55.0 // indirect
 	github.com/prometheus/procfs v0.15.1 // indirect
-	github.com/xrash/smetrics v0.0.0-20240521201337-686a1a2994c1 // indirect
-	golang.org/x/sys v0.26.0 // indirect
 	google.golang.org/protobuf v1.34.2 // indirect
 )
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+)
+
+const (
+	// DefaultReadyMaxRoundLag is how many rounds (based on avg block time) algod can be behind before we're not ready
+	DefaultReadyMaxRoundLag = 5
+	// maxStateAge is how long since the last successful validator state load before we're not ready.  State is
+	// refetched every minute so this allows for several consecutive failures.
+	maxStateAge = 10 * time.Minute
+	// how long health checks have to fetch what they need from algod
+	healthCheckTimeout = 5 * time.Second
+)
+
+// workerHeartbeatMaxAge is how long each worker can go without a heartbeat before it's considered wedged.
+var workerHeartbeatMaxAge = map[string]time.Duration{
+	"KeyWatcher":    5 * time.Minute,
+	"EpochUpdater":  5 * time.Minute,
+	"StakerEvictor": 15 * time.Minute,
+}
+
+const (
+	checkOK      = "ok"
+	checkFailed  = "fail"
+	checkSkipped = "skipped"
+)
+
+type healthCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+type healthResponse struct {
+	Status string        `json:"status"`
+	Checks []healthCheck `json:"checks"`
+}
+
+func (d *Daemon) heartbeat(worker string) {
+	d.Lock()
+	defer d.Unlock()
+	d.heartbeats[worker] = time.Now()
+}
+
+// keepHeartbeat keeps heartbeating for worker (in the background) until the returned func is called.  Used to
+// cover long-running operations (like participation key generation) which have their own timeouts.
+func (d *Daemon) keepHeartbeat(worker string) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				d.heartbeat(worker)
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func (d *Daemon) stateLoaded() {
+	d.Lock()
+	defer d.Unlock()
+	d.lastStateLoad = time.Now()
+}
+
+// isLive is the liveness probe - it fails only if one of our workers appears to be wedged, as that's something
+// a restart would fix.
+func (d *Daemon) isLive() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, d.checkWorkers())
+	})
+}
+
+// isReady is the readiness probe - algod has to be synced, our validator state current, and every funded pool
+// assigned to this node online against a participation key present on our node.
+func (d *Daemon) isReady() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+		defer cancel()
+
+		var checks []healthCheck
+		checks = append(checks, d.checkAlgodSynced(ctx))
+		checks = append(checks, d.checkStateFresh())
+		checks = append(checks, d.checkPoolsOnline(ctx))
+		writeHealth(w, checks)
+	})
+}
+
+func (d *Daemon) checkWorkers() []healthCheck {
+	d.RLock()
+	defer d.RUnlock()
+
+	var checks []healthCheck
+	for _, worker := range slices.Sorted(maps.Keys(workerHeartbeatMaxAge)) {
+		check := healthCheck{Name: "worker:" + worker, Status: checkOK}
+		lastBeat, found := d.heartbeats[worker]
+		switch {
+		case !d.active:
+			check.Status = checkSkipped
+			check.Detail = "standby instance, workers not running"
+		case !found:
+			// workers (like StakerEvictor) which aren't needed never start
+			check.Status = checkSkipped
+			check.Detail = "worker not running"
+		case time.Since(lastBeat) > workerHeartbeatMaxAge[worker]:
+			check.Status = checkFailed
+			check.Detail = fmt.Sprintf("no heartbeat since %s (%v ago)", lastBeat.UTC().Format(time.RFC3339), time.Since(lastBeat).Round(time.Second))
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func (d *Daemon) checkAlgodSynced(ctx context.Context) healthCheck {
+	check := healthCheck{Name: "algod-synced", Status: checkFailed}
+	status, err := d.chain.Status(ctx)
+	if err != nil {
+		check.Detail = fmt.Sprintf("unable to fetch algod status: %v", err)
+		return check
+	}
+	if status.CatchupTime != 0 {
+		check.Detail = fmt.Sprintf("algod is catching up, at round:%d", status.LastRound)
+		return check
+	}
+	avgBlockTime := d.AverageBlockTime()
+	if avgBlockTime == 0 {
+		// not known yet - assume the worst case
+		avgBlockTime = 5 * time.Second
+	}
+	maxLag := time.Duration(d.readyMaxRoundLag) * avgBlockTime
+	if sinceLast := time.Duration(status.TimeSinceLastRound); sinceLast > maxLag {
+		check.Detail = fmt.Sprintf("algod at round:%d, no new round in %v - more than %d rounds behind", status.LastRound, sinceLast.Round(time.Millisecond), d.readyMaxRoundLag)
+		return check
+	}
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("at round:%d", status.LastRound)
+	return check
+}
+
+func (d *Daemon) checkStateFresh() healthCheck {
+	d.RLock()
+	lastLoad, active := d.lastStateLoad, d.active
+	d.RUnlock()
+
+	check := healthCheck{Name: "validator-state", Status: checkOK}
+	if !active {
+		// only the active instance's KeyWatcher refetches state
+		check.Status = checkSkipped
+		check.Detail = "standby instance, state refreshed by active instance"
+		return check
+	}
+	if time.Since(lastLoad) > maxStateAge {
+		check.Status = checkFailed
+		check.Detail = fmt.Sprintf("validator state last loaded %v ago", time.Since(lastLoad).Round(time.Second))
+	}
+	return check
+}
+
+func (d *Daemon) checkPoolsOnline(ctx context.Context) healthCheck {
+	check := healthCheck{Name: "pools-online", Status: checkFailed}
+	if !d.isActive() {
+		check.Status = checkSkipped
+		check.Detail = "standby instance, pools managed by active instance"
+		return check
+	}
+	partKeys, err := algo.GetParticipationKeys(ctx, d.chain)
+	if err != nil {
+		check.Detail = fmt.Sprintf("unable to fetch participation keys: %v", err)
+		return check
+	}
+	var problems []string
+	for poolId, poolAppId := range d.reti.Info().LocalPools {
+		address := crypto.GetApplicationAddress(poolAppId).String()
+		snapshot, err := d.reti.GetPoolSnapshot(ctx, poolAppId)
+		if err != nil {
+			check.Detail = fmt.Sprintf("unable to fetch account:%s, err:%v", address, err)
+			return check
+		}
+		acctInfo := snapshot.Account
+		if acctInfo.Amount <= acctInfo.MinBalance+1e6 {
+			// not funded - we don't bring those online
+			continue
+		}
+		if acctInfo.Status != OnlineStatus {
+			problems = append(problems, fmt.Sprintf("pool %d (app id:%d) is offline", poolId, poolAppId))
+			continue
+		}
+		if !slices.ContainsFunc(partKeys[address], func(key algo.ParticipationKey) bool {
+			return bytes.Equal(key.Key.SelectionParticipationKey, acctInfo.Participation.SelectionParticipationKey)
+		}) {
+			problems = append(problems, fmt.Sprintf("pool %d (app id:%d) is online but its participation key isn't present on this node", poolId, poolAppId))
+		}
+	}
+	if len(problems) > 0 {
+		slices.Sort(problems)
+		check.Detail = strings.Join(problems, "; ")
+		return check
+	}
+	check.Status = checkOK
+	return check
+}
+
+func writeHealth(w http.ResponseWriter, checks []healthCheck) {
+	resp := healthResponse{Status: checkOK, Checks: checks}
+	for _, check := range checks {
+		if check.Status == checkFailed {
+			resp.Status = checkFailed
+		}
+	}
+	if resp.Status != checkOK {
+		writeJSON(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
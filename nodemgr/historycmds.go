@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/history"
+	"github.com/TxnLab/reti/internal/lib/misc"
+	"github.com/TxnLab/reti/internal/lib/reti"
+)
+
+func GetHistoryCmdOpts() *cli.Command {
+	return &cli.Command{
+		Name:     "history",
+		Usage:    "Show the history of actions taken (epoch updates, going online/offline, key changes, evictions, etc.)",
+		Metadata: standaloneCommand,
+		Action:   HistoryList,
+		Flags: []cli.Flag{
+			&cli.UintFlag{
+				Name:  "pool",
+				Usage: "Only show actions for this pool id (the number in 'pool list')",
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "Only show actions of this type (ie: epochBalanceUpdate, goOnline, goOffline, removeStake, keyCreate, keyDelete, managerTopUp)",
+			},
+			&cli.StringFlag{
+				Name:  "since",
+				Usage: "Only show actions after this time - either a date/time (2006-01-02, 2006-01-02T15:04:05Z) or how long ago (ie: 36h, 7d)",
+			},
+			&cli.StringFlag{
+				Name:  "until",
+				Usage: "Only show actions before this time - same format as since",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "Maximum number of (most recent) actions to show",
+				Value: 100,
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Output as json (one action per line)",
+			},
+		},
+	}
+}
+
+func HistoryList(ctx context.Context, command *cli.Command) error {
+	store, err := getHistoryStore(command.String("history"))
+	if err != nil {
+		return err
+	}
+	if store == nil {
+		return errors.New("no history database configured - set via --history or RETI_HISTORY_DB")
+	}
+	query := history.Query{
+		PoolId: command.Uint("pool"),
+		Type:   command.String("type"),
+		Limit:  int(command.Int("limit")),
+	}
+	if query.Since, err = parseHistoryTime(command.String("since")); err != nil {
+		return fmt.Errorf("invalid since value: %w", err)
+	}
+	if query.Until, err = parseHistoryTime(command.String("until")); err != nil {
+		return fmt.Errorf("invalid until value: %w", err)
+	}
+	actions, err := store.Query(query)
+	if err != nil {
+		return err
+	}
+	if command.Bool("json") {
+		enc := json.NewEncoder(os.Stdout)
+		for _, action := range actions {
+			if err := enc.Encode(action); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	out := new(strings.Builder)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Time\tType\tPool\tRound\tFees\tOutcome\tDetail\tTxn id\t")
+	for _, action := range actions {
+		var poolStr, roundStr, feeStr, txnStr string
+		if action.PoolId != 0 {
+			poolStr = fmt.Sprintf("%d", action.PoolId)
+		}
+		if action.Round != 0 {
+			roundStr = fmt.Sprintf("%d", action.Round)
+		}
+		if action.Fees != 0 {
+			feeStr = algo.FormattedAlgoAmount(action.Fees)
+		}
+		if len(action.TxIDs) > 0 {
+			// the last txn is the actual method call, any prior are payments, etc.
+			txnStr = action.TxIDs[len(action.TxIDs)-1]
+		}
+		outcome := "ok"
+		if !action.Succeeded() {
+			outcome = "FAILED: " + action.Error
+		}
+		if action.DryRun {
+			outcome = "dry-run " + outcome
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n", action.Time.Local().Format(time.DateTime), action.Type, poolStr,
+			roundStr, feeStr, outcome, action.Detail, txnStr)
+	}
+	tw.Flush()
+	fmt.Print(out.String())
+	return nil
+}
+
+// parseHistoryTime parses either an absolute date/time or a relative duration (how long ago)
+func parseHistoryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	ago, err := parseDurationWithDays(value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q isn't a valid date/time or duration", value)
+	}
+	return time.Now().Add(-ago), nil
+}
+
+// getHistoryStore returns the history store at path - nil if no path is configured (history disabled)
+func getHistoryStore(path string) (*history.Store, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return history.NewStore(path)
+}
+
+// recordHistory adds action to our history, if enabled
+func (ac *RetiApp) recordHistory(action history.Action) {
+	if ac.history == nil {
+		return
+	}
+	if err := ac.history.Record(action); err != nil {
+		misc.Warnf(ac.logger, "unable to record %s action in history, err:%v", action.Type, err)
+	}
+}
+
+// recordTxnHistory is the reti transaction observer which records every contract call made in our history
+func (ac *RetiApp) recordTxnHistory(result reti.TxnResult) {
+	if result.Exported {
+		// nothing was sent - it's submitted (if ever) once signed elsewhere
+		return
+	}
+	action := history.Action{
+		Type:   result.Method,
+		Round:  result.Round,
+		TxIDs:  result.TxIDs,
+		Fees:   result.Fees,
+		Detail: result.Detail,
+		DryRun: result.DryRun,
+	}
+	if result.Err != nil {
+		action.Error = result.Err.Error()
+	}
+	for i, pool := range ac.retiClient.Info().Pools {
+		if pool.PoolAppId == result.AppID {
+			action.PoolId = uint64(i + 1)
+			action.PoolAppId = pool.PoolAppId
+			break
+		}
+	}
+	ac.recordHistory(action)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"log/slog"
+	"math"
 	"math/big"
 	"net/http"
 	"net/url"
@@ -30,13 +31,21 @@ func FormattedAlgoAmount(microAlgos uint64) string {
 	return formattedAmount
 }
 
+// AlgosToMicroAlgos converts an amount of ALGO (ie: from a flag) to microAlgos - rounding to the nearest microAlgo,
+// as most decimal amounts aren't exact as floats (ie: 0.29 ALGO would otherwise be 289999 microAlgos).  Negative
+// amounts are 0.
+func AlgosToMicroAlgos(algos float64) uint64 {
+	return uint64(math.Round(max(algos, 0) * 1e6))
+}
+
 func GetAlgoClient(log *slog.Logger, config NetworkConfig) (*algod.Client, error) {
 	var (
-		apiURL     string
-		apiToken   string
-		apiHeaders []*common.Header
-		serverAddr *url.URL
-		err        error
+		apiURL      string
+		apiToken    string
+		tokenSecret string
+		apiHeaders  []*common.Header
+		serverAddr  *url.URL
+		err         error
 	)
 	if config.NodeDataDir != "" {
 		// Read address and admin token from main-net directory
@@ -49,6 +58,7 @@ func GetAlgoClient(log *slog.Logger, config NetworkConfig) (*algod.Client, error
 	} else {
 		apiURL = config.NodeURL
 		apiToken = config.NodeToken
+		tokenSecret = config.NodeTokenSecret
 		// Convert config.NodeHeaders map into []*common.Header slice
 		for key, value := range config.NodeHeaders {
 			apiHeaders = append(apiHeaders, &common.Header{
@@ -74,7 +84,31 @@ func GetAlgoClient(log *slog.Logger, config NetworkConfig) (*algod.Client, error
 	customTransport.MaxIdleConns = 100
 	customTransport.MaxConnsPerHost = 100
 	customTransport.MaxIdleConnsPerHost = 100
-	client, err := algod.MakeClientWithTransport(serverAddr.String(), apiToken, apiHeaders, customTransport)
+
+	var transport http.RoundTripper = customTransport
+	if tokenSecret != "" {
+		transport = &tokenTransport{base: customTransport, token: secretToken(tokenSecret, apiToken)}
+	}
+	if len(config.Endpoints) > 0 {
+		endpoints := []*algodEndpoint{{name: serverAddr.Host, url: serverAddr, token: secretToken(tokenSecret, apiToken), headers: map[string]string{}}}
+		for _, header := range apiHeaders {
+			endpoints[0].headers[header.Key] = header.Value
+		}
+		for _, endpoint := range config.Endpoints {
+			endpointAddr, err := url.Parse(strings.TrimRight(endpoint.URL, "/"))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse url:%v, error:%w", endpoint.URL, err)
+			}
+			misc.Infof(log, "Failover Algorand node at:%s", endpointAddr.String())
+			endpoints = append(endpoints, &algodEndpoint{name: endpointAddr.Host, url: endpointAddr, token: secretToken(endpoint.TokenSecret, endpoint.Token), headers: endpoint.Headers})
+		}
+		failover := newFailoverTransport(log, customTransport, endpoints)
+		// get initial health of all endpoints before first use
+		failover.checkAll()
+		go failover.monitor()
+		transport = failover
+	}
+	client, err := algod.MakeClientWithTransport(serverAddr.String(), apiToken, apiHeaders, transport)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to make algod client (url:%s), error:%w`, serverAddr.String(), err)
 	}
@@ -119,30 +153,30 @@ func GetStringFromGlobalState(globalState []models.TealKeyValue, keyName string)
 }
 
 // GetBareAccount just returns account information without asset data
-func GetBareAccount(ctx context.Context, algoClient *algod.Client, account string) (models.Account, error) {
-	return algoClient.AccountInformation(account).Exclude("all").Do(ctx)
+func GetBareAccount(ctx context.Context, accounts AccountReader, account string) (models.Account, error) {
+	return accounts.BareAccountInformation(ctx, account)
 }
 
-func GetVersionString(ctx context.Context, algoClient *algod.Client) (string, error) {
-	vers, err := algoClient.Versions().Do(ctx)
+func GetVersionString(ctx context.Context, node StatusReader) (string, error) {
+	vers, err := node.Versions(ctx)
 	if err != nil {
 		return "", fmt.Errorf("error fetching /versions from algod: %w", err)
 	}
 	return fmt.Sprintf("%d.%d.%d %s [%s]", vers.Build.Major, vers.Build.Minor, vers.Build.BuildNumber, vers.Build.Branch, vers.Build.CommitHash), nil
 }
 
-func CalcBlockTimes(ctx context.Context, algoClient *algod.Client, numRounds uint64) (time.Duration, error) {
-	status, err := algoClient.Status().Do(ctx)
+func CalcBlockTimes(ctx context.Context, node StatusReader, numRounds uint64) (time.Duration, error) {
+	status, err := node.Status(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("unable to fetch node status: %w", err)
 	}
 	var blockTimes []time.Time
 	for round := status.LastRound - numRounds; round < status.LastRound; round++ {
-		block, err := algoClient.Block(round).Do(ctx)
+		header, err := node.BlockHeader(ctx, round)
 		if err != nil {
 			return 0, fmt.Errorf("unable to fetch block in getAverageBlockTime, err:%w", err)
 		}
-		blockTimes = append(blockTimes, time.Unix(block.TimeStamp, 0))
+		blockTimes = append(blockTimes, time.Unix(header.TimeStamp, 0))
 	}
 	var totalBlockTime time.Duration
 	for i := 1; i < len(blockTimes); i++ {
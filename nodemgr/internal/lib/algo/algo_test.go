@@ -0,0 +1,24 @@
+package algo
+
+import "testing"
+
+func TestAlgosToMicroAlgos(t *testing.T) {
+	tests := []struct {
+		algos float64
+		want  uint64
+	}{
+		{0, 0},
+		{1, 1_000_000},
+		{0.29, 290_000},
+		{1.1, 1_100_000},
+		{2.675, 2_675_000},
+		{0.000001, 1},
+		{123456.789012, 123_456_789_012},
+		{-1, 0},
+	}
+	for _, tt := range tests {
+		if got := AlgosToMicroAlgos(tt.algos); got != tt.want {
+			t.Errorf("AlgosToMicroAlgos(%v) = %d, want %d", tt.algos, got, tt.want)
+		}
+	}
+}
@@ -31,6 +31,15 @@ type localKeyStore struct {
 	keys map[string]ed25519.PrivateKey
 }
 
+// Close zeroes the keys - the key store can't be used afterward
+func (lk *localKeyStore) Close() error {
+	for address, key := range lk.keys {
+		clear(key)
+		delete(lk.keys, address)
+	}
+	return nil
+}
+
 func (lk *localKeyStore) HasAccount(publicAddress string) bool {
 	_, found := lk.keys[publicAddress]
 	return found
@@ -115,14 +115,14 @@ func SignWithKey(signers []TxnSigner, privateKey ed25519.PrivateKey) []TxnSigner
 }
 
 func SignWithAccountForATC(keyManager MultipleWalletSigner, publicAddress string) transaction.TransactionSigner {
-	return &kmdSigner{
+	return &accountSigner{
 		keyManager: keyManager,
 		address:    publicAddress,
 	}
 }
 
 func SignWithAccount(signers []TxnSigner, keyManager MultipleWalletSigner, publicAddress string) []TxnSigner {
-	return append(signers, &kmdSigner{
+	return append(signers, &accountSigner{
 		keyManager: keyManager,
 		address:    publicAddress,
 	})
@@ -148,16 +148,17 @@ func (s *skSigner) SignTxn(ctx context.Context, tx types.Transaction) (string, [
 	return crypto.SignTransaction(s.sk, tx)
 }
 
-type kmdSigner struct {
+// accountSigner signs for a specific account via a MultipleWalletSigner
+type accountSigner struct {
 	keyManager MultipleWalletSigner
 	address    string
 }
 
-func (k *kmdSigner) SignTxn(ctx context.Context, tx types.Transaction) (string, []byte, error) {
+func (k *accountSigner) SignTxn(ctx context.Context, tx types.Transaction) (string, []byte, error) {
 	return k.keyManager.SignWithAccount(ctx, tx, k.address)
 }
 
-func (k *kmdSigner) SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
+func (k *accountSigner) SignTransactions(txGroup []types.Transaction, indexesToSign []int) ([][]byte, error) {
 	stxs := make([][]byte, len(indexesToSign))
 	for i, pos := range indexesToSign {
 		_, stxBytes, err := k.keyManager.SignWithAccount(context.Background(), txGroup[pos], k.address)
@@ -171,8 +172,8 @@ func (k *kmdSigner) SignTransactions(txGroup []types.Transaction, indexesToSign
 	return stxs, nil
 }
 
-func (k *kmdSigner) Equals(other transaction.TransactionSigner) bool {
-	if castedSigner, ok := other.(*kmdSigner); ok {
+func (k *accountSigner) Equals(other transaction.TransactionSigner) bool {
+	if castedSigner, ok := other.(*accountSigner); ok {
 		return castedSigner.address == k.address
 	}
 	return false
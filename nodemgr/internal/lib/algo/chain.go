@@ -0,0 +1,188 @@
+package algo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// The daemon and the réti client access the chain through these (narrow) interfaces rather than an algod client
+// directly, so their logic can be run against an in-memory ledger (see FakeLedger) rather than a live node.
+
+// AccountReader fetches account state
+type AccountReader interface {
+	// AccountInformation returns the account, including its asset holdings, created assets and apps, etc.
+	AccountInformation(ctx context.Context, address string) (models.Account, error)
+	// BareAccountInformation returns just the account - without any of its asset or app data
+	BareAccountInformation(ctx context.Context, address string) (models.Account, error)
+}
+
+// AppReader fetches application (global) state
+type AppReader interface {
+	Application(ctx context.Context, appID uint64) (models.Application, error)
+}
+
+// BoxReader fetches application boxes
+type BoxReader interface {
+	ApplicationBox(ctx context.Context, appID uint64, name []byte) (models.Box, error)
+}
+
+// PartKeyManager manages the participation keys of the node
+type PartKeyManager interface {
+	ParticipationKeys(ctx context.Context) ([]ParticipationKey, error)
+	// GenerateParticipationKeys requests the node generate a key for the account - the key is generated in the
+	// background, so it won't be returned by ParticipationKeys until some time later.
+	GenerateParticipationKeys(ctx context.Context, account string, params GenerateParticipationKeysParams) error
+	DeleteParticipationKey(ctx context.Context, partKeyID string) error
+}
+
+// StatusReader fetches the status of the node and the blocks it has
+type StatusReader interface {
+	Status(ctx context.Context) (models.NodeStatus, error)
+	// StatusAfterBlock waits for a block after the specified round, returning the node status at that time
+	StatusAfterBlock(ctx context.Context, round uint64) (models.NodeStatus, error)
+	BlockHeader(ctx context.Context, round uint64) (types.BlockHeader, error)
+	Versions(ctx context.Context) (models.Version, error)
+}
+
+// TxnSubmitter simulates and sends transactions
+type TxnSubmitter interface {
+	SuggestedParams(ctx context.Context) (types.SuggestedParams, error)
+	SimulateTransaction(ctx context.Context, request models.SimulateRequest) (models.SimulateResponse, error)
+	// SendRawTransaction sends the (msgpack encoded) signed transaction group, returning the id of the first
+	// transaction
+	SendRawTransaction(ctx context.Context, signedGroup []byte) (string, error)
+	PendingTransactionInformation(ctx context.Context, txid string) (models.PendingTransactionInfoResponse, error)
+}
+
+// Chain is everything the daemon (and réti client) needs of the chain
+type Chain interface {
+	AccountReader
+	AppReader
+	BoxReader
+	PartKeyManager
+	StatusReader
+	TxnSubmitter
+}
+
+// NewAlgodChain returns a Chain implemented by the algod node of algoClient
+func NewAlgodChain(algoClient *algod.Client) Chain {
+	return &algodChain{algoClient: algoClient}
+}
+
+type algodChain struct {
+	algoClient *algod.Client
+}
+
+func (a *algodChain) AccountInformation(ctx context.Context, address string) (models.Account, error) {
+	return a.algoClient.AccountInformation(address).Do(ctx)
+}
+
+func (a *algodChain) BareAccountInformation(ctx context.Context, address string) (models.Account, error) {
+	return a.algoClient.AccountInformation(address).Exclude("all").Do(ctx)
+}
+
+func (a *algodChain) Application(ctx context.Context, appID uint64) (models.Application, error) {
+	return a.algoClient.GetApplicationByID(appID).Do(ctx)
+}
+
+func (a *algodChain) ApplicationBox(ctx context.Context, appID uint64, name []byte) (models.Box, error) {
+	return a.algoClient.GetApplicationBoxByName(appID, name).Do(ctx)
+}
+
+func (a *algodChain) ParticipationKeys(ctx context.Context) ([]ParticipationKey, error) {
+	var response []ParticipationKey
+	err := (*common.Client)(a.algoClient).Get(ctx, &response, "/v2/participation", nil, nil)
+	return response, err
+}
+
+func (a *algodChain) GenerateParticipationKeys(ctx context.Context, account string, params GenerateParticipationKeysParams) error {
+	var response struct{}
+	return (*common.Client)(a.algoClient).Post(ctx, &response, fmt.Sprintf("/v2/participation/generate/%s", account), params, nil, nil)
+}
+
+func (a *algodChain) DeleteParticipationKey(ctx context.Context, partKeyID string) error {
+	var response string
+	return (*common.Client)(a.algoClient).Delete(ctx, &response, fmt.Sprintf("/v2/participation/%s", partKeyID), nil, nil)
+}
+
+func (a *algodChain) Status(ctx context.Context) (models.NodeStatus, error) {
+	return a.algoClient.Status().Do(ctx)
+}
+
+func (a *algodChain) StatusAfterBlock(ctx context.Context, round uint64) (models.NodeStatus, error) {
+	return a.algoClient.StatusAfterBlock(round).Do(ctx)
+}
+
+type blockHeaderParams struct {
+	Format     string `url:"format,omitempty"`
+	HeaderOnly bool   `url:"header-only,omitempty"`
+}
+
+// BlockHeader fetches just the header of the specified block - the sdk only supports fetching the entire block
+// (with all its transactions) which is wasteful when only the header data (timestamps, etc.) is needed.
+func (a *algodChain) BlockHeader(ctx context.Context, round uint64) (types.BlockHeader, error) {
+	var response models.BlockResponse
+	err := (*common.Client)(a.algoClient).GetRawMsgpack(ctx, &response, fmt.Sprintf("/v2/blocks/%d", round), blockHeaderParams{Format: "msgpack", HeaderOnly: true}, nil)
+	if err != nil {
+		return types.BlockHeader{}, err
+	}
+	return response.Block.BlockHeader, nil
+}
+
+func (a *algodChain) Versions(ctx context.Context) (models.Version, error) {
+	return a.algoClient.Versions().Do(ctx)
+}
+
+func (a *algodChain) SuggestedParams(ctx context.Context) (types.SuggestedParams, error) {
+	return a.algoClient.SuggestedParams().Do(ctx)
+}
+
+func (a *algodChain) SimulateTransaction(ctx context.Context, request models.SimulateRequest) (models.SimulateResponse, error) {
+	return a.algoClient.SimulateTransaction(request).Do(ctx)
+}
+
+func (a *algodChain) SendRawTransaction(ctx context.Context, signedGroup []byte) (string, error) {
+	return a.algoClient.SendRawTransaction(signedGroup).Do(ctx)
+}
+
+func (a *algodChain) PendingTransactionInformation(ctx context.Context, txid string) (models.PendingTransactionInfoResponse, error) {
+	info, _, err := a.algoClient.PendingTransactionInformation(txid).Do(ctx)
+	return info, err
+}
+
+// WaitForConfirmation waits for the transaction to be confirmed, for up to waitRounds rounds, returning its
+// confirmed info.
+func WaitForConfirmation(ctx context.Context, chain interface {
+	StatusReader
+	TxnSubmitter
+}, txid string, waitRounds uint64) (models.PendingTransactionInfoResponse, error) {
+	status, err := chain.Status(ctx)
+	if err != nil {
+		return models.PendingTransactionInfoResponse{}, err
+	}
+	var (
+		startRound   = status.LastRound + 1
+		currentRound = startRound
+	)
+	for currentRound < startRound+waitRounds {
+		info, err := chain.PendingTransactionInformation(ctx, txid)
+		if err == nil {
+			if info.ConfirmedRound > 0 {
+				return info, nil
+			}
+			if info.PoolError != "" {
+				return info, fmt.Errorf("transaction:%s rejected: %s", txid, info.PoolError)
+			}
+		}
+		if _, err = chain.StatusAfterBlock(ctx, currentRound); err != nil {
+			return models.PendingTransactionInfoResponse{}, err
+		}
+		currentRound++
+	}
+	return models.PendingTransactionInfoResponse{}, fmt.Errorf("txid:%s after %d rounds: %w", txid, waitRounds, ErrNotConfirmed)
+}
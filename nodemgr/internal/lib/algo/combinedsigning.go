@@ -0,0 +1,67 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// NewCombinedSigner returns a signer for the accounts of all the signers - an account is signed for by the first
+// signer having it.
+func NewCombinedSigner(signers ...MultipleWalletSigner) MultipleWalletSigner {
+	return &combinedSigner{signers: signers}
+}
+
+type combinedSigner struct {
+	signers []MultipleWalletSigner
+}
+
+func (c *combinedSigner) HasAccount(publicAddress string) bool {
+	return c.signerFor(publicAddress) != nil
+}
+
+func (c *combinedSigner) signerFor(publicAddress string) MultipleWalletSigner {
+	for _, signer := range c.signers {
+		if signer.HasAccount(publicAddress) {
+			return signer
+		}
+	}
+	return nil
+}
+
+func (c *combinedSigner) FindFirstSigner(addresses []string) (string, error) {
+	for _, signer := range c.signers {
+		if address, err := signer.FindFirstSigner(addresses); err == nil {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("no signer found for any of the addresses")
+}
+
+func (c *combinedSigner) SignWithAccount(ctx context.Context, tx types.Transaction, publicAddress string) (string, []byte, error) {
+	signer := c.signerFor(publicAddress)
+	if signer == nil {
+		return "", nil, fmt.Errorf("key not found for address %s", publicAddress)
+	}
+	return signer.SignWithAccount(ctx, tx, publicAddress)
+}
+
+// Close closes all the signers holding secrets
+func (c *combinedSigner) Close() error {
+	var errs []error
+	for _, signer := range c.signers {
+		errs = append(errs, CloseSigner(signer))
+	}
+	return errors.Join(errs...)
+}
+
+// CloseSigner zeroes any secrets (keys) held by signer, if it holds any.  The signer can't be used afterward.
+func CloseSigner(signer MultipleWalletSigner) error {
+	if closer, ok := signer.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
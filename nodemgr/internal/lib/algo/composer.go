@@ -0,0 +1,428 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+const (
+	// protocol limits on the references of a single app call
+	maxTxnAccounts       = 4
+	maxTxnTotalResources = 8
+	// max inner transactions in a group - used to size the fee while simulating so fees are never the failure
+	maxGroupInnerTxns = 256
+)
+
+// Composer builds a transaction group much like transaction.AtomicTransactionComposer, but rather than the caller
+// having to know every box, app, asset and account the contracts will touch (and the fees needed to cover inner
+// transactions), it simulates the group first and fills all of that in from what the group actually used.
+//
+// Method calls shouldn't set fees or (non-argument) references themselves, other than for references which need to
+// be in a particular transaction.  Extra 'gas' (no-op) method calls can be added to a group to give it more room for
+// references.
+type Composer struct {
+	entries []composerEntry
+}
+
+type composerEntry struct {
+	// only one of these is set
+	method *transaction.AddMethodCallParams
+	txn    *transaction.TransactionWithSigner
+	// index of the transaction within the group (set when built)
+	groupIndex int
+}
+
+// AddTransaction adds a non method call transaction to the group - it's added as-is.
+func (c *Composer) AddTransaction(txn transaction.TransactionWithSigner) {
+	c.entries = append(c.entries, composerEntry{txn: &txn})
+}
+
+// AddMethodCall adds an app method call to the group - its references and fee are filled in by Prepare.
+func (c *Composer) AddMethodCall(params transaction.AddMethodCallParams) {
+	c.entries = append(c.entries, composerEntry{method: &params})
+}
+
+// Prepare simulates the group, returning the group ready to execute, with the references and fees of every method
+// call populated based on the simulation.  Returns an error if the simulation fails.
+func (c *Composer) Prepare(ctx context.Context, chain Chain) (*Group, error) {
+	if len(c.entries) == 0 {
+		return nil, errors.New("no transactions to compose")
+	}
+	lastMethod := -1
+	for i, entry := range c.entries {
+		if entry.method != nil {
+			lastMethod = i
+		}
+	}
+	var lastMethodFee uint64
+	if lastMethod != -1 {
+		fee, err := c.simulationFee(ctx, chain, lastMethod)
+		if err != nil {
+			return nil, err
+		}
+		lastMethodFee = fee
+	}
+	// simulate w/ empty signatures - nothing is signed until we know exactly what's being sent.  The last method
+	// call pays enough fees to cover the whole group (as long as its sender can afford it).
+	simGroup, err := c.build(true, func(i int, params *transaction.AddMethodCallParams) {
+		params.SuggestedParams.FlatFee = true
+		params.SuggestedParams.Fee = types.MicroAlgos(minFee(params.SuggestedParams))
+		if i == lastMethod {
+			params.SuggestedParams.Fee = types.MicroAlgos(lastMethodFee)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	simResult, err := simGroup.Simulate(ctx, chain, models.SimulateRequest{
+		AllowEmptySignatures:  true,
+		AllowUnnamedResources: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("simulate failed: %w", err)
+	}
+	groupResult := simResult.SimulateResponse.TxnGroups[0]
+	if groupResult.FailureMessage != "" {
+		return nil, fmt.Errorf("simulate failed at txn:%v, %s", groupResult.FailedAt, groupResult.FailureMessage)
+	}
+	builtGroup, err := simGroup.Transactions()
+	if err != nil {
+		return nil, err
+	}
+
+	// per-method-call references to add, from what wasn't explicitly referenced in the simulated group
+	slots := map[int]*refSlots{}
+	var slotOrder []*refSlots
+	for i, entry := range c.entries {
+		if entry.method == nil {
+			continue
+		}
+		slot := newRefSlots(builtGroup[entry.groupIndex].Txn)
+		slots[i] = slot
+		slotOrder = append(slotOrder, slot)
+	}
+	for i, entry := range c.entries {
+		if entry.method == nil {
+			continue
+		}
+		// resources which must be referenced by this specific transaction
+		txnResources := groupResult.TxnResults[entry.groupIndex].UnnamedResourcesAccessed
+		if err = populateResources(txnResources, []*refSlots{slots[i]}); err != nil {
+			return nil, fmt.Errorf("method call %d: %w", i, err)
+		}
+	}
+	// resources which can be referenced by any transaction in the group
+	if err = populateResources(groupResult.UnnamedResourcesAccessed, slotOrder); err != nil {
+		return nil, err
+	}
+
+	return c.build(false, func(i int, params *transaction.AddMethodCallParams) {
+		slot := slots[i]
+		params.ForeignApps = append(slices.Clone(params.ForeignApps), slot.addedApps...)
+		params.ForeignAssets = append(slices.Clone(params.ForeignAssets), slot.addedAssets...)
+		params.ForeignAccounts = append(slices.Clone(params.ForeignAccounts), slot.addedAccounts...)
+		params.BoxReferences = append(slices.Clone(params.BoxReferences), slot.addedBoxes...)
+		// each method call covers the inner transactions it issues
+		fee := minFee(params.SuggestedParams)
+		fee += innerFeeShortfall(groupResult.TxnResults[c.entries[i].groupIndex].TxnResult.InnerTxns, fee)
+		params.SuggestedParams.FlatFee = true
+		params.SuggestedParams.Fee = types.MicroAlgos(fee)
+	})
+}
+
+// simulationFee returns the fee the method call at entry idx pays while simulating - enough to cover the whole
+// group (w/ the max number of inner transactions), but no more than what its sender can spend after the rest of
+// what it sends in the group, so simulating doesn't fail just because the sender couldn't afford the worst case.
+// The fees actually paid are sized from the simulation.
+func (c *Composer) simulationFee(ctx context.Context, accounts AccountReader, idx int) (uint64, error) {
+	params := c.entries[idx].method
+	var (
+		sender     = params.Sender.String()
+		fee        = minFee(params.SuggestedParams)
+		groupFee   = fee * (uint64(len(c.entries)) + maxGroupInnerTxns)
+		otherSpend uint64
+	)
+	spent := func(txn types.Transaction) {
+		if txn.Sender.String() != sender {
+			return
+		}
+		otherSpend += max(uint64(txn.Fee), fee)
+		if txn.Type == types.PaymentTx {
+			otherSpend += uint64(txn.Amount)
+		}
+	}
+	for i, entry := range c.entries {
+		if entry.txn != nil {
+			spent(entry.txn.Txn)
+			continue
+		}
+		for _, arg := range entry.method.MethodArgs {
+			if txnArg, ok := arg.(transaction.TransactionWithSigner); ok {
+				spent(txnArg.Txn)
+			}
+		}
+		if i != idx && entry.method.Sender.String() == sender {
+			otherSpend += fee
+		}
+	}
+	account, err := GetBareAccount(ctx, accounts, sender)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch account:%s to size simulation fee: %w", sender, err)
+	}
+	var spendable uint64
+	if account.Amount > account.MinBalance+otherSpend {
+		spendable = account.Amount - account.MinBalance - otherSpend
+	}
+	return max(fee, min(groupFee, spendable)), nil
+}
+
+// Simulate simulates the group as-is (with empty signatures, so it needn't be signable), returning the result.
+// This is how read-only methods are called.
+func (c *Composer) Simulate(ctx context.Context, submitter TxnSubmitter, request models.SimulateRequest) (transaction.SimulateResult, error) {
+	group, err := c.build(true, func(int, *transaction.AddMethodCallParams) {})
+	if err != nil {
+		return transaction.SimulateResult{}, err
+	}
+	request.AllowEmptySignatures = true
+	return group.Simulate(ctx, submitter, request)
+}
+
+// build creates the group from our entries, calling update to modify each method call first.  If forSimulate is
+// set, all signers are replaced with empty signers.
+func (c *Composer) build(forSimulate bool, update func(i int, params *transaction.AddMethodCallParams)) (*Group, error) {
+	atc := &transaction.AtomicTransactionComposer{}
+	methods := map[int]*abi.Method{}
+	for i := range c.entries {
+		entry := &c.entries[i]
+		if entry.txn != nil {
+			txn := *entry.txn
+			if forSimulate {
+				txn.Signer = transaction.EmptyTransactionSigner{}
+			}
+			if err := atc.AddTransaction(txn); err != nil {
+				return nil, err
+			}
+			entry.groupIndex = atc.Count() - 1
+			continue
+		}
+		params := *entry.method
+		params.MethodArgs = slices.Clone(params.MethodArgs)
+		if forSimulate {
+			params.Signer = transaction.EmptyTransactionSigner{}
+			for argIdx, arg := range params.MethodArgs {
+				if txnArg, ok := arg.(transaction.TransactionWithSigner); ok {
+					txnArg.Signer = transaction.EmptyTransactionSigner{}
+					params.MethodArgs[argIdx] = txnArg
+				}
+			}
+		}
+		update(i, &params)
+		if err := atc.AddMethodCall(params); err != nil {
+			return nil, fmt.Errorf("error adding method call %s: %w", params.Method.Name, err)
+		}
+		entry.groupIndex = atc.Count() - 1
+		methods[entry.groupIndex] = &params.Method
+	}
+	group := &Group{atc: atc, methods: make([]*abi.Method, atc.Count())}
+	for groupIndex, method := range methods {
+		group.methods[groupIndex] = method
+	}
+	return group, nil
+}
+
+func minFee(params types.SuggestedParams) uint64 {
+	return max(params.MinFee, transaction.MinTxnFee)
+}
+
+// innerFeeShortfall returns the fees the outer transaction must cover for the inner transactions (recursively) which
+// didn't pay their own way.
+func innerFeeShortfall(innerTxns []models.PendingTransactionResponse, minFee uint64) uint64 {
+	var shortfall uint64
+	for _, inner := range innerTxns {
+		if fee := uint64(inner.Transaction.Txn.Fee); fee < minFee {
+			shortfall += minFee - fee
+		}
+		shortfall += innerFeeShortfall(inner.InnerTxns, minFee)
+	}
+	return shortfall
+}
+
+// refSlots tracks the references of a single app call, and those added to it
+type refSlots struct {
+	appID    uint64
+	sender   string
+	accounts []string
+	apps     []uint64
+	assets   []uint64
+	numBoxes int
+
+	addedAccounts []string
+	addedApps     []uint64
+	addedAssets   []uint64
+	addedBoxes    []types.AppBoxReference
+}
+
+func newRefSlots(txn types.Transaction) *refSlots {
+	slot := &refSlots{
+		appID:    uint64(txn.ApplicationID),
+		sender:   txn.Sender.String(),
+		numBoxes: len(txn.BoxReferences),
+	}
+	for _, account := range txn.Accounts {
+		slot.accounts = append(slot.accounts, account.String())
+	}
+	for _, app := range txn.ForeignApps {
+		slot.apps = append(slot.apps, uint64(app))
+	}
+	for _, asset := range txn.ForeignAssets {
+		slot.assets = append(slot.assets, uint64(asset))
+	}
+	return slot
+}
+
+func (s *refSlots) free() int {
+	return maxTxnTotalResources - (len(s.accounts) + len(s.apps) + len(s.assets) + s.numBoxes)
+}
+
+func (s *refSlots) freeAccounts() int {
+	return min(s.free(), maxTxnAccounts-len(s.accounts))
+}
+
+func (s *refSlots) hasApp(appID uint64) bool {
+	return appID == s.appID || slices.Contains(s.apps, appID)
+}
+
+func (s *refSlots) hasAccount(account string) bool {
+	return account == s.sender || slices.Contains(s.accounts, account)
+}
+
+func (s *refSlots) addApp(appID uint64) {
+	if !s.hasApp(appID) {
+		s.apps = append(s.apps, appID)
+		s.addedApps = append(s.addedApps, appID)
+	}
+}
+
+func (s *refSlots) addAsset(assetID uint64) {
+	if !slices.Contains(s.assets, assetID) {
+		s.assets = append(s.assets, assetID)
+		s.addedAssets = append(s.addedAssets, assetID)
+	}
+}
+
+func (s *refSlots) addAccount(account string) {
+	if !s.hasAccount(account) {
+		s.accounts = append(s.accounts, account)
+		s.addedAccounts = append(s.addedAccounts, account)
+	}
+}
+
+func (s *refSlots) addBox(appID uint64, name []byte) {
+	s.numBoxes++
+	s.addedBoxes = append(s.addedBoxes, types.AppBoxReference{AppID: appID, Name: name})
+}
+
+// findSlot returns the first slot matching fn, or nil if none
+func findSlot(slots []*refSlots, fn func(slot *refSlots) bool) *refSlots {
+	for _, slot := range slots {
+		if fn(slot) {
+			return slot
+		}
+	}
+	return nil
+}
+
+// populateResources adds each of the accessed resources to one of slots, returning an error if they can't all fit.
+// Cross-product references (local state, asset holdings) go first as they need their two parts in the same
+// transaction.
+func populateResources(resources models.SimulateUnnamedResourcesAccessed, slots []*refSlots) error {
+	noRoom := func(what string) error {
+		return fmt.Errorf("no room in transaction group for reference to %s - more (gas) app calls are needed", what)
+	}
+	// place a pair of account + app/asset references in the same transaction
+	placePair := func(account string, hasOther func(*refSlots) bool, addOther func(*refSlots)) bool {
+		slot := findSlot(slots, func(slot *refSlots) bool {
+			return (slot.hasAccount(account) && (hasOther(slot) || slot.free() >= 1)) ||
+				(hasOther(slot) && slot.freeAccounts() >= 1)
+		})
+		if slot == nil {
+			slot = findSlot(slots, func(slot *refSlots) bool { return slot.free() >= 2 && slot.freeAccounts() >= 1 })
+		}
+		if slot == nil {
+			return false
+		}
+		slot.addAccount(account)
+		addOther(slot)
+		return true
+	}
+	for _, local := range resources.AppLocals {
+		if !placePair(local.Account, func(slot *refSlots) bool { return slot.hasApp(local.App) },
+			func(slot *refSlots) { slot.addApp(local.App) }) {
+			return noRoom(fmt.Sprintf("local state of app:%d for account:%s", local.App, local.Account))
+		}
+	}
+	for _, holding := range resources.AssetHoldings {
+		if !placePair(holding.Account, func(slot *refSlots) bool { return slices.Contains(slot.assets, holding.Asset) },
+			func(slot *refSlots) { slot.addAsset(holding.Asset) }) {
+			return noRoom(fmt.Sprintf("asset:%d holding for account:%s", holding.Asset, holding.Account))
+		}
+	}
+	for _, account := range resources.Accounts {
+		if findSlot(slots, func(slot *refSlots) bool { return slot.hasAccount(account) }) != nil {
+			continue
+		}
+		slot := findSlot(slots, func(slot *refSlots) bool { return slot.freeAccounts() >= 1 })
+		if slot == nil {
+			return noRoom("account:" + account)
+		}
+		slot.addAccount(account)
+	}
+	for _, box := range resources.Boxes {
+		// box refs must be in a transaction referencing (or calling) the box's app
+		slot := findSlot(slots, func(slot *refSlots) bool { return slot.hasApp(box.App) && slot.free() >= 1 })
+		if slot == nil {
+			slot = findSlot(slots, func(slot *refSlots) bool { return slot.free() >= 2 })
+		}
+		if slot == nil {
+			return noRoom(fmt.Sprintf("box:%q of app:%d", box.Name, box.App))
+		}
+		slot.addApp(box.App)
+		slot.addBox(box.App, box.Name)
+	}
+	for _, app := range resources.Apps {
+		if findSlot(slots, func(slot *refSlots) bool { return slot.hasApp(app) }) != nil {
+			continue
+		}
+		slot := findSlot(slots, func(slot *refSlots) bool { return slot.free() >= 1 })
+		if slot == nil {
+			return noRoom(fmt.Sprintf("app:%d", app))
+		}
+		slot.addApp(app)
+	}
+	for _, asset := range resources.Assets {
+		if findSlot(slots, func(slot *refSlots) bool { return slices.Contains(slot.assets, asset) }) != nil {
+			continue
+		}
+		slot := findSlot(slots, func(slot *refSlots) bool { return slot.free() >= 1 })
+		if slot == nil {
+			return noRoom(fmt.Sprintf("asset:%d", asset))
+		}
+		slot.addAsset(asset)
+	}
+	// extra box references are just for additional box i/o quota
+	for range resources.ExtraBoxRefs {
+		slot := findSlot(slots, func(slot *refSlots) bool { return slot.free() >= 1 })
+		if slot == nil {
+			return noRoom("additional box i/o")
+		}
+		slot.addBox(0, nil)
+	}
+	return nil
+}
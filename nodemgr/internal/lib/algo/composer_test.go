@@ -0,0 +1,70 @@
+package algo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+func TestComposerSimulationFee(t *testing.T) {
+	const groupFee = 1000 * (2 + maxGroupInnerTxns)
+	tests := []struct {
+		name    string
+		balance uint64
+		payment uint64 // sent by the same sender in the group
+		wantFee uint64
+	}{
+		{name: "funded sender covers the whole group", balance: 10e6, wantFee: groupFee},
+		{name: "low balance is capped at what's spendable", balance: 150_000, wantFee: 150_000 - 100_000 - 1000}, // less the fee of its other method call
+		{name: "payments in the group aren't spendable", balance: 150_000, payment: 20_000, wantFee: 150_000 - 100_000 - 20_000 - 1000},
+		{name: "never less than the min fee", balance: 100_000, wantFee: 1000},
+	}
+	method, err := abi.MethodFromSignature("gas()void")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				ctx     = context.Background()
+				ledger  = NewFakeLedger()
+				sender  = crypto.GenerateAccount().Address
+				simFees []types.MicroAlgos
+			)
+			ledger.SetAccount(models.Account{Address: sender.String(), Amount: tt.balance, MinBalance: 100_000})
+			ledger.SimulateFunc = func(request models.SimulateRequest) (models.SimulateResponse, error) {
+				response := models.SimulateResponse{TxnGroups: []models.SimulateTransactionGroupResult{{}}}
+				for _, txn := range request.TxnGroups[0].Txns {
+					simFees = append(simFees, txn.Txn.Fee)
+					response.TxnGroups[0].TxnResults = append(response.TxnGroups[0].TxnResults, models.SimulateTransactionResult{})
+				}
+				return response, nil
+			}
+			params, _ := ledger.SuggestedParams(ctx)
+
+			comp := Composer{}
+			if tt.payment != 0 {
+				payment, err := transaction.MakePaymentTxn(sender.String(), sender.String(), tt.payment, nil, "", params)
+				if err != nil {
+					t.Fatal(err)
+				}
+				comp.AddTransaction(transaction.TransactionWithSigner{Txn: payment, Signer: transaction.EmptyTransactionSigner{}})
+			} else {
+				comp.AddMethodCall(transaction.AddMethodCallParams{AppID: 1, Method: method, Sender: sender, SuggestedParams: params, Signer: transaction.EmptyTransactionSigner{}})
+			}
+			comp.AddMethodCall(transaction.AddMethodCallParams{AppID: 1, Method: method, Sender: sender, SuggestedParams: params, Signer: transaction.EmptyTransactionSigner{}})
+
+			if _, err = comp.Prepare(ctx, ledger); err != nil {
+				t.Fatalf("Prepare: %v", err)
+			}
+			if got := uint64(simFees[len(simFees)-1]); got != tt.wantFee {
+				t.Errorf("simulated last method call with fee:%d, want %d", got, tt.wantFee)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package algo
+
+import (
+	"context"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// NewDryRunSigner returns a signer which never signs anything - 'signed' transactions have empty signatures, so
+// they can only be simulated (w/ AllowEmptySignatures), never sent.  It claims to be able to sign for every
+// account, so code paths normally requiring local keys can be exercised without them.
+func NewDryRunSigner(signer MultipleWalletSigner) MultipleWalletSigner {
+	return &dryRunSigner{signer: signer}
+}
+
+type dryRunSigner struct {
+	// signer is only used to pick local accounts (if present) when choosing between addresses
+	signer MultipleWalletSigner
+}
+
+func (d *dryRunSigner) Close() error {
+	return CloseSigner(d.signer)
+}
+
+func (d *dryRunSigner) HasAccount(string) bool {
+	return true
+}
+
+func (d *dryRunSigner) FindFirstSigner(addresses []string) (string, error) {
+	address, err := d.signer.FindFirstSigner(addresses)
+	if err != nil && len(addresses) > 0 {
+		return addresses[0], nil
+	}
+	return address, err
+}
+
+func (d *dryRunSigner) SignWithAccount(_ context.Context, tx types.Transaction, _ string) (string, []byte, error) {
+	return crypto.GetTxID(tx), msgpack.Encode(types.SignedTxn{Txn: tx}), nil
+}
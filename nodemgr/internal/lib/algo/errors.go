@@ -4,4 +4,6 @@ import "errors"
 
 var (
 	ErrStateKeyNotFound = errors.New("key in global state not found")
+	// ErrNotConfirmed is returned when a transaction isn't confirmed within the rounds waited for it
+	ErrNotConfirmed = errors.New("transaction not confirmed")
 )
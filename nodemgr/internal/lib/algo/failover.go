@@ -0,0 +1,283 @@
+package algo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+const (
+	// how often every endpoint is checked for sync status / last round
+	endpointHealthInterval = 5 * time.Second
+	// an endpoint this many rounds (or fewer) behind the furthest along endpoint is considered equally current, so
+	// the order of endpoints is preferred.
+	endpointMaxRoundLag = 2
+	algodTokenHeader    = "X-Algo-API-Token"
+)
+
+var (
+	promEndpointHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "reti",
+		Name:      "algod_endpoint_healthy",
+		Help:      "1 if the algod endpoint is reachable and synced",
+	}, []string{"endpoint"})
+	promEndpointLastRound = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Subsystem: "reti",
+		Name:      "algod_endpoint_last_round",
+	}, []string{"endpoint"})
+)
+
+// pinnedPaths are api paths which must always go to the primary (local) node - participation keys only exist on the
+// node that generated them.
+var pinnedPaths = []string{"/v2/participation"}
+
+// algodEndpoint is a single algod endpoint along w/ its last known health
+type algodEndpoint struct {
+	name string
+	url  *url.URL
+	// token returns the current api token of the endpoint (which may be rotated)
+	token   func() string
+	headers map[string]string
+
+	sync.RWMutex
+	healthy   bool
+	lastRound uint64
+	lastErr   error
+}
+
+func (e *algodEndpoint) health() (bool, uint64) {
+	e.RLock()
+	defer e.RUnlock()
+	return e.healthy, e.lastRound
+}
+
+func (e *algodEndpoint) markUnhealthy(err error) {
+	e.Lock()
+	e.healthy = false
+	e.lastErr = err
+	e.Unlock()
+	promEndpointHealthy.WithLabelValues(e.name).Set(0)
+}
+
+// failoverTransport is used as the http transport of the algod client when multiple endpoints are configured.
+// The algod client itself is always created against the primary endpoint - each request is rewritten to target the
+// healthiest endpoint (using that endpoint's token and headers) unless it's a participation key call, which stays
+// pinned to the primary.
+type failoverTransport struct {
+	log       *slog.Logger
+	base      http.RoundTripper
+	endpoints []*algodEndpoint // [0] is primary
+}
+
+func newFailoverTransport(log *slog.Logger, base http.RoundTripper, endpoints []*algodEndpoint) *failoverTransport {
+	return &failoverTransport{log: log, base: base, endpoints: endpoints}
+}
+
+// monitor health checks all endpoints periodically, for the lifetime of the process
+func (f *failoverTransport) monitor() {
+	ticker := time.NewTicker(endpointHealthInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		f.checkAll()
+	}
+}
+
+func (f *failoverTransport) checkAll() {
+	var wg sync.WaitGroup
+	for _, endpoint := range f.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			f.checkEndpoint(endpoint)
+		}()
+	}
+	wg.Wait()
+}
+
+func (f *failoverTransport) checkEndpoint(endpoint *algodEndpoint) {
+	ctx, cancel := context.WithTimeout(context.Background(), endpointHealthInterval)
+	defer cancel()
+
+	var status models.NodeStatus
+	err := func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.url.JoinPath("/v2/status").String(), nil)
+		if err != nil {
+			return err
+		}
+		f.setAuth(req, endpoint)
+		resp, err := f.base.RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("status returned http status %d", resp.StatusCode)
+		}
+		return json.NewDecoder(resp.Body).Decode(&status)
+	}()
+
+	endpoint.Lock()
+	wasHealthy := endpoint.healthy
+	endpoint.lastErr = err
+	if err == nil {
+		// still catching up is considered unhealthy
+		endpoint.lastRound = status.LastRound
+		endpoint.healthy = status.CatchupTime == 0
+	} else {
+		endpoint.healthy = false
+	}
+	isHealthy := endpoint.healthy
+	endpoint.Unlock()
+
+	if isHealthy {
+		promEndpointHealthy.WithLabelValues(endpoint.name).Set(1)
+	} else {
+		promEndpointHealthy.WithLabelValues(endpoint.name).Set(0)
+	}
+	promEndpointLastRound.WithLabelValues(endpoint.name).Set(float64(status.LastRound))
+	if wasHealthy != isHealthy {
+		if isHealthy {
+			misc.Infof(f.log, "algod endpoint %s is healthy, at round:%d", endpoint.name, status.LastRound)
+		} else if err != nil {
+			misc.Warnf(f.log, "algod endpoint %s is unhealthy, err:%v", endpoint.name, err)
+		} else {
+			misc.Warnf(f.log, "algod endpoint %s is unhealthy, catching up, at round:%d", endpoint.name, status.LastRound)
+		}
+	}
+}
+
+// candidates returns the endpoints to try for a request, in order of preference.  The healthiest endpoints come
+// first - healthy and within endpointMaxRoundLag of the furthest along endpoint, in configured order - followed by
+// the remaining endpoints in configured order as a last resort.
+func (f *failoverTransport) candidates() []*algodEndpoint {
+	var maxRound uint64
+	for _, endpoint := range f.endpoints {
+		if healthy, lastRound := endpoint.health(); healthy {
+			maxRound = max(maxRound, lastRound)
+		}
+	}
+	var preferred, others []*algodEndpoint
+	for _, endpoint := range f.endpoints {
+		if healthy, lastRound := endpoint.health(); healthy && lastRound+endpointMaxRoundLag >= maxRound {
+			preferred = append(preferred, endpoint)
+		} else {
+			others = append(others, endpoint)
+		}
+	}
+	return append(preferred, others...)
+}
+
+func (f *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	primary := f.endpoints[0]
+	apiPath := strings.TrimPrefix(req.URL.Path, strings.TrimRight(primary.url.Path, "/"))
+	for _, pinnedPath := range pinnedPaths {
+		if strings.HasPrefix(apiPath, pinnedPath) {
+			// always the primary - but w/ its current token, as it may have been rotated
+			pinnedReq := req.Clone(req.Context())
+			f.setAuth(pinnedReq, primary)
+			return f.base.RoundTrip(pinnedReq)
+		}
+	}
+
+	var (
+		lastErr    error
+		candidates = f.candidates()
+	)
+	for i, endpoint := range candidates {
+		if i > 0 {
+			// retrying - only possible if we can re-send the body
+			if req.Body != nil && req.GetBody == nil {
+				break
+			}
+			misc.Debugf(f.log, "retrying %s against algod endpoint %s", apiPath, endpoint.name)
+		}
+		resp, err := f.base.RoundTrip(f.rewrite(req, endpoint, apiPath))
+		if err == nil && !isUnavailableStatus(resp.StatusCode) {
+			return resp, nil
+		}
+		if err == nil {
+			// keep the response in case it's the last we get
+			err = fmt.Errorf("algod endpoint %s returned http status %d", endpoint.name, resp.StatusCode)
+			if i == len(candidates)-1 {
+				return resp, nil
+			}
+			resp.Body.Close()
+		}
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, err
+		}
+		endpoint.markUnhealthy(err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// rewrite returns a copy of req targeting the specified endpoint (url, token and headers)
+func (f *failoverTransport) rewrite(req *http.Request, endpoint *algodEndpoint, apiPath string) *http.Request {
+	newReq := req.Clone(req.Context())
+	if req.Body != nil && req.GetBody != nil {
+		newReq.Body, _ = req.GetBody()
+	}
+	newReq.URL.Scheme = endpoint.url.Scheme
+	newReq.URL.Host = endpoint.url.Host
+	newReq.URL.Path = strings.TrimRight(endpoint.url.Path, "/") + apiPath
+	newReq.Host = ""
+	if endpoint != f.endpoints[0] {
+		// strip the primary's headers (added by the client) as they're replaced w/ this endpoint's
+		for key := range f.endpoints[0].headers {
+			newReq.Header.Del(key)
+		}
+	}
+	f.setAuth(newReq, endpoint)
+	return newReq
+}
+
+func (f *failoverTransport) setAuth(req *http.Request, endpoint *algodEndpoint) {
+	req.Header.Set(algodTokenHeader, endpoint.token())
+	for key, value := range endpoint.headers {
+		req.Header.Set(key, value)
+	}
+}
+
+// secretToken returns a func returning the current value of the token secret - or token if it isn't (or is no
+// longer) defined.
+func secretToken(secretName, token string) func() string {
+	return func() string {
+		if secretName != "" {
+			if value := misc.GetSecret(secretName); value != "" {
+				return value
+			}
+		}
+		return token
+	}
+}
+
+// tokenTransport sets the api token of every request from its (current) secret value, so it can be rotated
+// without a restart
+type tokenTransport struct {
+	base  http.RoundTripper
+	token func() string
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	newReq := req.Clone(req.Context())
+	newReq.Header.Set(algodTokenHeader, t.token())
+	return t.base.RoundTrip(newReq)
+}
+
+func isUnavailableStatus(statusCode int) bool {
+	return statusCode == http.StatusBadGateway || statusCode == http.StatusServiceUnavailable || statusCode == http.StatusGatewayTimeout
+}
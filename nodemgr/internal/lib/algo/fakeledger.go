@@ -0,0 +1,373 @@
+package algo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// ErrFakeNotFound is returned by FakeLedger for accounts, apps, boxes, etc. it doesn't have
+var ErrFakeNotFound = errors.New("not found in fake ledger")
+
+// FakeLedger is an in-memory Chain for exercising the daemon (and réti client) without a live node.  Its state is
+// whatever the caller sets - it doesn't evaluate contracts, so simulations return whatever SimulateFunc returns.
+//
+// Rounds only advance via AdvanceRound, or when a transaction group is sent - the group is confirmed in the round
+// it creates.  Payments in sent groups are applied to the account balances, nothing else is.
+type FakeLedger struct {
+	// SimulateFunc, if set, is called for every simulate request.  By default every transaction in the group
+	// succeeds without any logs (so non-void method calls fail to decode their return value).
+	SimulateFunc func(request models.SimulateRequest) (models.SimulateResponse, error)
+	// BlockTime is the time between the (fake) timestamps of consecutive blocks - set before using the ledger
+	BlockTime time.Duration
+
+	sync.RWMutex
+	round     uint64
+	startTime time.Time
+	// advanced is closed (and replaced) whenever the round advances
+	advanced  chan struct{}
+	params    types.SuggestedParams
+	version   models.Version
+	accounts  map[string]models.Account
+	apps      map[uint64]models.Application
+	boxes     map[uint64]map[string][]byte
+	partKeys  []ParticipationKey
+	nextKeyID int
+	submitted []types.SignedTxn
+	confirmed map[string]models.PendingTransactionInfoResponse // keyed by txid
+}
+
+// NewFakeLedger returns an empty FakeLedger at round 1
+func NewFakeLedger() *FakeLedger {
+	return &FakeLedger{
+		BlockTime: 2800 * time.Millisecond,
+		round:     1,
+		startTime: time.Now(),
+		advanced:  make(chan struct{}),
+		params: types.SuggestedParams{
+			Fee:             types.MicroAlgos(1000),
+			GenesisID:       "fakeledger-v1",
+			GenesisHash:     make([]byte, 32),
+			FirstRoundValid: 1,
+			LastRoundValid:  1001,
+			MinFee:          1000,
+			FlatFee:         true,
+		},
+		accounts:  map[string]models.Account{},
+		apps:      map[uint64]models.Application{},
+		boxes:     map[uint64]map[string][]byte{},
+		confirmed: map[string]models.PendingTransactionInfoResponse{},
+	}
+}
+
+// SetAccount adds (or replaces) an account
+func (f *FakeLedger) SetAccount(account models.Account) {
+	f.Lock()
+	defer f.Unlock()
+	f.accounts[account.Address] = account
+}
+
+// SetApplication adds (or replaces) an application
+func (f *FakeLedger) SetApplication(app models.Application) {
+	f.Lock()
+	defer f.Unlock()
+	f.apps[app.Id] = app
+}
+
+// SetBox adds (or replaces) a box of an application
+func (f *FakeLedger) SetBox(appID uint64, name []byte, value []byte) {
+	f.Lock()
+	defer f.Unlock()
+	if f.boxes[appID] == nil {
+		f.boxes[appID] = map[string][]byte{}
+	}
+	f.boxes[appID][string(name)] = slices.Clone(value)
+}
+
+// SetVersion sets the node version returned by Versions
+func (f *FakeLedger) SetVersion(version models.Version) {
+	f.Lock()
+	defer f.Unlock()
+	f.version = version
+}
+
+// Round returns the current (last) round
+func (f *FakeLedger) Round() uint64 {
+	f.RLock()
+	defer f.RUnlock()
+	return f.round
+}
+
+// AdvanceRound advances the ledger numRounds rounds, waking anything waiting in StatusAfterBlock
+func (f *FakeLedger) AdvanceRound(numRounds uint64) {
+	f.Lock()
+	defer f.Unlock()
+	f.advance(numRounds)
+}
+
+// advance must be called with the lock held
+func (f *FakeLedger) advance(numRounds uint64) {
+	if numRounds == 0 {
+		return
+	}
+	f.round += numRounds
+	close(f.advanced)
+	f.advanced = make(chan struct{})
+}
+
+// Submitted returns every signed transaction sent to the ledger, in the order sent
+func (f *FakeLedger) Submitted() []types.SignedTxn {
+	f.RLock()
+	defer f.RUnlock()
+	return slices.Clone(f.submitted)
+}
+
+func (f *FakeLedger) AccountInformation(_ context.Context, address string) (models.Account, error) {
+	f.RLock()
+	defer f.RUnlock()
+	account, found := f.accounts[address]
+	if !found {
+		// like algod, unknown accounts are just empty
+		return models.Account{Address: address, Round: f.round, Status: "Offline"}, nil
+	}
+	account.Round = f.round
+	return account, nil
+}
+
+func (f *FakeLedger) BareAccountInformation(ctx context.Context, address string) (models.Account, error) {
+	account, err := f.AccountInformation(ctx, address)
+	account.Assets, account.CreatedAssets, account.AppsLocalState, account.CreatedApps = nil, nil, nil, nil
+	return account, err
+}
+
+func (f *FakeLedger) Application(_ context.Context, appID uint64) (models.Application, error) {
+	f.RLock()
+	defer f.RUnlock()
+	app, found := f.apps[appID]
+	if !found {
+		return models.Application{}, fmt.Errorf("app id:%d: %w", appID, ErrFakeNotFound)
+	}
+	return app, nil
+}
+
+func (f *FakeLedger) ApplicationBox(_ context.Context, appID uint64, name []byte) (models.Box, error) {
+	f.RLock()
+	defer f.RUnlock()
+	value, found := f.boxes[appID][string(name)]
+	if !found {
+		return models.Box{}, fmt.Errorf("box:%q of app id:%d: %w", name, appID, ErrFakeNotFound)
+	}
+	return models.Box{Name: slices.Clone(name), Value: slices.Clone(value), Round: f.round}, nil
+}
+
+func (f *FakeLedger) ParticipationKeys(_ context.Context) ([]ParticipationKey, error) {
+	f.RLock()
+	defer f.RUnlock()
+	return slices.Clone(f.partKeys), nil
+}
+
+// GenerateParticipationKeys adds the key right away (the node would take minutes to generate it)
+func (f *FakeLedger) GenerateParticipationKeys(_ context.Context, account string, params GenerateParticipationKeysParams) error {
+	if params.Last <= params.First {
+		return fmt.Errorf("invalid key range of %d - %d", params.First, params.Last)
+	}
+	dilution := uint64(math.Sqrt(float64(params.Last - params.First)))
+	if params.Dilution != nil {
+		dilution = *params.Dilution
+	}
+	f.Lock()
+	defer f.Unlock()
+	f.nextKeyID++
+	key := ParticipationKey{
+		Address:             account,
+		EffectiveFirstValid: params.First,
+		EffectiveLastValid:  params.Last,
+		Id:                  fmt.Sprintf("FAKEKEY%d", f.nextKeyID),
+	}
+	key.Key.VoteFirstValid = params.First
+	key.Key.VoteLastValid = params.Last
+	key.Key.VoteKeyDilution = dilution
+	// distinct (but meaningless) key material per key, so keys can be told apart by what's registered online
+	key.Key.SelectionParticipationKey = fakeKeyMaterial("selection", key.Id, 32)
+	key.Key.VoteParticipationKey = fakeKeyMaterial("vote", key.Id, 32)
+	key.Key.StateProofKey = fakeKeyMaterial("stateproof", key.Id, 64)
+	f.partKeys = append(f.partKeys, key)
+	return nil
+}
+
+// fakeKeyMaterial returns size bytes derived from the kind of key and the participation key id
+func fakeKeyMaterial(kind string, id string, size int) []byte {
+	material := make([]byte, 0, size)
+	for i := 0; len(material) < size; i++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%d", kind, id, i)))
+		material = append(material, sum[:]...)
+	}
+	return material[:size]
+}
+
+func (f *FakeLedger) DeleteParticipationKey(_ context.Context, partKeyID string) error {
+	f.Lock()
+	defer f.Unlock()
+	idx := slices.IndexFunc(f.partKeys, func(key ParticipationKey) bool { return key.Id == partKeyID })
+	if idx == -1 {
+		return fmt.Errorf("participation key:%s: %w", partKeyID, ErrFakeNotFound)
+	}
+	f.partKeys = slices.Delete(f.partKeys, idx, idx+1)
+	return nil
+}
+
+func (f *FakeLedger) Status(_ context.Context) (models.NodeStatus, error) {
+	f.RLock()
+	defer f.RUnlock()
+	return f.status(), nil
+}
+
+// status must be called with the lock held
+func (f *FakeLedger) status() models.NodeStatus {
+	return models.NodeStatus{
+		LastRound:            f.round,
+		LastVersion:          "fakeledger",
+		NextVersion:          "fakeledger",
+		NextVersionRound:     f.round + 1,
+		NextVersionSupported: true,
+	}
+}
+
+// StatusAfterBlock waits for the ledger to advance past round (or the context to be cancelled)
+func (f *FakeLedger) StatusAfterBlock(ctx context.Context, round uint64) (models.NodeStatus, error) {
+	for {
+		f.RLock()
+		status, advanced := f.status(), f.advanced
+		f.RUnlock()
+		if status.LastRound > round {
+			return status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return models.NodeStatus{}, ctx.Err()
+		case <-advanced:
+		}
+	}
+}
+
+// BlockHeader returns a header for any round up to the current round.  Round 1 is timestamped when the ledger was
+// created, with each round after it BlockTime later.
+func (f *FakeLedger) BlockHeader(_ context.Context, round uint64) (types.BlockHeader, error) {
+	f.RLock()
+	defer f.RUnlock()
+	if round == 0 || round > f.round {
+		return types.BlockHeader{}, fmt.Errorf("round:%d: %w", round, ErrFakeNotFound)
+	}
+	timestamp := f.startTime.Add(time.Duration(round-1) * f.BlockTime)
+	return types.BlockHeader{
+		Round:       types.Round(round),
+		TimeStamp:   timestamp.Unix(),
+		GenesisID:   f.params.GenesisID,
+		GenesisHash: types.Digest(f.params.GenesisHash),
+	}, nil
+}
+
+func (f *FakeLedger) Versions(_ context.Context) (models.Version, error) {
+	f.RLock()
+	defer f.RUnlock()
+	return f.version, nil
+}
+
+func (f *FakeLedger) SuggestedParams(_ context.Context) (types.SuggestedParams, error) {
+	f.RLock()
+	defer f.RUnlock()
+	params := f.params
+	params.FirstRoundValid = types.Round(f.round)
+	params.LastRoundValid = types.Round(f.round + 1000)
+	return params, nil
+}
+
+func (f *FakeLedger) SimulateTransaction(_ context.Context, request models.SimulateRequest) (models.SimulateResponse, error) {
+	if f.SimulateFunc != nil {
+		return f.SimulateFunc(request)
+	}
+	response := models.SimulateResponse{LastRound: f.Round(), Version: 2}
+	for _, group := range request.TxnGroups {
+		groupResult := models.SimulateTransactionGroupResult{}
+		for _, txn := range group.Txns {
+			groupResult.TxnResults = append(groupResult.TxnResults, models.SimulateTransactionResult{
+				TxnResult: models.PendingTransactionResponse{Transaction: txn},
+			})
+		}
+		response.TxnGroups = append(response.TxnGroups, groupResult)
+	}
+	return response, nil
+}
+
+// SendRawTransaction records the signed group and confirms it in a new round, applying any payments
+func (f *FakeLedger) SendRawTransaction(_ context.Context, signedGroup []byte) (string, error) {
+	var txns []types.SignedTxn
+	dec := msgpack.NewDecoder(bytes.NewReader(signedGroup))
+	for {
+		var stxn types.SignedTxn
+		if err := dec.Decode(&stxn); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return "", fmt.Errorf("unable to decode signed transactions: %w", err)
+		}
+		txns = append(txns, stxn)
+	}
+	if len(txns) == 0 {
+		return "", errors.New("no transactions sent")
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	f.advance(1)
+	for _, stxn := range txns {
+		f.submitted = append(f.submitted, stxn)
+		f.confirmed[crypto.GetTxID(stxn.Txn)] = models.PendingTransactionInfoResponse{
+			Transaction:    stxn,
+			ConfirmedRound: f.round,
+		}
+		f.debit(stxn.Txn.Sender.String(), uint64(stxn.Txn.Fee))
+		if stxn.Txn.Type == types.PaymentTx {
+			f.debit(stxn.Txn.Sender.String(), uint64(stxn.Txn.Amount))
+			receiver := f.accounts[stxn.Txn.Receiver.String()]
+			receiver.Address = stxn.Txn.Receiver.String()
+			receiver.Amount += uint64(stxn.Txn.Amount)
+			receiver.AmountWithoutPendingRewards = receiver.Amount
+			f.accounts[receiver.Address] = receiver
+		}
+	}
+	return crypto.GetTxID(txns[0].Txn), nil
+}
+
+// debit must be called with the lock held
+func (f *FakeLedger) debit(address string, amount uint64) {
+	account, found := f.accounts[address]
+	if !found {
+		return
+	}
+	account.Amount -= min(account.Amount, amount)
+	account.AmountWithoutPendingRewards = account.Amount
+	f.accounts[address] = account
+}
+
+func (f *FakeLedger) PendingTransactionInformation(_ context.Context, txid string) (models.PendingTransactionInfoResponse, error) {
+	f.RLock()
+	defer f.RUnlock()
+	info, found := f.confirmed[txid]
+	if !found {
+		return models.PendingTransactionInfoResponse{}, fmt.Errorf("txid:%s: %w", txid, ErrFakeNotFound)
+	}
+	return info, nil
+}
@@ -0,0 +1,128 @@
+package algo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// abiReturnPrefix prefixes the log of an ABI method's return value
+var abiReturnPrefix = []byte{0x15, 0x1f, 0x7c, 0x75}
+
+// Group is a transaction group built by Composer.  It's simulated and sent much like the sdk's atomic transaction
+// composer does, but through a TxnSubmitter rather than an algod client.
+type Group struct {
+	atc *transaction.AtomicTransactionComposer
+	// methods is the method called by each transaction in the group - nil if not a method call
+	methods []*abi.Method
+}
+
+// Transactions returns the transactions of the group (with the group id assigned)
+func (g *Group) Transactions() ([]transaction.TransactionWithSigner, error) {
+	return g.atc.BuildGroup()
+}
+
+// Simulate signs and simulates the group, returning the simulation result along with the result of every method
+// call.
+func (g *Group) Simulate(ctx context.Context, submitter TxnSubmitter, request models.SimulateRequest) (transaction.SimulateResult, error) {
+	signed, err := g.atc.GatherSignatures()
+	if err != nil {
+		return transaction.SimulateResult{}, err
+	}
+	signedTxns := make([]types.SignedTxn, len(signed))
+	for i, stxn := range signed {
+		if err = msgpack.Decode(stxn, &signedTxns[i]); err != nil {
+			return transaction.SimulateResult{}, err
+		}
+	}
+	request.TxnGroups = []models.SimulateRequestTransactionGroup{{Txns: signedTxns}}
+	response, err := submitter.SimulateTransaction(ctx, request)
+	if err != nil {
+		return transaction.SimulateResult{}, err
+	}
+	if len(response.TxnGroups) != 1 || len(response.TxnGroups[0].TxnResults) != len(signedTxns) {
+		return transaction.SimulateResult{}, errors.New("simulate response doesn't match the simulated group")
+	}
+	result := transaction.SimulateResult{SimulateResponse: response}
+	for i, method := range g.methods {
+		if method == nil {
+			continue
+		}
+		txnInfo := models.PendingTransactionInfoResponse(response.TxnGroups[0].TxnResults[i].TxnResult)
+		result.MethodResults = append(result.MethodResults, methodResult(crypto.GetTxID(signedTxns[i].Txn), *method, txnInfo))
+	}
+	return result, nil
+}
+
+// Execute signs and sends the group, waiting (up to waitRounds) for it to be confirmed, returning the result of
+// every method call.
+func (g *Group) Execute(ctx context.Context, chain interface {
+	StatusReader
+	TxnSubmitter
+}, waitRounds uint64) (transaction.ExecuteResult, error) {
+	txns, err := g.atc.BuildGroup()
+	if err != nil {
+		return transaction.ExecuteResult{}, err
+	}
+	signed, err := g.atc.GatherSignatures()
+	if err != nil {
+		return transaction.ExecuteResult{}, err
+	}
+	if _, err = chain.SendRawTransaction(ctx, bytes.Join(signed, nil)); err != nil {
+		return transaction.ExecuteResult{}, err
+	}
+
+	result := transaction.ExecuteResult{}
+	for _, txn := range txns {
+		result.TxIDs = append(result.TxIDs, crypto.GetTxID(txn.Txn))
+	}
+	// the whole group is confirmed together, so we just wait for the first
+	confirmed, err := WaitForConfirmation(ctx, chain, result.TxIDs[0], waitRounds)
+	if err != nil {
+		return result, err
+	}
+	result.ConfirmedRound = confirmed.ConfirmedRound
+	for i, method := range g.methods {
+		if method == nil {
+			continue
+		}
+		txnInfo := confirmed
+		if i != 0 {
+			if txnInfo, err = chain.PendingTransactionInformation(ctx, result.TxIDs[i]); err != nil {
+				result.MethodResults = append(result.MethodResults, transaction.ABIMethodResult{TxID: result.TxIDs[i], Method: *method, DecodeError: err})
+				continue
+			}
+		}
+		result.MethodResults = append(result.MethodResults, methodResult(result.TxIDs[i], *method, txnInfo))
+	}
+	return result, nil
+}
+
+// methodResult decodes the return value of a method call from the (last) log of its transaction
+func methodResult(txid string, method abi.Method, txnInfo models.PendingTransactionInfoResponse) transaction.ABIMethodResult {
+	result := transaction.ABIMethodResult{TxID: txid, Method: method, TransactionInfo: txnInfo}
+	if method.Returns.IsVoid() {
+		result.RawReturnValue = []byte{}
+		return result
+	}
+	if len(txnInfo.Logs) == 0 || !bytes.HasPrefix(txnInfo.Logs[len(txnInfo.Logs)-1], abiReturnPrefix) {
+		result.DecodeError = fmt.Errorf("method call %s did not log a return value", method.Name)
+		return result
+	}
+	result.RawReturnValue = txnInfo.Logs[len(txnInfo.Logs)-1][len(abiReturnPrefix):]
+	returnType, err := method.Returns.GetTypeObject()
+	if err != nil {
+		result.DecodeError = err
+		return result
+	}
+	result.ReturnValue, result.DecodeError = returnType.Decode(result.RawReturnValue)
+	return result
+}
@@ -6,12 +6,12 @@ import (
 	"log/slog"
 	"time"
 
-	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
-	"github.com/algorand/go-algorand-sdk/v2/client/v2/common"
-
 	"github.com/TxnLab/reti/internal/lib/misc"
 )
 
+// PartKeyPollInterval is how often GenerateParticipationKey checks whether the requested key has been generated
+var PartKeyPollInterval = 10 * time.Second
+
 type ParticipationKey struct {
 	Address             string `json:"address"`
 	EffectiveFirstValid uint64 `json:"effective-first-valid"`
@@ -31,10 +31,8 @@ type ParticipationKey struct {
 
 type PartKeysByAddress map[string][]ParticipationKey
 
-func GetParticipationKeys(ctx context.Context, algoClient *algod.Client) (PartKeysByAddress, error) {
-	var response []ParticipationKey
-
-	err := (*common.Client)(algoClient).Get(ctx, &response, fmt.Sprintf("/v2/participation"), nil, nil)
+func GetParticipationKeys(ctx context.Context, keyManager PartKeyManager) (PartKeysByAddress, error) {
+	response, err := keyManager.ParticipationKeys(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get participation keys")
 	}
@@ -48,7 +46,7 @@ func GetParticipationKeys(ctx context.Context, algoClient *algod.Client) (PartKe
 
 type GenerateParticipationKeysParams struct {
 	// Dilution Key dilution for two-level participation keys (defaults to sqrt of validity window).
-	//Dilution *uint64 `form:"dilution,omitempty" json:"dilution,omitempty"`
+	Dilution *uint64 `form:"dilution,omitempty" url:"dilution,omitempty" json:"dilution,omitempty"`
 
 	// First First round for participation key.
 	First uint64 `form:"first" url:"first" json:"first"`
@@ -58,19 +56,22 @@ type GenerateParticipationKeysParams struct {
 }
 
 // GenerateParticipationKey generates a participation key for an account within a specific validity window.
-// It sends a POST request to the Algorand node API to generate the participation key.
-// After the request is sent, it polls the node every 10 seconds to check if the key has been generated.
+// It requests the node generate the participation key.
+// After the request is sent, it polls the node (every PartKeyPollInterval) to check if the key has been generated.
 // If the key is successfully generated, it returns the participation key.
 // If the key is not generated within 30 minutes, it returns an error.
-func GenerateParticipationKey(ctx context.Context, algoClient *algod.Client, logger *slog.Logger, account string, firstValid, lastValid uint64) (*ParticipationKey, error) {
-	var response struct{}
+// A dilution of 0 uses the algod default (sqrt of the validity window).
+func GenerateParticipationKey(ctx context.Context, keyManager PartKeyManager, logger *slog.Logger, account string, firstValid, lastValid, dilution uint64) (*ParticipationKey, error) {
 	var params = GenerateParticipationKeysParams{
 		First: firstValid,
 		Last:  lastValid,
 	}
+	if dilution != 0 {
+		params.Dilution = &dilution
+	}
 
-	misc.Infof(logger, "generating part key for account:%s, first/last valid of %d - %d", account, firstValid, lastValid)
-	err := (*common.Client)(algoClient).Post(ctx, &response, fmt.Sprintf("/v2/participation/generate/%s", account), params, nil, nil)
+	misc.Infof(logger, "generating part key for account:%s, first/last valid of %d - %d, dilution:%d", account, firstValid, lastValid, dilution)
+	err := keyManager.GenerateParticipationKeys(ctx, account, params)
 	if err != nil {
 		return nil, fmt.Errorf("error generating participation key for account:%s, err:%w", account, err)
 	}
@@ -79,9 +80,9 @@ func GenerateParticipationKey(ctx context.Context, algoClient *algod.Client, log
 		select {
 		case <-ctx.Done():
 			return nil, context.Canceled
-		case <-time.After(10 * time.Second):
-			// poll every 10 seconds checking to see if key has been generated
-			partKeys, err := GetParticipationKeys(ctx, algoClient)
+		case <-time.After(PartKeyPollInterval):
+			// poll, checking to see if key has been generated
+			partKeys, err := GetParticipationKeys(ctx, keyManager)
 			if err != nil {
 				return nil, fmt.Errorf("unable to get part keys as part of polling after key generation request, err:%w", err)
 			}
@@ -101,10 +102,9 @@ func GenerateParticipationKey(ctx context.Context, algoClient *algod.Client, log
 	}
 }
 
-func DeleteParticipationKey(ctx context.Context, algoClient *algod.Client, logger *slog.Logger, partKeyID string) error {
-	var response string
+func DeleteParticipationKey(ctx context.Context, keyManager PartKeyManager, logger *slog.Logger, partKeyID string) error {
 	misc.Infof(logger, "delete part key id:%s", partKeyID)
-	err := (*common.Client)(algoClient).Delete(ctx, &response, fmt.Sprintf("/v2/participation/%s", partKeyID), nil, nil)
+	err := keyManager.DeleteParticipationKey(ctx, partKeyID)
 	if err != nil {
 		return fmt.Errorf("error delete participation key for id:%s, err:%w", partKeyID, err)
 	}
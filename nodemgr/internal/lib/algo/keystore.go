@@ -0,0 +1,264 @@
+package algo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+const (
+	keystoreVersion = 1
+	// keystoreCheck is encrypted into every keystore so a wrong passphrase is detected even if there are no keys
+	keystoreCheck = "réti keystore"
+)
+
+var (
+	ErrKeystoreExists     = errors.New("keystore already exists")
+	ErrKeystorePassphrase = errors.New("incorrect keystore passphrase")
+)
+
+// keystoreFile is the on-disk (json) format of a keystore.  Every key is encrypted w/ XChaCha20-Poly1305 using a
+// key derived from the passphrase via scrypt - with the address as additional data so keys can't be swapped
+// between entries.
+type keystoreFile struct {
+	Version int               `json:"version"`
+	KDF     misc.ScryptParams `json:"kdf"`
+	Check   keystoreSecret    `json:"check"`
+	Keys    []keystoreSecret  `json:"keys"`
+}
+
+type keystoreSecret struct {
+	Address string `json:"address,omitempty"`
+	misc.Sealed
+}
+
+// Keystore is an unlocked encrypted keystore file.  It's a MultipleWalletSigner for the keys it holds, and keys can
+// be imported into (or removed from) it.  Close zeroes the decrypted keys.
+type Keystore struct {
+	log  *slog.Logger
+	path string
+
+	sync.RWMutex
+	file    keystoreFile
+	derived []byte
+	keys    map[string]ed25519.PrivateKey
+}
+
+// CreateKeystore creates a new (empty) keystore at path, encrypted w/ passphrase.  The file must not already exist.
+func CreateKeystore(log *slog.Logger, path string, passphrase []byte) (*Keystore, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%s: %w", path, ErrKeystoreExists)
+	}
+	if len(passphrase) == 0 {
+		return nil, errors.New("keystore passphrase can't be empty")
+	}
+	kdf, err := misc.NewScryptParams()
+	if err != nil {
+		return nil, err
+	}
+	ks := &Keystore{
+		log:  log,
+		path: path,
+		file: keystoreFile{Version: keystoreVersion, KDF: kdf},
+		keys: map[string]ed25519.PrivateKey{},
+	}
+	if ks.derived, err = ks.file.KDF.DeriveKey(passphrase); err != nil {
+		return nil, err
+	}
+	if ks.file.Check, err = ks.seal("", []byte(keystoreCheck)); err != nil {
+		return nil, err
+	}
+	if err = ks.save(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
+
+// OpenKeystore opens and decrypts the keystore at path - returning ErrKeystorePassphrase if the passphrase is wrong.
+func OpenKeystore(log *slog.Logger, path string, passphrase []byte) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read keystore: %w", err)
+	}
+	ks := &Keystore{log: log, path: path, keys: map[string]ed25519.PrivateKey{}}
+	if err = json.Unmarshal(data, &ks.file); err != nil {
+		return nil, fmt.Errorf("invalid keystore file %s: %w", path, err)
+	}
+	if ks.file.Version != keystoreVersion || ks.file.KDF.Name != "scrypt" {
+		return nil, fmt.Errorf("unsupported keystore version:%d, kdf:%s", ks.file.Version, ks.file.KDF.Name)
+	}
+	if ks.derived, err = ks.file.KDF.DeriveKey(passphrase); err != nil {
+		return nil, fmt.Errorf("keystore %s: %w", path, err)
+	}
+	check, err := ks.open(ks.file.Check)
+	if err != nil || string(check) != keystoreCheck {
+		ks.Close()
+		return nil, ErrKeystorePassphrase
+	}
+	for _, secret := range ks.file.Keys {
+		seed, err := ks.open(secret)
+		if err != nil {
+			ks.Close()
+			return nil, fmt.Errorf("unable to decrypt key for account:%s: %w", secret.Address, err)
+		}
+		ks.keys[secret.Address] = ed25519.NewKeyFromSeed(seed)
+		clear(seed)
+	}
+	misc.Infof(log, "Keystore %s unlocked, %d accounts", path, len(ks.keys))
+	return ks, nil
+}
+
+func (ks *Keystore) seal(address string, plaintext []byte) (keystoreSecret, error) {
+	sealed, err := misc.Seal(ks.derived, plaintext, []byte(address))
+	if err != nil {
+		return keystoreSecret{}, err
+	}
+	return keystoreSecret{Address: address, Sealed: sealed}, nil
+}
+
+func (ks *Keystore) open(secret keystoreSecret) ([]byte, error) {
+	return misc.Unseal(ks.derived, secret.Sealed, []byte(secret.Address))
+}
+
+// save writes the keystore, replacing the file atomically so it's never left partially written
+func (ks *Keystore) save() error {
+	data, err := json.MarshalIndent(ks.file, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmpFile, err := os.CreateTemp(filepath.Dir(ks.path), filepath.Base(ks.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("unable to write keystore: %w", err)
+	}
+	defer os.Remove(tmpFile.Name())
+	if _, err = tmpFile.Write(data); err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpFile.Name(), fs.FileMode(0o600))
+	}
+	if err == nil {
+		err = os.Rename(tmpFile.Name(), ks.path)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to write keystore: %w", err)
+	}
+	return nil
+}
+
+// Import adds the key to the keystore, returning its address.  It's an error if the key is already present.
+func (ks *Keystore) Import(key ed25519.PrivateKey) (string, error) {
+	account, err := crypto.AccountFromPrivateKey(key)
+	if err != nil {
+		return "", err
+	}
+	address := account.Address.String()
+	ks.Lock()
+	defer ks.Unlock()
+	if _, found := ks.keys[address]; found {
+		return "", fmt.Errorf("account:%s is already in the keystore", address)
+	}
+	secret, err := ks.seal(address, key.Seed())
+	if err != nil {
+		return "", err
+	}
+	ks.file.Keys = append(ks.file.Keys, secret)
+	if err = ks.save(); err != nil {
+		ks.file.Keys = ks.file.Keys[:len(ks.file.Keys)-1]
+		return "", err
+	}
+	ks.keys[address] = slices.Clone(key)
+	return address, nil
+}
+
+// Remove removes the key of address from the keystore
+func (ks *Keystore) Remove(address string) error {
+	ks.Lock()
+	defer ks.Unlock()
+	key, found := ks.keys[address]
+	if !found {
+		return fmt.Errorf("account:%s isn't in the keystore", address)
+	}
+	prevKeys := ks.file.Keys
+	ks.file.Keys = slices.DeleteFunc(slices.Clone(prevKeys), func(secret keystoreSecret) bool { return secret.Address == address })
+	if err := ks.save(); err != nil {
+		ks.file.Keys = prevKeys
+		return err
+	}
+	clear(key)
+	delete(ks.keys, address)
+	return nil
+}
+
+// Addresses returns the (sorted) addresses of the keys in the keystore
+func (ks *Keystore) Addresses() []string {
+	ks.RLock()
+	defer ks.RUnlock()
+	addresses := make([]string, 0, len(ks.keys))
+	for address := range ks.keys {
+		addresses = append(addresses, address)
+	}
+	slices.Sort(addresses)
+	return addresses
+}
+
+// Close zeroes the decrypted keys (and passphrase derived key) - the keystore can't be used afterward.
+func (ks *Keystore) Close() error {
+	ks.Lock()
+	defer ks.Unlock()
+	for address, key := range ks.keys {
+		clear(key)
+		delete(ks.keys, address)
+	}
+	clear(ks.derived)
+	ks.derived = nil
+	return nil
+}
+
+func (ks *Keystore) HasAccount(publicAddress string) bool {
+	ks.RLock()
+	defer ks.RUnlock()
+	_, found := ks.keys[publicAddress]
+	return found
+}
+
+func (ks *Keystore) FindFirstSigner(addresses []string) (string, error) {
+	if len(addresses) == 0 {
+		if all := ks.Addresses(); len(all) > 0 {
+			return all[0], nil
+		}
+	}
+	for _, address := range addresses {
+		if ks.HasAccount(address) {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("no signer found for any of the addresses")
+}
+
+func (ks *Keystore) SignWithAccount(_ context.Context, tx types.Transaction, publicAddress string) (string, []byte, error) {
+	ks.RLock()
+	defer ks.RUnlock()
+	key, found := ks.keys[publicAddress]
+	if !found {
+		return "", nil, fmt.Errorf("key not found for address %s", publicAddress)
+	}
+	return crypto.SignTransaction(key, tx)
+}
@@ -0,0 +1,131 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+func TestKeystore(t *testing.T) {
+	var (
+		log        = slog.New(slog.NewTextHandler(io.Discard, nil))
+		path       = filepath.Join(t.TempDir(), "reti.keystore")
+		passphrase = []byte("correct horse battery staple")
+		first      = crypto.GenerateAccount()
+		second     = crypto.GenerateAccount()
+	)
+	ks, err := CreateKeystore(log, path, passphrase)
+	if err != nil {
+		t.Fatalf("CreateKeystore: %v", err)
+	}
+
+	t.Run("wrong passphrase w/ no keys", func(t *testing.T) {
+		if _, err := OpenKeystore(log, path, []byte("wrong")); !errors.Is(err, ErrKeystorePassphrase) {
+			t.Errorf("err:%v, want %v", err, ErrKeystorePassphrase)
+		}
+	})
+
+	t.Run("create over an existing keystore", func(t *testing.T) {
+		if _, err := CreateKeystore(log, path, passphrase); !errors.Is(err, ErrKeystoreExists) {
+			t.Errorf("err:%v, want %v", err, ErrKeystoreExists)
+		}
+	})
+
+	for _, account := range []crypto.Account{first, second} {
+		address, err := ks.Import(account.PrivateKey)
+		if err != nil {
+			t.Fatalf("Import: %v", err)
+		}
+		if address != account.Address.String() {
+			t.Errorf("imported address:%s, want %s", address, account.Address)
+		}
+	}
+	if _, err = ks.Import(first.PrivateKey); err == nil {
+		t.Error("imported the same key twice")
+	}
+	if info, err := os.Stat(path); err != nil {
+		t.Error(err)
+	} else if info.Mode().Perm() != 0o600 {
+		t.Errorf("keystore file mode:%v, want 0600", info.Mode().Perm())
+	}
+	ks.Close()
+
+	t.Run("reopen", func(t *testing.T) {
+		ks, err := OpenKeystore(log, path, passphrase)
+		if err != nil {
+			t.Fatalf("OpenKeystore: %v", err)
+		}
+		defer ks.Close()
+		want := []string{first.Address.String(), second.Address.String()}
+		slices.Sort(want)
+		if !slices.Equal(ks.Addresses(), want) {
+			t.Errorf("addresses %v, want %v", ks.Addresses(), want)
+		}
+		tx := testPayment(second.Address, "").Txn
+		_, signed, err := ks.SignWithAccount(context.Background(), tx, second.Address.String())
+		if err != nil {
+			t.Fatalf("SignWithAccount: %v", err)
+		}
+		stxns, err := DecodeSignedGroup(signed)
+		if err != nil || !verifySignature(second.Address, tx, stxns[0].Sig) {
+			t.Errorf("signature of the reopened key doesn't verify (err:%v)", err)
+		}
+	})
+
+	t.Run("wrong passphrase", func(t *testing.T) {
+		if _, err := OpenKeystore(log, path, []byte("wrong")); !errors.Is(err, ErrKeystorePassphrase) {
+			t.Errorf("err:%v, want %v", err, ErrKeystorePassphrase)
+		}
+	})
+
+	t.Run("remove persists", func(t *testing.T) {
+		ks, err := OpenKeystore(log, path, passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = ks.Remove(first.Address.String()); err != nil {
+			t.Fatalf("Remove: %v", err)
+		}
+		if err = ks.Remove(first.Address.String()); err == nil {
+			t.Error("removed a key which isn't in the keystore")
+		}
+		ks.Close()
+
+		if ks, err = OpenKeystore(log, path, passphrase); err != nil {
+			t.Fatal(err)
+		}
+		defer ks.Close()
+		if addresses := ks.Addresses(); !slices.Equal(addresses, []string{second.Address.String()}) {
+			t.Errorf("addresses after remove %v, want only %s", addresses, second.Address)
+		}
+	})
+
+	t.Run("close zeroes keys", func(t *testing.T) {
+		ks, err := OpenKeystore(log, path, passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, derived := ks.keys[second.Address.String()], ks.derived
+		ks.Close()
+		if slices.ContainsFunc(key, func(b byte) bool { return b != 0 }) {
+			t.Error("key wasn't zeroed")
+		}
+		if slices.ContainsFunc(derived, func(b byte) bool { return b != 0 }) || ks.derived != nil {
+			t.Error("passphrase derived key wasn't zeroed")
+		}
+		if ks.HasAccount(second.Address.String()) {
+			t.Error("closed keystore still has the account")
+		}
+		if _, _, err = ks.SignWithAccount(context.Background(), types.Transaction{}, second.Address.String()); err == nil {
+			t.Error("closed keystore signed")
+		}
+	})
+}
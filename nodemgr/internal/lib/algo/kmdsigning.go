@@ -0,0 +1,200 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/kmd"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+// kmdRefreshInterval is the minimum time between refetching the wallet's accounts when asked about an account
+// we don't know about (so accounts added to the wallet while we're running are picked up)
+const kmdRefreshInterval = 1 * time.Minute
+
+// KMDConfig defines the kmd daemon, and the wallet within it, to sign with
+type KMDConfig struct {
+	URL      string
+	Token    string
+	Wallet   string
+	Password string
+}
+
+func (k KMDConfig) String() string {
+	return fmt.Sprintf("{URL: %s, Token: (length:%d), Wallet: %s}", k.URL, len(k.Token), k.Wallet)
+}
+
+// NewKMDSigner returns a signer which signs with the accounts of a kmd wallet.  If the kmd url isn't defined in
+// config, the kmd daemon of the node in config.NodeDataDir is used.
+func NewKMDSigner(log *slog.Logger, config NetworkConfig) (MultipleWalletSigner, error) {
+	kmdConfig := config.KMD
+	if kmdConfig.URL == "" {
+		if config.NodeDataDir == "" {
+			return nil, errors.New("kmd url (ALGO_KMD_URL) or node data directory (ALGORAND_DATA) must be set to use kmd")
+		}
+		var err error
+		kmdConfig.URL, kmdConfig.Token, err = getKMDNetAndToken(config.NodeDataDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if kmdConfig.Wallet == "" {
+		return nil, errors.New("kmd wallet name (ALGO_KMD_WALLET) must be set to use kmd")
+	}
+	client, err := kmd.MakeClient(strings.TrimRight(kmdConfig.URL, "/"), kmdConfig.Token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make kmd client (url:%s), error:%w", kmdConfig.URL, err)
+	}
+	wallets, err := client.ListWallets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list kmd wallets (url:%s), error:%w", kmdConfig.URL, err)
+	}
+	walletIdx := slices.IndexFunc(wallets.Wallets, func(wallet kmd.APIV1Wallet) bool { return wallet.Name == kmdConfig.Wallet })
+	if walletIdx == -1 {
+		return nil, fmt.Errorf("kmd wallet:%s not found", kmdConfig.Wallet)
+	}
+	signer := &kmdWalletSigner{
+		log:      log,
+		client:   client,
+		walletID: wallets.Wallets[walletIdx].ID,
+		password: kmdConfig.Password,
+	}
+	// fetching the accounts also verifies the password
+	if err = signer.refreshAccounts(); err != nil {
+		return nil, err
+	}
+	misc.Infof(log, "Signing with kmd wallet:%s at:%s, %d accounts", kmdConfig.Wallet, kmdConfig.URL, len(signer.accounts))
+	return signer, nil
+}
+
+// getKMDNetAndToken returns the kmd url and token of the node in nodeDataDir - kmd keeps its files in a versioned
+// (kmd-vX.Y) subdirectory.
+func getKMDNetAndToken(nodeDataDir string) (string, string, error) {
+	kmdDirs, err := filepath.Glob(filepath.Join(nodeDataDir, "kmd-v*"))
+	if err != nil || len(kmdDirs) == 0 {
+		return "", "", fmt.Errorf("no kmd directory found in node data directory:%s", nodeDataDir)
+	}
+	// use the most recent version if there are several
+	slices.Sort(kmdDirs)
+	kmdDir := kmdDirs[len(kmdDirs)-1]
+	if _, err = os.Stat(filepath.Join(kmdDir, "kmd.net")); err != nil {
+		return "", "", fmt.Errorf("kmd isn't running for the node in:%s (no kmd.net file), error:%w", nodeDataDir, err)
+	}
+	return GetNetAndTokenFromFiles(filepath.Join(kmdDir, "kmd.net"), filepath.Join(kmdDir, "kmd.token"))
+}
+
+type kmdWalletSigner struct {
+	log      *slog.Logger
+	client   kmd.Client
+	walletID string
+	password string
+
+	sync.RWMutex
+	accounts    []string
+	lastRefresh time.Time
+}
+
+// withHandle calls fn with a wallet handle that's valid for the duration of the call.  Handles expire fairly
+// quickly (1 minute by default), so a new one is used for every operation.
+func (k *kmdWalletSigner) withHandle(fn func(handle string) error) error {
+	resp, err := k.client.InitWalletHandle(k.walletID, k.password)
+	if err != nil {
+		return fmt.Errorf("failed to open kmd wallet, error:%w", err)
+	}
+	defer func() {
+		if _, err := k.client.ReleaseWalletHandle(resp.WalletHandleToken); err != nil {
+			misc.Warnf(k.log, "failed to release kmd wallet handle, err:%v", err)
+		}
+	}()
+	return fn(resp.WalletHandleToken)
+}
+
+// refreshAccounts refetches the accounts of the wallet
+func (k *kmdWalletSigner) refreshAccounts() error {
+	var accounts []string
+	err := k.withHandle(func(handle string) error {
+		resp, err := k.client.ListKeys(handle)
+		if err != nil {
+			return fmt.Errorf("failed to list kmd wallet keys, error:%w", err)
+		}
+		accounts = resp.Addresses
+		return nil
+	})
+	k.Lock()
+	defer k.Unlock()
+	k.lastRefresh = time.Now()
+	if err != nil {
+		return err
+	}
+	k.accounts = accounts
+	return nil
+}
+
+func (k *kmdWalletSigner) HasAccount(publicAddress string) bool {
+	k.RLock()
+	found, stale := slices.Contains(k.accounts, publicAddress), time.Since(k.lastRefresh) > kmdRefreshInterval
+	k.RUnlock()
+	if found || !stale {
+		return found
+	}
+	if err := k.refreshAccounts(); err != nil {
+		misc.Warnf(k.log, "unable to refresh kmd wallet accounts, err:%v", err)
+		return false
+	}
+	k.RLock()
+	defer k.RUnlock()
+	return slices.Contains(k.accounts, publicAddress)
+}
+
+// FindFirstSigner returns the first of addresses the wallet has the key for - or the first account of the wallet
+// if addresses is empty.
+func (k *kmdWalletSigner) FindFirstSigner(addresses []string) (string, error) {
+	if len(addresses) == 0 {
+		k.RLock()
+		defer k.RUnlock()
+		if len(k.accounts) > 0 {
+			return k.accounts[0], nil
+		}
+	}
+	for _, address := range addresses {
+		if k.HasAccount(address) {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("no signer found for any of the addresses")
+}
+
+// SignWithAccount signs tx with the key of publicAddress, which needn't be the sender (if the sender was rekeyed
+// to it)
+func (k *kmdWalletSigner) SignWithAccount(_ context.Context, tx types.Transaction, publicAddress string) (string, []byte, error) {
+	signerAddress, err := types.DecodeAddress(publicAddress)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid signing address:%s, error:%w", publicAddress, err)
+	}
+	var signed []byte
+	err = k.withHandle(func(handle string) error {
+		resp, err := k.client.SignTransactionWithSpecificPublicKey(handle, k.password, tx, ed25519.PublicKey(signerAddress[:]))
+		if err != nil {
+			return fmt.Errorf("kmd failed to sign with account:%s, error:%w", publicAddress, err)
+		}
+		signed = resp.SignedTransaction
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return crypto.GetTxID(tx), signed, nil
+}
@@ -0,0 +1,100 @@
+package algo
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestKMDSigner runs against the kmd of a sandbox (ie: algokit localnet) - signing with the accounts of its
+// unencrypted-default-wallet.  It's skipped unless ALGO_KMD_URL or ALGO_KMD_TOKEN is set, ie:
+//
+//	ALGO_KMD_URL=http://localhost:4002 go test ./internal/lib/algo -run TestKMDSigner
+func TestKMDSigner(t *testing.T) {
+	kmdURL, kmdToken := os.Getenv("ALGO_KMD_URL"), os.Getenv("ALGO_KMD_TOKEN")
+	if kmdURL == "" && kmdToken == "" {
+		t.Skip("ALGO_KMD_URL / ALGO_KMD_TOKEN not set - skipping kmd integration test")
+	}
+	cfg := GetNetworkConfig("sandbox")
+	if kmdURL != "" {
+		cfg.KMD.URL = kmdURL
+	}
+	if kmdToken != "" {
+		cfg.KMD.Token = kmdToken
+	}
+	cfg.KMD.Wallet, cfg.KMD.Password = "unencrypted-default-wallet", ""
+
+	signer, err := NewKMDSigner(slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+	if err != nil {
+		t.Fatalf("NewKMDSigner: %v", err)
+	}
+	account, err := signer.FindFirstSigner(nil)
+	if err != nil {
+		t.Fatalf("FindFirstSigner w/ no addresses: %v", err)
+	}
+	notInWallet := crypto.GenerateAccount().Address.String()
+
+	t.Run("HasAccount", func(t *testing.T) {
+		if !signer.HasAccount(account) {
+			t.Errorf("HasAccount(%s) = false for an account of the wallet", account)
+		}
+		if signer.HasAccount(notInWallet) {
+			t.Errorf("HasAccount(%s) = true for an account not in the wallet", notInWallet)
+		}
+	})
+
+	t.Run("FindFirstSigner", func(t *testing.T) {
+		found, err := signer.FindFirstSigner([]string{notInWallet, account})
+		if err != nil || found != account {
+			t.Errorf("FindFirstSigner = %s, %v, want %s", found, err, account)
+		}
+		if _, err = signer.FindFirstSigner([]string{notInWallet}); err == nil {
+			t.Error("FindFirstSigner found a signer for an account not in the wallet")
+		}
+	})
+
+	t.Run("SignWithAccount", func(t *testing.T) {
+		params := types.SuggestedParams{
+			Fee:             1000,
+			FlatFee:         true,
+			FirstRoundValid: 1,
+			LastRoundValid:  1000,
+			GenesisID:       "sandnet-v1",
+			GenesisHash:     make([]byte, 32),
+			MinFee:          1000,
+		}
+		tx, err := transaction.MakePaymentTxn(account, account, 0, nil, "", params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txid, signed, err := signer.SignWithAccount(context.Background(), tx, account)
+		if err != nil {
+			t.Fatalf("SignWithAccount: %v", err)
+		}
+		if txid != crypto.GetTxID(tx) {
+			t.Errorf("SignWithAccount txid:%s, want %s", txid, crypto.GetTxID(tx))
+		}
+		var stxn types.SignedTxn
+		if err = msgpack.Decode(signed, &stxn); err != nil {
+			t.Fatalf("signed transaction doesn't decode: %v", err)
+		}
+		signerAddr, _ := types.DecodeAddress(account)
+		message := append([]byte("TX"), msgpack.Encode(tx)...)
+		if !ed25519.Verify(signerAddr[:], message, stxn.Sig[:]) {
+			t.Error("signature doesn't verify against the account's key")
+		}
+
+		if _, _, err = signer.SignWithAccount(context.Background(), tx, notInWallet); err == nil {
+			t.Error("SignWithAccount signed with an account not in the wallet")
+		}
+	})
+}
@@ -14,15 +14,35 @@ type NetworkConfig struct {
 
 	NFDAPIUrl string
 
-	NodeURL     string
-	NodeToken   string
-	NodeHeaders map[string]string
+	NodeURL   string
+	NodeToken string
+	// NodeTokenSecret is the name of the secret NodeToken came from - it's re-read on every request so the token
+	// can be rotated without a restart
+	NodeTokenSecret string
+	NodeHeaders     map[string]string
+
+	// Endpoints is an ordered list of additional algod endpoints to fail over to.  The node defined by NodeDataDir
+	// or NodeURL is always the primary (first) endpoint, and is the only one used for participation key calls as
+	// it's the node that actually holds the keys.
+	Endpoints []AlgodEndpoint
+
+	// KMD defines the kmd daemon and wallet used for signing when the kmd signer is selected
+	KMD KMDConfig
 
 	RetiAppID uint64
 }
 
+// AlgodEndpoint defines a single algod api endpoint and its credentials
+type AlgodEndpoint struct {
+	URL   string
+	Token string
+	// TokenSecret is the name of the secret Token came from (re-read on every request)
+	TokenSecret string
+	Headers     map[string]string
+}
+
 func (n NetworkConfig) String() string {
-	return fmt.Sprintf("NodeDataDir: %s, NFDAPIUrl: %s, NodeURL: %s, NodeToken: (length:%d), NodeHeaders: %v, RetiAppID: %d", n.NodeDataDir, n.NFDAPIUrl, n.NodeURL, len(n.NodeToken), n.NodeHeaders, n.RetiAppID)
+	return fmt.Sprintf("NodeDataDir: %s, NFDAPIUrl: %s, NodeURL: %s, NodeToken: (length:%d), NodeHeaders: %v, Endpoints: %d, KMD: %s, RetiAppID: %d", n.NodeDataDir, n.NFDAPIUrl, n.NodeURL, len(n.NodeToken), n.NodeHeaders, len(n.Endpoints), n.KMD, n.RetiAppID)
 }
 
 func GetNetworkConfig(network string) NetworkConfig {
@@ -50,25 +70,60 @@ func GetNetworkConfig(network string) NetworkConfig {
 	nodeToken := misc.GetSecret("ALGO_ALGOD_TOKEN")
 	if nodeToken != "" {
 		cfg.NodeToken = nodeToken
+		cfg.NodeTokenSecret = "ALGO_ALGOD_TOKEN"
 	}
 	// ALGO_ALGOD_ADMIN_TOKEN is what we assume users will use, so it takes precedence for the node token
 	// (which is required to be an admin token)
 	if token := misc.GetSecret("ALGO_ALGOD_ADMIN_TOKEN"); token != "" {
 		cfg.NodeToken = token
+		cfg.NodeTokenSecret = "ALGO_ALGOD_ADMIN_TOKEN"
+	}
+	cfg.NodeHeaders = parseHeaders(misc.GetSecret("ALGO_ALGOD_HEADERS"))
+
+	// Additional failover endpoints are defined in order via ALGO_ALGOD_URL_2, ALGO_ALGOD_URL_3, ... each with
+	// optional matching ALGO_ALGOD_TOKEN_n and ALGO_ALGOD_HEADERS_n values.
+	for i := 2; ; i++ {
+		endpointURL := misc.GetSecret(fmt.Sprintf("ALGO_ALGOD_URL_%d", i))
+		if endpointURL == "" {
+			break
+		}
+		tokenSecret := fmt.Sprintf("ALGO_ALGOD_TOKEN_%d", i)
+		cfg.Endpoints = append(cfg.Endpoints, AlgodEndpoint{
+			URL:         endpointURL,
+			Token:       misc.GetSecret(tokenSecret),
+			TokenSecret: tokenSecret,
+			Headers:     parseHeaders(misc.GetSecret(fmt.Sprintf("ALGO_ALGOD_HEADERS_%d", i))),
+		})
+	}
+
+	if kmdURL := misc.GetSecret("ALGO_KMD_URL"); kmdURL != "" {
+		cfg.KMD.URL = kmdURL
+	}
+	if kmdToken := misc.GetSecret("ALGO_KMD_TOKEN"); kmdToken != "" {
+		cfg.KMD.Token = kmdToken
 	}
-	NodeHeaders := misc.GetSecret("ALGO_ALGOD_HEADERS")
-	// parse NodeHeaders from key:value,[key:value...] pairs and put into cfg.NodeHeaders map
-	cfg.NodeHeaders = map[string]string{}
-	for _, header := range strings.Split(NodeHeaders, ",") {
+	if wallet := os.Getenv("ALGO_KMD_WALLET"); wallet != "" {
+		cfg.KMD.Wallet = wallet
+	}
+	if password := misc.GetSecret("ALGO_KMD_PASSWORD"); password != "" {
+		cfg.KMD.Password = password
+	}
+
+	return cfg
+}
+
+// parseHeaders parses headers from key:value,[key:value...] pairs into a map
+func parseHeaders(headers string) map[string]string {
+	retHeaders := map[string]string{}
+	for _, header := range strings.Split(headers, ",") {
 		parts := strings.SplitN(header, ":", 2) // Just split on first : - they can have :'s in value.
 		if len(parts) == 2 {
 			key := strings.TrimSpace(parts[0])
 			value := strings.TrimSpace(parts[1])
-			cfg.NodeHeaders[key] = value
+			retHeaders[key] = value
 		}
 	}
-
-	return cfg
+	return retHeaders
 }
 
 func getDefaults(network string) NetworkConfig {
@@ -95,6 +150,11 @@ func getDefaults(network string) NetworkConfig {
 		cfg.NFDAPIUrl = "https://api.testnet.nf.domains"
 		cfg.NodeURL = "http://localhost:4001"
 		cfg.NodeToken = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+		cfg.KMD = KMDConfig{
+			URL:    "http://localhost:4002",
+			Token:  "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			Wallet: "unencrypted-default-wallet",
+		}
 	//-----
 	// VOI
 	//-----
@@ -0,0 +1,251 @@
+package algo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// ErrExternallySigned is returned when asked to sign for an account whose transactions are signed elsewhere (ie: a
+// cold multisig) - the transactions have to be exported unsigned instead.
+var ErrExternallySigned = errors.New("account is signed externally - export the transactions unsigned instead")
+
+// NewExternalSigner returns a signer which signs w/ signer, but which also treats the external addresses as
+// signers (of last resort) - FindFirstSigner returns them rather than failing, but they can't sign anything here.
+func NewExternalSigner(signer MultipleWalletSigner, external ...string) MultipleWalletSigner {
+	return &externalSigner{signer: signer, external: external}
+}
+
+type externalSigner struct {
+	signer   MultipleWalletSigner
+	external []string
+}
+
+func (e *externalSigner) HasAccount(publicAddress string) bool {
+	return e.signer.HasAccount(publicAddress)
+}
+
+// FindFirstSigner returns the first of addresses we have the key for - falling back to the first of them which is
+// signed externally.
+func (e *externalSigner) FindFirstSigner(addresses []string) (string, error) {
+	if address, err := e.signer.FindFirstSigner(addresses); err == nil {
+		return address, nil
+	}
+	for _, address := range addresses {
+		if slices.Contains(e.external, address) {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("no signer found for any of the addresses")
+}
+
+func (e *externalSigner) SignWithAccount(ctx context.Context, tx types.Transaction, publicAddress string) (string, []byte, error) {
+	if !e.signer.HasAccount(publicAddress) && slices.Contains(e.external, publicAddress) {
+		return "", nil, fmt.Errorf("account:%s: %w", publicAddress, ErrExternallySigned)
+	}
+	return e.signer.SignWithAccount(ctx, tx, publicAddress)
+}
+
+func (e *externalSigner) Describe(address string) string {
+	return DescribeSigningAccount(e.signer, address)
+}
+
+func (e *externalSigner) Close() error {
+	return CloseSigner(e.signer)
+}
+
+// DecodeSignedGroup decodes concatenated msgpack encoded signed (or not) transactions
+func DecodeSignedGroup(data []byte) ([]types.SignedTxn, error) {
+	var stxns []types.SignedTxn
+	decoder := msgpack.NewDecoder(bytes.NewReader(data))
+	for {
+		var stxn types.SignedTxn
+		err := decoder.Decode(&stxn)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid transaction file, transaction %d: %w", len(stxns), err)
+		}
+		stxns = append(stxns, stxn)
+	}
+	if len(stxns) == 0 {
+		return nil, errors.New("no transactions found")
+	}
+	return stxns, nil
+}
+
+// EncodeSignedGroup returns the signed (or not) transactions msgpack encoded and concatenated, as they're sent.
+// It's the same format goal uses for transaction files, so goal can sign them as well.
+func EncodeSignedGroup(stxns []types.SignedTxn) []byte {
+	var encoded []byte
+	for _, stxn := range stxns {
+		encoded = append(encoded, msgpack.Encode(stxn)...)
+	}
+	return encoded
+}
+
+// authorizer returns the address which has to sign the transaction - the sender unless it's been rekeyed
+func authorizer(stxn types.SignedTxn) types.Address {
+	if !stxn.AuthAddr.IsZero() {
+		return stxn.AuthAddr
+	}
+	return stxn.Txn.Sender
+}
+
+// SignGroup adds every signature signer can to the transactions.  Transactions which already have (partial)
+// multisig signatures get those of the multisig members signer has keys for - as do the transactions authorized by
+// msig, if set.  Returns the number of signatures added.
+func SignGroup(ctx context.Context, signer MultipleWalletSigner, stxns []types.SignedTxn, msig *crypto.MultisigAccount) (int, error) {
+	var msigAddr types.Address
+	if msig != nil {
+		var err error
+		if msigAddr, err = msig.Address(); err != nil {
+			return 0, err
+		}
+	}
+	var numSigned int
+	for i := range stxns {
+		stxn := &stxns[i]
+		auth := authorizer(*stxn)
+		if stxn.Msig.Blank() && msig != nil && auth == msigAddr {
+			stxn.Msig = types.MultisigSig{Version: msig.Version, Threshold: msig.Threshold}
+			for _, pk := range msig.Pks {
+				stxn.Msig.Subsigs = append(stxn.Msig.Subsigs, types.MultisigSubsig{Key: pk})
+			}
+		}
+		if auth != stxn.Txn.Sender {
+			stxn.AuthAddr = auth
+		}
+		if !stxn.Msig.Blank() {
+			txnMsig, err := crypto.MultisigAccountFromSig(stxn.Msig)
+			if err != nil {
+				return numSigned, fmt.Errorf("txn %d has an invalid multisig: %w", i, err)
+			}
+			if addr, err := txnMsig.Address(); err != nil || addr != auth {
+				return numSigned, fmt.Errorf("txn %d multisig isn't that of its signer:%s", i, auth)
+			}
+			for j, subsig := range stxn.Msig.Subsigs {
+				member := types.Address(subsig.Key)
+				if subsig.Sig != (types.Signature{}) || !signer.HasAccount(member.String()) {
+					continue
+				}
+				// a multisig member signs the same bytes it would sign for its own account
+				sig, err := signatureOf(ctx, signer, stxn.Txn, member)
+				if err != nil {
+					return numSigned, fmt.Errorf("error signing txn %d w/ multisig member:%s: %w", i, member, err)
+				}
+				stxn.Msig.Subsigs[j].Sig = sig
+				numSigned++
+			}
+			continue
+		}
+		if stxn.Sig == (types.Signature{}) && stxn.Lsig.Blank() && signer.HasAccount(auth.String()) {
+			sig, err := signatureOf(ctx, signer, stxn.Txn, auth)
+			if err != nil {
+				return numSigned, fmt.Errorf("error signing txn %d w/ account:%s: %w", i, auth, err)
+			}
+			stxn.Sig = sig
+			numSigned++
+		}
+	}
+	return numSigned, nil
+}
+
+// signatureOf returns the signature of account over tx, verifying it
+func signatureOf(ctx context.Context, signer MultipleWalletSigner, tx types.Transaction, account types.Address) (types.Signature, error) {
+	_, signedBytes, err := signer.SignWithAccount(ctx, tx, account.String())
+	if err != nil {
+		return types.Signature{}, err
+	}
+	var signed types.SignedTxn
+	if err = msgpack.Decode(signedBytes, &signed); err != nil {
+		return types.Signature{}, err
+	}
+	if !verifySignature(account, tx, signed.Sig) {
+		return types.Signature{}, fmt.Errorf("invalid signature returned for account:%s", account)
+	}
+	return signed.Sig, nil
+}
+
+func verifySignature(account types.Address, tx types.Transaction, sig types.Signature) bool {
+	// transactions are signed w/ a "TX" prefix, as with txids
+	return ed25519.Verify(account[:], append([]byte("TX"), msgpack.Encode(tx)...), sig[:])
+}
+
+// MergeSignedGroups merges the signatures of several copies of the same transaction group (ie: each signed by a
+// different multisig member) into one.
+func MergeSignedGroups(groups ...[]types.SignedTxn) ([]types.SignedTxn, error) {
+	if len(groups) == 0 {
+		return nil, errors.New("no transaction groups to merge")
+	}
+	merged := slices.Clone(groups[0])
+	for groupIdx, group := range groups[1:] {
+		if len(group) != len(merged) {
+			return nil, fmt.Errorf("group %d has %d transactions, not %d", groupIdx+2, len(group), len(merged))
+		}
+		for i, stxn := range group {
+			into := &merged[i]
+			if crypto.GetTxID(stxn.Txn) != crypto.GetTxID(into.Txn) {
+				return nil, fmt.Errorf("transaction %d of group %d is a different transaction", i, groupIdx+2)
+			}
+			if into.AuthAddr.IsZero() {
+				into.AuthAddr = stxn.AuthAddr
+			}
+			if into.Sig == (types.Signature{}) {
+				into.Sig = stxn.Sig
+			}
+			if stxn.Msig.Blank() {
+				continue
+			}
+			if into.Msig.Blank() {
+				into.Msig = stxn.Msig
+				into.Msig.Subsigs = slices.Clone(stxn.Msig.Subsigs)
+				continue
+			}
+			if into.Msig.Version != stxn.Msig.Version || into.Msig.Threshold != stxn.Msig.Threshold || len(into.Msig.Subsigs) != len(stxn.Msig.Subsigs) {
+				return nil, fmt.Errorf("transaction %d of group %d has a different multisig account", i, groupIdx+2)
+			}
+			into.Msig.Subsigs = slices.Clone(into.Msig.Subsigs)
+			for j, subsig := range stxn.Msig.Subsigs {
+				if !slices.Equal(subsig.Key, into.Msig.Subsigs[j].Key) {
+					return nil, fmt.Errorf("transaction %d of group %d has a different multisig account", i, groupIdx+2)
+				}
+				if into.Msig.Subsigs[j].Sig == (types.Signature{}) {
+					into.Msig.Subsigs[j].Sig = subsig.Sig
+				}
+			}
+		}
+	}
+	return merged, nil
+}
+
+// SignatureStatus describes how far along the signing of a transaction is - ie: "signed", "unsigned" or "multisig
+// 1 of 2", and whether it's fully signed.
+func SignatureStatus(stxn types.SignedTxn) (string, bool) {
+	switch {
+	case !stxn.Lsig.Blank():
+		return "logicsig", true
+	case !stxn.Msig.Blank():
+		var numSigs int
+		for _, subsig := range stxn.Msig.Subsigs {
+			if subsig.Sig != (types.Signature{}) {
+				numSigs++
+			}
+		}
+		return fmt.Sprintf("multisig %d of %d", numSigs, stxn.Msig.Threshold), numSigs >= int(stxn.Msig.Threshold)
+	case stxn.Sig != (types.Signature{}):
+		return "signed", true
+	default:
+		return "unsigned", false
+	}
+}
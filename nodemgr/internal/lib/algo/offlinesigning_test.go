@@ -0,0 +1,327 @@
+package algo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// accountsSigner signs w/ the keys of its accounts
+type accountsSigner map[string]crypto.Account
+
+func newAccountsSigner(accounts ...crypto.Account) accountsSigner {
+	signer := accountsSigner{}
+	for _, account := range accounts {
+		signer[account.Address.String()] = account
+	}
+	return signer
+}
+
+func (s accountsSigner) HasAccount(publicAddress string) bool {
+	_, found := s[publicAddress]
+	return found
+}
+
+func (s accountsSigner) FindFirstSigner(addresses []string) (string, error) {
+	for _, address := range addresses {
+		if s.HasAccount(address) {
+			return address, nil
+		}
+	}
+	return "", errors.New("no signer found")
+}
+
+func (s accountsSigner) SignWithAccount(_ context.Context, tx types.Transaction, publicAddress string) (string, []byte, error) {
+	account, found := s[publicAddress]
+	if !found {
+		return "", nil, fmt.Errorf("no key for:%s", publicAddress)
+	}
+	return crypto.SignTransaction(account.PrivateKey, tx)
+}
+
+type offlineSigningTest struct {
+	members [3]crypto.Account
+	msig    crypto.MultisigAccount
+	// msigAddr is the address of the 2 of 3 multisig of members
+	msigAddr types.Address
+	// single is a (non multisig) account
+	single crypto.Account
+}
+
+func newOfflineSigningTest(t *testing.T) offlineSigningTest {
+	ot := offlineSigningTest{single: crypto.GenerateAccount()}
+	var addresses []types.Address
+	for i := range ot.members {
+		ot.members[i] = crypto.GenerateAccount()
+		addresses = append(addresses, ot.members[i].Address)
+	}
+	var err error
+	if ot.msig, err = crypto.MultisigAccountWithParams(1, 2, addresses); err != nil {
+		t.Fatal(err)
+	}
+	if ot.msigAddr, err = ot.msig.Address(); err != nil {
+		t.Fatal(err)
+	}
+	return ot
+}
+
+func testPayment(sender types.Address, note string) types.SignedTxn {
+	tx := types.Transaction{Type: types.PaymentTx, Header: types.Header{Sender: sender, Fee: 1000, FirstValid: 1, LastValid: 1000, Note: []byte(note)}}
+	tx.Receiver = sender
+	return types.SignedTxn{Txn: tx}
+}
+
+// withSubsigs returns stxn w/ the msig template of ot.msig, signed by the members at signed
+func (ot offlineSigningTest) withSubsigs(t *testing.T, stxn types.SignedTxn, signed ...int) types.SignedTxn {
+	stxn.Msig = types.MultisigSig{Version: ot.msig.Version, Threshold: ot.msig.Threshold}
+	for i, pk := range ot.msig.Pks {
+		subsig := types.MultisigSubsig{Key: pk}
+		for _, member := range signed {
+			if member == i {
+				_, signedBytes, err := newAccountsSigner(ot.members[i]).SignWithAccount(context.Background(), stxn.Txn, ot.members[i].Address.String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				signedTxn, _ := DecodeSignedGroup(signedBytes)
+				subsig.Sig = signedTxn[0].Sig
+			}
+		}
+		stxn.Msig.Subsigs = append(stxn.Msig.Subsigs, subsig)
+	}
+	return stxn
+}
+
+// verifySubsigs fails the test unless every multisig subsig present is a valid signature of the transaction
+func verifySubsigs(t *testing.T, stxn types.SignedTxn) {
+	t.Helper()
+	for j, subsig := range stxn.Msig.Subsigs {
+		if subsig.Sig != (types.Signature{}) && !verifySignature(types.Address(subsig.Key), stxn.Txn, subsig.Sig) {
+			t.Errorf("multisig subsig %d doesn't verify", j)
+		}
+	}
+}
+
+func TestSignGroup(t *testing.T) {
+	ot := newOfflineSigningTest(t)
+	rekeyed := crypto.GenerateAccount().Address
+	tests := []struct {
+		name       string
+		signer     accountsSigner
+		stxn       types.SignedTxn
+		msig       *crypto.MultisigAccount
+		wantSigned int
+		wantStatus string
+		wantAuth   types.Address
+		wantErr    string
+	}{
+		{
+			name:       "single signature",
+			signer:     newAccountsSigner(ot.single),
+			stxn:       testPayment(ot.single.Address, ""),
+			wantSigned: 1,
+			wantStatus: "signed",
+		},
+		{
+			name:       "no key for the sender",
+			signer:     newAccountsSigner(ot.members[0]),
+			stxn:       testPayment(ot.single.Address, ""),
+			wantStatus: "unsigned",
+		},
+		{
+			name:       "multisig template inserted for the sender",
+			signer:     newAccountsSigner(ot.members[0]),
+			stxn:       testPayment(ot.msigAddr, ""),
+			msig:       &ot.msig,
+			wantSigned: 1,
+			wantStatus: "multisig 1 of 2",
+		},
+		{
+			name:       "multisig template w/out member keys",
+			signer:     newAccountsSigner(ot.single),
+			stxn:       testPayment(ot.msigAddr, ""),
+			msig:       &ot.msig,
+			wantStatus: "multisig 0 of 2",
+		},
+		{
+			name:   "sender rekeyed to the multisig",
+			signer: newAccountsSigner(ot.members[0], ot.members[2]),
+			stxn: func() types.SignedTxn {
+				stxn := testPayment(rekeyed, "")
+				stxn.AuthAddr = ot.msigAddr
+				return stxn
+			}(),
+			msig:       &ot.msig,
+			wantSigned: 2,
+			wantStatus: "multisig 2 of 2",
+			wantAuth:   ot.msigAddr,
+		},
+		{
+			name:       "partial multisig completed",
+			signer:     newAccountsSigner(ot.members[1]),
+			stxn:       ot.withSubsigs(t, testPayment(ot.msigAddr, ""), 0),
+			wantSigned: 1,
+			wantStatus: "multisig 2 of 2",
+		},
+		{
+			name:       "existing subsigs aren't signed again",
+			signer:     newAccountsSigner(ot.members[0]),
+			stxn:       ot.withSubsigs(t, testPayment(ot.msigAddr, ""), 0),
+			wantStatus: "multisig 1 of 2",
+		},
+		{
+			name:    "multisig of another account",
+			signer:  newAccountsSigner(ot.members[0]),
+			stxn:    ot.withSubsigs(t, testPayment(ot.single.Address, "")),
+			wantErr: "multisig isn't that of its signer",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stxns := []types.SignedTxn{tt.stxn}
+			numSigned, err := SignGroup(context.Background(), tt.signer, stxns, tt.msig)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err:%v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SignGroup: %v", err)
+			}
+			if numSigned != tt.wantSigned {
+				t.Errorf("signed %d, want %d", numSigned, tt.wantSigned)
+			}
+			if status, _ := SignatureStatus(stxns[0]); status != tt.wantStatus {
+				t.Errorf("status %q, want %q", status, tt.wantStatus)
+			}
+			if stxns[0].AuthAddr != tt.wantAuth {
+				t.Errorf("auth addr %s, want %s", stxns[0].AuthAddr, tt.wantAuth)
+			}
+			if stxns[0].Sig != (types.Signature{}) && !verifySignature(stxns[0].Txn.Sender, stxns[0].Txn, stxns[0].Sig) {
+				t.Error("signature doesn't verify")
+			}
+			verifySubsigs(t, stxns[0])
+		})
+	}
+}
+
+func TestMergeSignedGroups(t *testing.T) {
+	ot := newOfflineSigningTest(t)
+	other := newOfflineSigningTest(t)
+	payment := testPayment(ot.msigAddr, "")
+	rekeyed := testPayment(ot.single.Address, "")
+	rekeyedWithAuth := rekeyed
+	rekeyedWithAuth.AuthAddr = ot.msigAddr
+	tests := []struct {
+		name       string
+		groups     [][]types.SignedTxn
+		wantStatus []string
+		wantAuth   types.Address
+		wantErr    string
+	}{
+		{
+			name:       "subsigs of each copy",
+			groups:     [][]types.SignedTxn{{ot.withSubsigs(t, payment, 0)}, {ot.withSubsigs(t, payment, 2)}},
+			wantStatus: []string{"multisig 2 of 2"},
+		},
+		{
+			name:       "unsigned copy",
+			groups:     [][]types.SignedTxn{{payment}, {ot.withSubsigs(t, payment, 1)}},
+			wantStatus: []string{"multisig 1 of 2"},
+		},
+		{
+			name:       "auth addr of a rekeyed sender",
+			groups:     [][]types.SignedTxn{{ot.withSubsigs(t, rekeyed)}, {ot.withSubsigs(t, rekeyedWithAuth, 1)}},
+			wantStatus: []string{"multisig 1 of 2"},
+			wantAuth:   ot.msigAddr,
+		},
+		{
+			name:    "different transaction",
+			groups:  [][]types.SignedTxn{{ot.withSubsigs(t, payment, 0)}, {ot.withSubsigs(t, testPayment(ot.msigAddr, "other"), 1)}},
+			wantErr: "is a different transaction",
+		},
+		{
+			name:    "different multisig",
+			groups:  [][]types.SignedTxn{{ot.withSubsigs(t, payment, 0)}, {other.withSubsigs(t, payment, 1)}},
+			wantErr: "has a different multisig account",
+		},
+		{
+			name:    "different group size",
+			groups:  [][]types.SignedTxn{{payment}, {payment, payment}},
+			wantErr: "group 2 has 2 transactions, not 1",
+		},
+		{
+			name:    "nothing to merge",
+			wantErr: "no transaction groups to merge",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var before []string
+			for _, group := range tt.groups {
+				before = append(before, string(EncodeSignedGroup(group)))
+			}
+			merged, err := MergeSignedGroups(tt.groups...)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("err:%v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MergeSignedGroups: %v", err)
+			}
+			for i, stxn := range merged {
+				if status, _ := SignatureStatus(stxn); status != tt.wantStatus[i] {
+					t.Errorf("txn %d status %q, want %q", i, status, tt.wantStatus[i])
+				}
+				if stxn.AuthAddr != tt.wantAuth {
+					t.Errorf("txn %d auth addr %s, want %s", i, stxn.AuthAddr, tt.wantAuth)
+				}
+				verifySubsigs(t, stxn)
+			}
+			for i, group := range tt.groups {
+				if string(EncodeSignedGroup(group)) != before[i] {
+					t.Errorf("group %d was modified by the merge", i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestSignatureStatus(t *testing.T) {
+	ot := newOfflineSigningTest(t)
+	payment := testPayment(ot.msigAddr, "")
+	signedGroup := []types.SignedTxn{testPayment(ot.single.Address, "")}
+	if _, err := SignGroup(context.Background(), newAccountsSigner(ot.single), signedGroup, nil); err != nil {
+		t.Fatal(err)
+	}
+	lsig := testPayment(ot.single.Address, "")
+	lsig.Lsig = types.LogicSig{Logic: []byte{0x01}}
+	tests := []struct {
+		name         string
+		stxn         types.SignedTxn
+		wantStatus   string
+		wantComplete bool
+	}{
+		{name: "unsigned", stxn: payment, wantStatus: "unsigned"},
+		{name: "signed", stxn: signedGroup[0], wantStatus: "signed", wantComplete: true},
+		{name: "logicsig", stxn: lsig, wantStatus: "logicsig", wantComplete: true},
+		{name: "multisig below the threshold", stxn: ot.withSubsigs(t, payment, 1), wantStatus: "multisig 1 of 2"},
+		{name: "multisig at the threshold", stxn: ot.withSubsigs(t, payment, 0, 2), wantStatus: "multisig 2 of 2", wantComplete: true},
+		{name: "multisig over the threshold", stxn: ot.withSubsigs(t, payment, 0, 1, 2), wantStatus: "multisig 3 of 2", wantComplete: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, complete := SignatureStatus(tt.stxn)
+			if status != tt.wantStatus || complete != tt.wantComplete {
+				t.Errorf("SignatureStatus = %q, %v, want %q, %v", status, complete, tt.wantStatus, tt.wantComplete)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package algo
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+// authAddrRefreshInterval is how long the authorizing address of an account is cached before it's looked up again
+// (so accounts rekeyed while we're running are picked up)
+const authAddrRefreshInterval = 10 * time.Minute
+
+// AuthAddress returns the address authorized to sign for address - its auth address if it's been rekeyed, otherwise
+// the address itself.
+func AuthAddress(ctx context.Context, accounts AccountReader, address string) (string, error) {
+	account, err := accounts.BareAccountInformation(ctx, address)
+	if err != nil {
+		return "", fmt.Errorf("unable to fetch account:%s, err:%w", address, err)
+	}
+	if account.AuthAddr != "" && account.AuthAddr != types.ZeroAddress.String() {
+		return account.AuthAddr, nil
+	}
+	return address, nil
+}
+
+// NewRekeyAwareSigner returns a signer which signs for accounts w/ the key of their authorizing address - so an
+// account rekeyed to another is signed for by the key of the account it was rekeyed to.  Addresses are resolved
+// to their authorizing address via accounts.
+func NewRekeyAwareSigner(log *slog.Logger, signer MultipleWalletSigner, accounts AccountReader) *RekeyAwareSigner {
+	return &RekeyAwareSigner{log: log, signer: signer, accounts: accounts, authAddrs: map[string]authAddrEntry{}}
+}
+
+// RekeyAwareSigner is a MultipleWalletSigner for accounts whose authorizing address another signer has the key for.
+type RekeyAwareSigner struct {
+	log      *slog.Logger
+	signer   MultipleWalletSigner
+	accounts AccountReader
+
+	sync.Mutex
+	authAddrs map[string]authAddrEntry
+}
+
+type authAddrEntry struct {
+	authAddr string
+	fetched  time.Time
+}
+
+// AuthAddr returns the authorizing address of address (cached).  If it can't be fetched, the address itself is
+// returned along with the error.
+func (r *RekeyAwareSigner) AuthAddr(address string) (string, error) {
+	r.Lock()
+	entry, found := r.authAddrs[address]
+	r.Unlock()
+	if found && time.Since(entry.fetched) < authAddrRefreshInterval {
+		return entry.authAddr, nil
+	}
+	authAddr, err := AuthAddress(context.Background(), r.accounts, address)
+	if err != nil {
+		if found {
+			// keep using what we had
+			return entry.authAddr, err
+		}
+		return address, err
+	}
+	if authAddr != address && (!found || entry.authAddr != authAddr) {
+		misc.Infof(r.log, "account:%s is rekeyed to:%s - signing for it with the key of the latter", address, authAddr)
+	}
+	r.Lock()
+	r.authAddrs[address] = authAddrEntry{authAddr: authAddr, fetched: time.Now()}
+	r.Unlock()
+	return authAddr, nil
+}
+
+// Describe returns the address, along with the address it's rekeyed to if it has been - for messages about which
+// keys are missing.
+func (r *RekeyAwareSigner) Describe(address string) string {
+	if authAddr, err := r.AuthAddr(address); err == nil && authAddr != address {
+		return fmt.Sprintf("%s (rekeyed to %s)", address, authAddr)
+	}
+	return address
+}
+
+func (r *RekeyAwareSigner) authAddr(address string) string {
+	authAddr, err := r.AuthAddr(address)
+	if err != nil {
+		misc.Warnf(r.log, "unable to determine auth address of account:%s, err:%v", address, err)
+	}
+	return authAddr
+}
+
+func (r *RekeyAwareSigner) HasAccount(publicAddress string) bool {
+	return r.signer.HasAccount(r.authAddr(publicAddress))
+}
+
+// FindFirstSigner returns the first of addresses whose authorizing address can be signed with.  The address
+// returned is that of the account (ie: the sender), not the address it's rekeyed to.
+func (r *RekeyAwareSigner) FindFirstSigner(addresses []string) (string, error) {
+	if len(addresses) == 0 {
+		return r.signer.FindFirstSigner(addresses)
+	}
+	authAddrs := make([]string, len(addresses))
+	for i, address := range addresses {
+		authAddrs[i] = r.authAddr(address)
+	}
+	authAddr, err := r.signer.FindFirstSigner(authAddrs)
+	if err != nil {
+		return "", err
+	}
+	for i, address := range addresses {
+		if authAddrs[i] == authAddr {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("no signer found for any of the addresses")
+}
+
+// SignWithAccount signs tx (sent by publicAddress) with the key of the authorizing address of publicAddress
+func (r *RekeyAwareSigner) SignWithAccount(ctx context.Context, tx types.Transaction, publicAddress string) (string, []byte, error) {
+	return r.signer.SignWithAccount(ctx, tx, r.authAddr(publicAddress))
+}
+
+func (r *RekeyAwareSigner) Close() error {
+	return CloseSigner(r.signer)
+}
+
+// DescribeSigningAccount returns the address, along with the address it's rekeyed to if signer is rekey aware and
+// it has been.
+func DescribeSigningAccount(signer MultipleWalletSigner, address string) string {
+	if describer, ok := signer.(interface{ Describe(address string) string }); ok {
+		return describer.Describe(address)
+	}
+	return address
+}
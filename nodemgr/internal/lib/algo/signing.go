@@ -9,7 +9,6 @@ import (
 	"log"
 	"log/slog"
 
-	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
 	"github.com/algorand/go-algorand-sdk/v2/transaction"
@@ -70,16 +69,38 @@ func decodeAndSignTransaction(signer MultipleWalletSigner, txnType string, msgPa
 	return txnid, bytes, nil
 }
 
-func sendAndWaitTxns(ctx context.Context, log *slog.Logger, algoClient *algod.Client, txnBytes []byte) (models.PendingTransactionInfoResponse, error) {
-	txid, err := algoClient.SendRawTransaction(txnBytes).Do(ctx)
+func sendAndWaitTxns(ctx context.Context, log *slog.Logger, chain Chain, txnBytes []byte) (models.PendingTransactionInfoResponse, error) {
+	txid, err := chain.SendRawTransaction(ctx, txnBytes)
 	if err != nil {
 		return models.PendingTransactionInfoResponse{}, fmt.Errorf("sendAndWaitTxns failed to send txns: %w", err)
 	}
 	log.Info("sendAndWaitTxns", "txid", txid)
-	resp, err := transaction.WaitForConfirmation(algoClient, txid, 100, ctx)
+	resp, err := WaitForConfirmation(ctx, chain, txid, 100)
 	if err != nil {
 		return models.PendingTransactionInfoResponse{}, fmt.Errorf("sendAndWaitTxns failure in confirmation wait: %w", err)
 	}
 	log.Info("sendAndWaitTxns", "confirmed-round", resp.ConfirmedRound)
 	return resp, nil
 }
+
+// SendPayment sends amount microAlgos from -> to (signed by signer) and waits for confirmation, returning the
+// transaction id and confirmed round.
+func SendPayment(ctx context.Context, log *slog.Logger, chain Chain, signer MultipleWalletSigner, from, to string, amount uint64, note string) (string, uint64, error) {
+	params, err := chain.SuggestedParams(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	paymentTxn, err := transaction.MakePaymentTxn(from, to, amount, []byte(note), "", params)
+	if err != nil {
+		return "", 0, err
+	}
+	txid, signedBytes, err := signer.SignWithAccount(ctx, paymentTxn, from)
+	if err != nil {
+		return "", 0, fmt.Errorf("error signing payment from:%s, error: %w", from, err)
+	}
+	resp, err := sendAndWaitTxns(ctx, log, chain, signedBytes)
+	if err != nil {
+		return txid, 0, err
+	}
+	return txid, resp.ConfirmedRound, nil
+}
@@ -0,0 +1,393 @@
+package algo
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+// createdAppsRefreshInterval is the minimum time between refetching the apps created by an app when asked about one
+// it doesn't (yet) include
+const createdAppsRefreshInterval = 1 * time.Minute
+
+// ErrPolicyViolation is returned when asked to sign a transaction the signing policy doesn't allow
+var ErrPolicyViolation = errors.New("signing policy violation")
+
+// SigningPolicy is what a policy signer will sign, per account - anything it doesn't allow is refused, including
+// every transaction of accounts it doesn't mention.  It's declared in a json file, ie:
+//
+//	{
+//	  "accounts": {
+//	    "MANAGER...": {
+//	      "apps": [2714516089],
+//	      "appsCreatedBy": [2714516089],
+//	      "methods": ["epochBalanceUpdate()void", "goOnline(pay,byte[],byte[],byte[],uint64,uint64,uint64)void"],
+//	      "maxFee": 100000,
+//	      "maxDailyFees": 10000000,
+//	      "maxPayment": 2000000,
+//	      "maxDailyPayments": 20000000
+//	    },
+//	    "TREASURY...": {
+//	      "receivers": ["MANAGER..."],
+//	      "maxFee": 1000,
+//	      "maxDailyFees": 10000,
+//	      "maxPayment": 10000000,
+//	      "maxDailyPayments": 50000000
+//	    }
+//	  }
+//	}
+//
+// All amounts are in microAlgos.
+type SigningPolicy struct {
+	Accounts map[string]AccountPolicy `json:"accounts"`
+}
+
+// AccountPolicy is what may be signed for a single account
+type AccountPolicy struct {
+	// Apps are the app ids which may be called
+	Apps []uint64 `json:"apps"`
+	// AppsCreatedBy are app ids whose (app) account created the other apps which may be called - ie: the validator
+	// registry, which creates every staking pool.
+	AppsCreatedBy []uint64 `json:"appsCreatedBy"`
+	// Methods, if set, are the only ABI methods which may be called - either method signatures or selectors (in hex)
+	Methods []string `json:"methods"`
+	// MaxFee is the largest fee of a single transaction
+	MaxFee uint64 `json:"maxFee"`
+	// MaxDailyFees is the most that may be spent on fees in a (UTC) day
+	MaxDailyFees uint64 `json:"maxDailyFees"`
+	// Receivers are the (non app) addresses which may be paid - ie: the manager account, funded by a treasury
+	Receivers []string `json:"receivers"`
+	// MaxPayment is the largest payment allowed (ie: the go online fee) - only to the receivers, or the address of
+	// an app which may be called.  No payments are allowed if 0.
+	MaxPayment uint64 `json:"maxPayment"`
+	// MaxDailyPayments is the most that may be paid in a (UTC) day
+	MaxDailyPayments uint64 `json:"maxDailyPayments"`
+
+	selectors [][]byte
+}
+
+// LoadSigningPolicy reads (and validates) the signing policy in the json file at path
+func LoadSigningPolicy(path string) (SigningPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return SigningPolicy{}, fmt.Errorf("unable to read signing policy: %w", err)
+	}
+	var policy SigningPolicy
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err = decoder.Decode(&policy); err != nil {
+		return SigningPolicy{}, fmt.Errorf("invalid signing policy %s: %w", path, err)
+	}
+	if len(policy.Accounts) == 0 {
+		return SigningPolicy{}, fmt.Errorf("signing policy %s has no accounts", path)
+	}
+	for account, accountPolicy := range policy.Accounts {
+		if _, err = types.DecodeAddress(account); err != nil {
+			return SigningPolicy{}, fmt.Errorf("signing policy has invalid account:%s, err:%w", account, err)
+		}
+		if accountPolicy.MaxFee == 0 || accountPolicy.MaxDailyFees == 0 {
+			return SigningPolicy{}, fmt.Errorf("signing policy for account:%s must set maxFee and maxDailyFees", account)
+		}
+		for _, receiver := range accountPolicy.Receivers {
+			if _, err = types.DecodeAddress(receiver); err != nil {
+				return SigningPolicy{}, fmt.Errorf("signing policy for account:%s has invalid receiver:%s, err:%w", account, receiver, err)
+			}
+		}
+		if accountPolicy.MaxPayment != 0 && accountPolicy.MaxDailyPayments == 0 {
+			return SigningPolicy{}, fmt.Errorf("signing policy for account:%s must set maxDailyPayments if payments are allowed", account)
+		}
+		for _, method := range accountPolicy.Methods {
+			selector, err := ParseSelector(method)
+			if err != nil {
+				return SigningPolicy{}, fmt.Errorf("signing policy for account:%s: %w", account, err)
+			}
+			accountPolicy.selectors = append(accountPolicy.selectors, selector)
+		}
+		policy.Accounts[account] = accountPolicy
+	}
+	return policy, nil
+}
+
+// ParseSelector returns the ABI selector of a method signature (ie: epochBalanceUpdate()void), or the selector
+// itself if given as 8 hex digits.
+func ParseSelector(method string) ([]byte, error) {
+	if selector, err := hex.DecodeString(strings.TrimPrefix(method, "0x")); err == nil && len(selector) == 4 {
+		return selector, nil
+	}
+	abiMethod, err := abi.MethodFromSignature(method)
+	if err != nil {
+		return nil, fmt.Errorf("invalid method signature:%s, err:%w", method, err)
+	}
+	return abiMethod.GetSelector(), nil
+}
+
+// SpendStore persists what a policy signer has signed for, so the daily limits still apply across restarts
+type SpendStore interface {
+	// PolicySpend returns the fees and payments signed for account on day (YYYY-MM-DD, UTC)
+	PolicySpend(account, day string) (fees, payments uint64, err error)
+	// SetPolicySpend records the fees and payments signed for account on day
+	SetPolicySpend(account, day string, fees, payments uint64) error
+}
+
+// NewPolicySigner returns a signer which only signs (w/ signer) what policy allows.  The apps created by the
+// AppsCreatedBy apps are fetched via accounts, and daily spending is kept in spends.
+func NewPolicySigner(log *slog.Logger, signer MultipleWalletSigner, policy SigningPolicy, accounts AccountReader, spends SpendStore) MultipleWalletSigner {
+	return &policySigner{
+		log:      log,
+		signer:   signer,
+		policy:   policy,
+		accounts: accounts,
+		spends:   spends,
+		created:  map[uint64]createdAppsEntry{},
+		spent:    map[string]dailySpend{},
+	}
+}
+
+type policySigner struct {
+	log      *slog.Logger
+	signer   MultipleWalletSigner
+	policy   SigningPolicy
+	accounts AccountReader
+	spends   SpendStore
+
+	sync.Mutex
+	// created caches the apps created by each AppsCreatedBy app
+	created map[uint64]createdAppsEntry
+	// spent caches (from spends) what's been signed for today, by account
+	spent map[string]dailySpend
+}
+
+type createdAppsEntry struct {
+	appIDs  []uint64
+	fetched time.Time
+}
+
+type dailySpend struct {
+	day      string
+	fees     uint64
+	payments uint64
+}
+
+func (p *policySigner) HasAccount(publicAddress string) bool {
+	return p.signer.HasAccount(publicAddress)
+}
+
+func (p *policySigner) FindFirstSigner(addresses []string) (string, error) {
+	return p.signer.FindFirstSigner(addresses)
+}
+
+func (p *policySigner) Describe(address string) string {
+	return DescribeSigningAccount(p.signer, address)
+}
+
+func (p *policySigner) Close() error {
+	return CloseSigner(p.signer)
+}
+
+// SignWithAccount signs tx if the policy for its sender allows it - and it's within the sender's daily limits.
+func (p *policySigner) SignWithAccount(ctx context.Context, tx types.Transaction, publicAddress string) (string, []byte, error) {
+	sender := tx.Sender.String()
+	describe := fmt.Sprintf("txid:%s, type:%s, sender:%s, fee:%s", crypto.GetTxID(tx), tx.Type, sender, FormattedAlgoAmount(uint64(tx.Fee)))
+	if tx.Type == types.ApplicationCallTx {
+		describe += fmt.Sprintf(", app id:%d", tx.ApplicationID)
+		if len(tx.ApplicationArgs) > 0 {
+			describe += fmt.Sprintf(", selector:%x", tx.ApplicationArgs[0])
+		}
+	} else if tx.Type == types.PaymentTx {
+		describe += fmt.Sprintf(", receiver:%s, amount:%s", tx.Receiver, FormattedAlgoAmount(uint64(tx.Amount)))
+	}
+	if err := p.check(ctx, tx); err != nil {
+		misc.Warnf(p.log, "REFUSED signing %s: %v", describe, err)
+		return "", nil, fmt.Errorf("%w: %s", ErrPolicyViolation, err.Error())
+	}
+	txid, signed, err := p.signer.SignWithAccount(ctx, tx, publicAddress)
+	if err != nil {
+		p.refund(tx)
+		return "", nil, err
+	}
+	return txid, signed, nil
+}
+
+// check returns an error describing why tx isn't allowed - if it is, what it spends is added to the sender's
+// daily totals.
+func (p *policySigner) check(ctx context.Context, tx types.Transaction) error {
+	sender := tx.Sender.String()
+	accountPolicy, found := p.policy.Accounts[sender]
+	if !found {
+		return errors.New("no signing policy for the account")
+	}
+	if !tx.RekeyTo.IsZero() {
+		return errors.New("rekeying isn't allowed")
+	}
+	if uint64(tx.Fee) > accountPolicy.MaxFee {
+		return fmt.Errorf("fee exceeds the maximum of %s ALGO", FormattedAlgoAmount(accountPolicy.MaxFee))
+	}
+	var payment uint64
+	switch tx.Type {
+	case types.ApplicationCallTx:
+		if tx.OnCompletion != types.NoOpOC {
+			return fmt.Errorf("on completion:%d isn't allowed", tx.OnCompletion)
+		}
+		allowed, err := p.appAllowed(ctx, accountPolicy, uint64(tx.ApplicationID))
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return fmt.Errorf("app id:%d isn't allowed", tx.ApplicationID)
+		}
+		if len(accountPolicy.selectors) > 0 {
+			if len(tx.ApplicationArgs) == 0 || !slices.ContainsFunc(accountPolicy.selectors, func(selector []byte) bool {
+				return bytes.Equal(selector, tx.ApplicationArgs[0])
+			}) {
+				return errors.New("method isn't allowed")
+			}
+		}
+	case types.PaymentTx:
+		if !tx.CloseRemainderTo.IsZero() {
+			return errors.New("closing an account isn't allowed")
+		}
+		allowed := slices.Contains(accountPolicy.Receivers, tx.Receiver.String())
+		if !allowed {
+			var err error
+			if allowed, err = p.appAddressAllowed(ctx, accountPolicy, tx.Receiver); err != nil {
+				return err
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("payment to:%s isn't allowed", tx.Receiver)
+		}
+		payment = uint64(tx.Amount)
+		if payment > accountPolicy.MaxPayment {
+			return fmt.Errorf("payment exceeds the maximum of %s ALGO", FormattedAlgoAmount(accountPolicy.MaxPayment))
+		}
+	default:
+		return fmt.Errorf("transaction type:%s isn't allowed", tx.Type)
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	spent, err := p.spentToday(sender)
+	if err != nil {
+		return err
+	}
+	if spent.fees+uint64(tx.Fee) > accountPolicy.MaxDailyFees {
+		return fmt.Errorf("daily fees would exceed the maximum of %s ALGO (already spent:%s)", FormattedAlgoAmount(accountPolicy.MaxDailyFees), FormattedAlgoAmount(spent.fees))
+	}
+	if spent.payments+payment > accountPolicy.MaxDailyPayments {
+		return fmt.Errorf("daily payments would exceed the maximum of %s ALGO (already paid:%s)", FormattedAlgoAmount(accountPolicy.MaxDailyPayments), FormattedAlgoAmount(spent.payments))
+	}
+	spent.fees += uint64(tx.Fee)
+	spent.payments += payment
+	return p.setSpent(sender, spent)
+}
+
+// refund removes what tx spends from its sender's daily totals (because it wasn't signed after all)
+func (p *policySigner) refund(tx types.Transaction) {
+	p.Lock()
+	defer p.Unlock()
+	spent, err := p.spentToday(tx.Sender.String())
+	if err != nil {
+		misc.Warnf(p.log, "unable to refund unsigned txid:%s from the daily totals, err:%v", crypto.GetTxID(tx), err)
+		return
+	}
+	spent.fees -= min(spent.fees, uint64(tx.Fee))
+	if tx.Type == types.PaymentTx {
+		spent.payments -= min(spent.payments, uint64(tx.Amount))
+	}
+	if err = p.setSpent(tx.Sender.String(), spent); err != nil {
+		misc.Warnf(p.log, "unable to refund unsigned txid:%s from the daily totals, err:%v", crypto.GetTxID(tx), err)
+	}
+}
+
+// spentToday returns what's been spent by account in the current (UTC) day - must be called w/ the lock held
+func (p *policySigner) spentToday(account string) (dailySpend, error) {
+	today := time.Now().UTC().Format(time.DateOnly)
+	if spent := p.spent[account]; spent.day == today {
+		return spent, nil
+	}
+	fees, payments, err := p.spends.PolicySpend(account, today)
+	if err != nil {
+		return dailySpend{}, fmt.Errorf("unable to load daily totals, err:%w", err)
+	}
+	return dailySpend{day: today, fees: fees, payments: payments}, nil
+}
+
+// setSpent records what's been spent by account - must be called w/ the lock held
+func (p *policySigner) setSpent(account string, spent dailySpend) error {
+	if err := p.spends.SetPolicySpend(account, spent.day, spent.fees, spent.payments); err != nil {
+		return fmt.Errorf("unable to save daily totals, err:%w", err)
+	}
+	p.spent[account] = spent
+	return nil
+}
+
+// appAllowed returns true if the account may call appID - it's either listed, or was created by a listed creator
+func (p *policySigner) appAllowed(ctx context.Context, accountPolicy AccountPolicy, appID uint64) (bool, error) {
+	return p.allowedApp(ctx, accountPolicy, func(allowedID uint64) bool { return allowedID == appID })
+}
+
+// appAddressAllowed returns true if address is the address of an app the account may call
+func (p *policySigner) appAddressAllowed(ctx context.Context, accountPolicy AccountPolicy, address types.Address) (bool, error) {
+	return p.allowedApp(ctx, accountPolicy, func(allowedID uint64) bool { return crypto.GetApplicationAddress(allowedID) == address })
+}
+
+// allowedApp returns true if any of the apps the account may call matches
+func (p *policySigner) allowedApp(ctx context.Context, accountPolicy AccountPolicy, matches func(appID uint64) bool) (bool, error) {
+	if slices.ContainsFunc(accountPolicy.Apps, matches) {
+		return true, nil
+	}
+	for _, creatorAppID := range accountPolicy.AppsCreatedBy {
+		created, err := p.createdApps(ctx, creatorAppID, false)
+		if err != nil {
+			return false, err
+		}
+		if !slices.ContainsFunc(created, matches) {
+			// it may have been created since we last looked (ie: a new staking pool)
+			if created, err = p.createdApps(ctx, creatorAppID, true); err != nil {
+				return false, err
+			}
+		}
+		if slices.ContainsFunc(created, matches) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// createdApps returns the apps created by the account of creatorAppID - cached, unless refresh is set (which is
+// limited to once a minute)
+func (p *policySigner) createdApps(ctx context.Context, creatorAppID uint64, refresh bool) ([]uint64, error) {
+	p.Lock()
+	cached, found := p.created[creatorAppID]
+	p.Unlock()
+	if found && (!refresh || time.Since(cached.fetched) < createdAppsRefreshInterval) {
+		return cached.appIDs, nil
+	}
+	account, err := p.accounts.AccountInformation(ctx, crypto.GetApplicationAddress(creatorAppID).String())
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch apps created by app id:%d, err:%w", creatorAppID, err)
+	}
+	entry := createdAppsEntry{fetched: time.Now()}
+	for _, app := range account.CreatedApps {
+		entry.appIDs = append(entry.appIDs, app.Id)
+	}
+	p.Lock()
+	p.created[creatorAppID] = entry
+	p.Unlock()
+	return entry.appIDs, nil
+}
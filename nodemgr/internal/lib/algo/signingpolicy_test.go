@@ -0,0 +1,300 @@
+package algo
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+const (
+	testRegistryAppID = 1000
+	testPoolAppID     = 1001
+)
+
+// testSpendStore is an in-memory SpendStore
+type testSpendStore struct {
+	spent map[string][2]uint64
+	err   error
+}
+
+func (s *testSpendStore) PolicySpend(account, day string) (uint64, uint64, error) {
+	spent := s.spent[day+"/"+account]
+	return spent[0], spent[1], s.err
+}
+
+func (s *testSpendStore) SetPolicySpend(account, day string, fees, payments uint64) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.spent[day+"/"+account] = [2]uint64{fees, payments}
+	return nil
+}
+
+// testSigner 'signs' everything - unless told to fail
+type testSigner struct {
+	fail   bool
+	signed int
+}
+
+func (s *testSigner) HasAccount(string) bool { return true }
+
+func (s *testSigner) FindFirstSigner(addresses []string) (string, error) { return addresses[0], nil }
+
+func (s *testSigner) SignWithAccount(_ context.Context, tx types.Transaction, _ string) (string, []byte, error) {
+	if s.fail {
+		return "", nil, errors.New("signer unavailable")
+	}
+	s.signed++
+	return crypto.GetTxID(tx), []byte{}, nil
+}
+
+type policySignerTest struct {
+	manager, treasury, stranger types.Address
+	ledger                      *FakeLedger
+	policy                      SigningPolicy
+	spends                      *testSpendStore
+	inner                       *testSigner
+	signer                      MultipleWalletSigner
+}
+
+func newPolicySignerTest(t *testing.T) *policySignerTest {
+	pt := &policySignerTest{
+		manager:  crypto.GenerateAccount().Address,
+		treasury: crypto.GenerateAccount().Address,
+		stranger: crypto.GenerateAccount().Address,
+		ledger:   NewFakeLedger(),
+		spends:   &testSpendStore{spent: map[string][2]uint64{}},
+		inner:    &testSigner{},
+	}
+	pt.ledger.SetAccount(models.Account{
+		Address:     crypto.GetApplicationAddress(testRegistryAppID).String(),
+		CreatedApps: []models.Application{{Id: testPoolAppID}},
+	})
+	goOnline, _ := ParseSelector("goOnline(pay,byte[],byte[],byte[],uint64,uint64,uint64)void")
+	policyJSON, _ := json.Marshal(map[string]any{
+		"accounts": map[string]any{
+			pt.manager.String(): map[string]any{
+				"apps":             []uint64{testRegistryAppID},
+				"appsCreatedBy":    []uint64{testRegistryAppID},
+				"methods":          []string{"epochBalanceUpdate()void", hex.EncodeToString(goOnline)},
+				"maxFee":           10_000,
+				"maxDailyFees":     25_000,
+				"maxPayment":       2e6,
+				"maxDailyPayments": 3e6,
+			},
+			pt.treasury.String(): map[string]any{
+				"receivers":        []string{pt.manager.String()},
+				"maxFee":           1000,
+				"maxDailyFees":     10_000,
+				"maxPayment":       10e6,
+				"maxDailyPayments": 15e6,
+			},
+		},
+	})
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, policyJSON, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	if pt.policy, err = LoadSigningPolicy(path); err != nil {
+		t.Fatalf("LoadSigningPolicy: %v", err)
+	}
+	pt.signer = pt.newSigner()
+	return pt
+}
+
+func (pt *policySignerTest) newSigner() MultipleWalletSigner {
+	return NewPolicySigner(slog.New(slog.NewTextHandler(io.Discard, nil)), pt.inner, pt.policy, pt.ledger, pt.spends)
+}
+
+func (pt *policySignerTest) sign(signer MultipleWalletSigner, tx types.Transaction) error {
+	_, _, err := signer.SignWithAccount(context.Background(), tx, tx.Sender.String())
+	return err
+}
+
+func appCallTxn(sender types.Address, appID uint64, method string, fee uint64) types.Transaction {
+	tx := types.Transaction{Type: types.ApplicationCallTx, Header: types.Header{Sender: sender, Fee: types.MicroAlgos(fee)}}
+	tx.ApplicationID = types.AppIndex(appID)
+	if method != "" {
+		selector, _ := ParseSelector(method)
+		tx.ApplicationArgs = [][]byte{selector}
+	}
+	return tx
+}
+
+func paymentTxn(sender, receiver types.Address, amount uint64, fee uint64) types.Transaction {
+	tx := types.Transaction{Type: types.PaymentTx, Header: types.Header{Sender: sender, Fee: types.MicroAlgos(fee)}}
+	tx.Receiver, tx.Amount = receiver, types.MicroAlgos(amount)
+	return tx
+}
+
+func TestPolicySigner(t *testing.T) {
+	const epochUpdate = "epochBalanceUpdate()void"
+	pt := newPolicySignerTest(t)
+	poolAddress := crypto.GetApplicationAddress(testPoolAppID)
+	tests := []struct {
+		name string
+		// before are signed first, and must be allowed
+		before  []types.Transaction
+		tx      types.Transaction
+		wantErr string // empty if tx should be signed
+	}{
+		{name: "listed app", tx: appCallTxn(pt.manager, testRegistryAppID, epochUpdate, 1000)},
+		{name: "app created by a listed creator", tx: appCallTxn(pt.manager, testPoolAppID, epochUpdate, 1000)},
+		{name: "method listed by selector", tx: appCallTxn(pt.manager, testPoolAppID, "goOnline(pay,byte[],byte[],byte[],uint64,uint64,uint64)void", 1000)},
+		{name: "app not allowed", tx: appCallTxn(pt.manager, 5000, epochUpdate, 1000), wantErr: "app id:5000 isn't allowed"},
+		{name: "method not allowed", tx: appCallTxn(pt.manager, testPoolAppID, "goOffline()void", 1000), wantErr: "method isn't allowed"},
+		{name: "no method", tx: appCallTxn(pt.manager, testPoolAppID, "", 1000), wantErr: "method isn't allowed"},
+		{
+			name: "only no-op calls",
+			tx: func() types.Transaction {
+				tx := appCallTxn(pt.manager, testPoolAppID, epochUpdate, 1000)
+				tx.OnCompletion = types.OptInOC
+				return tx
+			}(),
+			wantErr: "on completion:1 isn't allowed",
+		},
+		{name: "unknown sender", tx: appCallTxn(pt.stranger, testPoolAppID, epochUpdate, 1000), wantErr: "no signing policy for the account"},
+		{
+			name: "rekeying",
+			tx: func() types.Transaction {
+				tx := appCallTxn(pt.manager, testPoolAppID, epochUpdate, 1000)
+				tx.RekeyTo = pt.stranger
+				return tx
+			}(),
+			wantErr: "rekeying isn't allowed",
+		},
+		{name: "fee over the maximum", tx: appCallTxn(pt.manager, testPoolAppID, epochUpdate, 10_001), wantErr: "fee exceeds the maximum"},
+		{name: "payment to a callable app", tx: paymentTxn(pt.manager, poolAddress, 2e6, 1000)},
+		{name: "payment to a receiver", tx: paymentTxn(pt.treasury, pt.manager, 10e6, 1000)},
+		{name: "payment to anyone else", tx: paymentTxn(pt.treasury, pt.stranger, 1, 1000), wantErr: "payment to:" + pt.stranger.String() + " isn't allowed"},
+		{name: "payment over the maximum", tx: paymentTxn(pt.manager, poolAddress, 2e6+1, 1000), wantErr: "payment exceeds the maximum"},
+		{
+			name: "closing the account",
+			tx: func() types.Transaction {
+				tx := paymentTxn(pt.treasury, pt.manager, 1, 1000)
+				tx.CloseRemainderTo = pt.manager
+				return tx
+			}(),
+			wantErr: "closing an account isn't allowed",
+		},
+		{name: "other transaction types", tx: types.Transaction{Type: types.AssetTransferTx, Header: types.Header{Sender: pt.manager, Fee: 1000}}, wantErr: "transaction type:axfer isn't allowed"},
+		{
+			name:    "daily fees",
+			before:  []types.Transaction{appCallTxn(pt.manager, testPoolAppID, epochUpdate, 10_000), appCallTxn(pt.manager, testPoolAppID, epochUpdate, 10_000)},
+			tx:      appCallTxn(pt.manager, testPoolAppID, epochUpdate, 10_000),
+			wantErr: "daily fees would exceed the maximum",
+		},
+		{
+			name:    "daily payments",
+			before:  []types.Transaction{paymentTxn(pt.treasury, pt.manager, 10e6, 1000)},
+			tx:      paymentTxn(pt.treasury, pt.manager, 5e6+1, 1000),
+			wantErr: "daily payments would exceed the maximum",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// every case starts the day over
+			pt.spends.spent = map[string][2]uint64{}
+			signer := pt.newSigner()
+			for _, tx := range tt.before {
+				if err := pt.sign(signer, tx); err != nil {
+					t.Fatalf("signing prior txn: %v", err)
+				}
+			}
+			err := pt.sign(signer, tt.tx)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("refused: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrPolicyViolation) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err:%v, want a policy violation containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicySignerDailyTotals(t *testing.T) {
+	call := func(pt *policySignerTest) types.Transaction {
+		return appCallTxn(pt.manager, testPoolAppID, "epochBalanceUpdate()void", 10_000)
+	}
+
+	t.Run("unsigned transactions are refunded", func(t *testing.T) {
+		pt := newPolicySignerTest(t)
+		pt.inner.fail = true
+		if err := pt.sign(pt.signer, call(pt)); err == nil || errors.Is(err, ErrPolicyViolation) {
+			t.Fatalf("err:%v, want the signer's error", err)
+		}
+		pt.inner.fail = false
+		for i := range 2 {
+			if err := pt.sign(pt.signer, call(pt)); err != nil {
+				t.Fatalf("signing txn %d after a refund: %v", i, err)
+			}
+		}
+		if err := pt.sign(pt.signer, call(pt)); !errors.Is(err, ErrPolicyViolation) {
+			t.Errorf("err:%v, want the daily fee limit reached", err)
+		}
+	})
+
+	t.Run("totals survive a restart", func(t *testing.T) {
+		pt := newPolicySignerTest(t)
+		for range 2 {
+			if err := pt.sign(pt.signer, call(pt)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := pt.sign(pt.newSigner(), call(pt)); !errors.Is(err, ErrPolicyViolation) {
+			t.Errorf("err:%v, want the daily fee limit still reached by a new signer", err)
+		}
+	})
+
+	t.Run("refused if totals can't be stored", func(t *testing.T) {
+		pt := newPolicySignerTest(t)
+		pt.spends.err = errors.New("database unavailable")
+		if err := pt.sign(pt.signer, call(pt)); !errors.Is(err, ErrPolicyViolation) {
+			t.Errorf("err:%v, want a refusal", err)
+		}
+		if pt.inner.signed != 0 {
+			t.Errorf("signed %d transactions w/out tracking them", pt.inner.signed)
+		}
+	})
+}
+
+func TestPolicySignerCreatedAppsRefresh(t *testing.T) {
+	const newPoolAppID = testPoolAppID + 1
+	pt := newPolicySignerTest(t)
+	call := appCallTxn(pt.manager, newPoolAppID, "epochBalanceUpdate()void", 1000)
+	if err := pt.sign(pt.signer, call); !errors.Is(err, ErrPolicyViolation) {
+		t.Fatalf("err:%v, want the not yet created pool refused", err)
+	}
+
+	pt.ledger.SetAccount(models.Account{
+		Address:     crypto.GetApplicationAddress(testRegistryAppID).String(),
+		CreatedApps: []models.Application{{Id: testPoolAppID}, {Id: newPoolAppID}},
+	})
+	if err := pt.sign(pt.signer, call); !errors.Is(err, ErrPolicyViolation) {
+		t.Errorf("err:%v, want the created apps only refreshed once per %v", err, createdAppsRefreshInterval)
+	}
+
+	policySigner := pt.signer.(*policySigner)
+	entry := policySigner.created[testRegistryAppID]
+	entry.fetched = entry.fetched.Add(-createdAppsRefreshInterval - time.Second)
+	policySigner.created[testRegistryAppID] = entry
+	if err := pt.sign(pt.signer, call); err != nil {
+		t.Errorf("new pool refused after the created apps refresh: %v", err)
+	}
+}
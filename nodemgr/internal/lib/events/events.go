@@ -0,0 +1,256 @@
+// Package events is a small typed event bus used to notify operators (webhooks, chat, email) of things needing
+// their attention, instead of it only being logged.
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+// Severity of an event - sinks can be configured to only receive events at or above a given severity.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityCritical:
+		return "critical"
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// ParseSeverity parses a severity name (info, warning or critical)
+func ParseSeverity(value string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "info":
+		return SeverityInfo, nil
+	case "warning", "warn":
+		return SeverityWarning, nil
+	case "critical", "crit":
+		return SeverityCritical, nil
+	}
+	return 0, fmt.Errorf("unknown severity:%q, must be one of info, warning, critical", value)
+}
+
+// Type is the type of event
+type Type string
+
+const (
+	// TypePoolOffline is sent when a pool which was online is found to be offline, without the daemon taking it
+	// offline.
+	TypePoolOffline Type = "poolOffline"
+	// TypeKeyExpiring is sent when a pool's active participation key expires soon with no replacement key present.
+	TypeKeyExpiring Type = "keyExpiring"
+	// TypeEpochUpdateFailed is sent when an epoch update still fails after all retries.
+	TypeEpochUpdateFailed Type = "epochUpdateFailed"
+	// TypeManagerBalanceLow is sent when the manager account balance is below the configured threshold.
+	TypeManagerBalanceLow Type = "managerBalanceLow"
+	// TypeManagerToppedUp is sent when the manager account is automatically funded from the treasury account.
+	TypeManagerToppedUp Type = "managerToppedUp"
+	// TypeStakerEvicted is sent when a staker is removed from a pool for no longer meeting the gating criteria.
+	TypeStakerEvicted Type = "stakerEvicted"
+	// TypeManagerChanged is sent when the daemon restarts itself because the validator manager account changed.
+	TypeManagerChanged Type = "managerChanged"
+)
+
+// Event is a single notification
+type Event struct {
+	Time        time.Time `json:"time"`
+	Type        Type      `json:"type"`
+	Severity    Severity  `json:"severity"`
+	ValidatorId uint64    `json:"validatorId,omitempty"`
+	NodeNum     uint64    `json:"nodeNum,omitempty"`
+	PoolId      uint64    `json:"poolId,omitempty"`
+	Message     string    `json:"message"`
+	// Key identifies the specific condition the event is for (ie: the staker being evicted), so events of the same
+	// type but for different things aren't treated as duplicates.
+	Key string `json:"-"`
+}
+
+// Summary returns a one-line, human-readable description of the event
+func (e Event) Summary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%s] %s", strings.ToUpper(e.Severity.String()), e.Type)
+	if e.ValidatorId != 0 {
+		fmt.Fprintf(&sb, " validator:%d", e.ValidatorId)
+	}
+	if e.NodeNum != 0 {
+		fmt.Fprintf(&sb, " node:%d", e.NodeNum)
+	}
+	if e.PoolId != 0 {
+		fmt.Fprintf(&sb, " pool:%d", e.PoolId)
+	}
+	fmt.Fprintf(&sb, " - %s", e.Message)
+	return sb.String()
+}
+
+func (e Event) dedupKey() string {
+	return fmt.Sprintf("%s/%d/%s", e.Type, e.PoolId, e.Key)
+}
+
+// Sink is a destination for events
+type Sink interface {
+	// Name identifies the sink in logs and configuration (ie: slack, email)
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+const (
+	// events queued per sink before new events are dropped (if a sink is slow or down)
+	sinkQueueSize = 100
+	sendTimeout   = 30 * time.Second
+	sendRetries   = 3
+)
+
+// Bus delivers published events to each of its sinks.  Each sink has its own queue and delivery goroutine so a
+// slow or failing sink doesn't hold up the others (or the publisher).
+type Bus struct {
+	logger *slog.Logger
+	wg     sync.WaitGroup
+
+	sync.RWMutex
+	sinks  []*sinkQueue
+	closed bool
+}
+
+type sinkQueue struct {
+	sink        Sink
+	minSeverity Severity
+	dedupWindow time.Duration
+	events      chan Event
+	// when each (deduplication) key was last sent - only touched by the delivery goroutine
+	lastSent map[string]time.Time
+}
+
+func NewBus(logger *slog.Logger) *Bus {
+	return &Bus{logger: logger}
+}
+
+// AddSink adds a sink receiving events of at least minSeverity.  Duplicate events (same type, pool and key) are
+// only sent once per dedupWindow - 0 disables de-duplication.
+func (b *Bus) AddSink(sink Sink, minSeverity Severity, dedupWindow time.Duration) {
+	queue := &sinkQueue{
+		sink:        sink,
+		minSeverity: minSeverity,
+		dedupWindow: dedupWindow,
+		events:      make(chan Event, sinkQueueSize),
+		lastSent:    map[string]time.Time{},
+	}
+	b.Lock()
+	b.sinks = append(b.sinks, queue)
+	b.Unlock()
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.deliver(queue)
+	}()
+}
+
+// Publish queues event for delivery to all sinks interested in it.  It never blocks, and is a no-op on a nil Bus
+// so callers needn't care whether notifications are configured.
+func (b *Bus) Publish(event Event) {
+	if b == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	b.RLock()
+	defer b.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, queue := range b.sinks {
+		if event.Severity < queue.minSeverity {
+			continue
+		}
+		select {
+		case queue.events <- event:
+		default:
+			misc.Warnf(b.logger, "notification queue for %s is full, dropping event:%s", queue.sink.Name(), event.Summary())
+		}
+	}
+}
+
+// Close stops accepting events and waits (up to timeout) for queued events to be delivered.
+func (b *Bus) Close(timeout time.Duration) {
+	if b == nil {
+		return
+	}
+	b.Lock()
+	if b.closed {
+		b.Unlock()
+		return
+	}
+	b.closed = true
+	for _, queue := range b.sinks {
+		close(queue.events)
+	}
+	b.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		misc.Warnf(b.logger, "timed out waiting for notifications to be sent")
+	}
+}
+
+func (b *Bus) deliver(queue *sinkQueue) {
+	for event := range queue.events {
+		key := event.dedupKey()
+		if last, found := queue.lastSent[key]; found && queue.dedupWindow > 0 && event.Time.Sub(last) < queue.dedupWindow {
+			continue
+		}
+		var err error
+		for try := 1; try <= sendRetries; try++ {
+			ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+			err = queue.sink.Send(ctx, event)
+			cancel()
+			if err == nil {
+				break
+			}
+			if try < sendRetries {
+				time.Sleep(time.Duration(try) * 2 * time.Second)
+			}
+		}
+		if err != nil {
+			misc.Errorf(b.logger, "unable to send notification to %s, event:%s, err:%v", queue.sink.Name(), event.Summary(), err)
+			continue
+		}
+		queue.lastSent[key] = event.Time
+	}
+}
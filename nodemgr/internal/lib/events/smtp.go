@@ -0,0 +1,66 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTPSink emails events.  STARTTLS is used if the server supports it (which is required to authenticate, unless
+// the server is on localhost).
+type SMTPSink struct {
+	// Addr is the host:port of the mail server
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+func (s *SMTPSink) Name() string {
+	return "email"
+}
+
+func (s *SMTPSink) Send(ctx context.Context, event Event) error {
+	host, _, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid smtp server address:%q, err:%w", s.Addr, err)
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	msg := s.message(event)
+
+	// smtp.SendMail has no context support, so just stop waiting on it if the context expires
+	errc := make(chan error, 1)
+	go func() {
+		errc <- smtp.SendMail(s.Addr, auth, s.From, s.To, msg)
+	}()
+	select {
+	case err = <-errc:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *SMTPSink) message(event Event) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", s.From)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(s.To, ", "))
+	subject := fmt.Sprintf("Réti %s: %s", strings.ToUpper(event.Severity.String()), event.Type)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", event.Time.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	fmt.Fprintf(&buf, "%s\r\n\r\n", event.Summary())
+	fmt.Fprintf(&buf, "Time: %s\r\n", event.Time.Format(time.RFC3339))
+	return buf.Bytes()
+}
@@ -0,0 +1,73 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookFormat is the payload format posted to a webhook
+type WebhookFormat string
+
+const (
+	// FormatJSON posts the Event itself as json
+	FormatJSON WebhookFormat = "webhook"
+	// FormatSlack posts a Slack (incoming webhook) compatible message
+	FormatSlack WebhookFormat = "slack"
+	// FormatDiscord posts a Discord (webhook) compatible message
+	FormatDiscord WebhookFormat = "discord"
+)
+
+// WebhookSink posts events to a webhook url
+type WebhookSink struct {
+	url    string
+	format WebhookFormat
+	client *http.Client
+}
+
+func NewWebhookSink(url string, format WebhookFormat) *WebhookSink {
+	return &WebhookSink{
+		url:    url,
+		format: format,
+		client: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (w *WebhookSink) Name() string {
+	return string(w.format)
+}
+
+func (w *WebhookSink) Send(ctx context.Context, event Event) error {
+	var payload any
+	switch w.format {
+	case FormatSlack:
+		payload = map[string]string{"text": event.Summary()}
+	case FormatDiscord:
+		payload = map[string]string{"content": event.Summary()}
+	default:
+		payload = event
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webhook returned status:%d, body:%q", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
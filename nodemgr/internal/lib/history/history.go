@@ -0,0 +1,220 @@
+// Package history is a small embedded (bbolt) store of the actions taken against our validator and its pools, so
+// there's a local audit trail of what was done, when, and with what outcome.
+package history
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var actionsBucket = []byte("actions")
+
+// Action types recorded outside of contract calls (contract calls use the method name as their type)
+const (
+	TypeKeyCreate    = "keyCreate"
+	TypeKeyDelete    = "keyDelete"
+	TypeManagerTopUp = "managerTopUp"
+)
+
+// Action is a single recorded action
+type Action struct {
+	Time      time.Time `json:"time"`
+	Type      string    `json:"type"`
+	PoolId    uint64    `json:"poolId,omitempty"`
+	PoolAppId uint64    `json:"poolAppId,omitempty"`
+	Round     uint64    `json:"round,omitempty"`
+	TxIDs     []string  `json:"txIds,omitempty"`
+	Fees      uint64    `json:"fees,omitempty"`
+	// Amount is the amount (in microAlgos) sent, for payments (ie: manager top-ups)
+	Amount uint64 `json:"amount,omitempty"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+	// DryRun is true if the action was only simulated (daemon dry-run mode)
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// Succeeded returns true if the action completed w/out error
+func (a Action) Succeeded() bool {
+	return a.Error == ""
+}
+
+// Query filters the actions returned by Store.Query - zero values match everything
+type Query struct {
+	PoolId uint64
+	Type   string
+	Since  time.Time
+	Until  time.Time
+	// Limit is the maximum number of (most recent) actions to return
+	Limit int
+}
+
+// Store is the history database.  The database is only opened for the duration of each operation, so the daemon
+// can keep recording actions while the CLI is used to query them.
+type Store struct {
+	path string
+}
+
+// NewStore returns a store for the database at path, creating it if necessary
+func NewStore(path string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("unable to create history directory:%s, err:%w", dir, err)
+		}
+	}
+	s := &Store{path: path}
+	err := s.update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(actionsBucket)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Record adds action to the history - if action.Time isn't set, the current time is used
+func (s *Store) Record(action Action) error {
+	if action.Time.IsZero() {
+		action.Time = time.Now()
+	}
+	action.Time = action.Time.UTC()
+	data, err := json.Marshal(action)
+	if err != nil {
+		return err
+	}
+	return s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(actionsBucket)
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		return bucket.Put(actionKey(action.Time, seq), data)
+	})
+}
+
+// Query returns the actions matching q, oldest first
+func (s *Store) Query(q Query) ([]Action, error) {
+	var actions []Action
+	err := s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(actionsBucket)
+		if bucket == nil {
+			return nil
+		}
+		cursor := bucket.Cursor()
+		// walk backwards from the end of the range so we can stop once we have Limit actions
+		var key, value []byte
+		if q.Until.IsZero() {
+			key, value = cursor.Last()
+		} else if key, value = cursor.Seek(actionKey(q.Until, 0)); key == nil {
+			key, value = cursor.Last()
+		} else {
+			key, value = cursor.Prev()
+		}
+		for ; key != nil; key, value = cursor.Prev() {
+			var action Action
+			if err := json.Unmarshal(value, &action); err != nil {
+				return fmt.Errorf("invalid history record:%x, err:%w", key, err)
+			}
+			if !q.Since.IsZero() && action.Time.Before(q.Since) {
+				break
+			}
+			if (q.PoolId != 0 && action.PoolId != q.PoolId) || (q.Type != "" && action.Type != q.Type) {
+				continue
+			}
+			actions = append(actions, action)
+			if q.Limit != 0 && len(actions) >= q.Limit {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// we collected newest first
+	for i, j := 0, len(actions)-1; i < j; i, j = i+1, j-1 {
+		actions[i], actions[j] = actions[j], actions[i]
+	}
+	return actions, nil
+}
+
+// actionKey returns a key sorting by time, then by sequence (for multiple actions at the same time)
+func actionKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
+
+func (s *Store) update(fn func(tx *bolt.Tx) error) error {
+	db, err := s.open(false)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.Update(fn)
+}
+
+func (s *Store) view(fn func(tx *bolt.Tx) error) error {
+	if _, err := os.Stat(s.path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	db, err := s.open(true)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	return db.View(fn)
+}
+
+func (s *Store) open(readOnly bool) (*bolt.DB, error) {
+	db, err := bolt.Open(s.path, 0o600, &bolt.Options{Timeout: 10 * time.Second, ReadOnly: readOnly})
+	if err != nil {
+		return nil, fmt.Errorf("unable to open history database:%s, err:%w", s.path, err)
+	}
+	return db, nil
+}
+
+var policySpendBucket = []byte("policySpend")
+
+// PolicySpend returns the fees and payments signed for account on day (YYYY-MM-DD) - see algo.SpendStore
+func (s *Store) PolicySpend(account, day string) (fees, payments uint64, err error) {
+	err = s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(policySpendBucket)
+		if bucket == nil {
+			return nil
+		}
+		value := bucket.Get(policySpendKey(account, day))
+		if len(value) != 16 {
+			return nil
+		}
+		fees, payments = binary.BigEndian.Uint64(value), binary.BigEndian.Uint64(value[8:])
+		return nil
+	})
+	return fees, payments, err
+}
+
+// SetPolicySpend records the fees and payments signed for account on day (YYYY-MM-DD) - see algo.SpendStore
+func (s *Store) SetPolicySpend(account, day string, fees, payments uint64) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(policySpendBucket)
+		if err != nil {
+			return err
+		}
+		value := make([]byte, 16)
+		binary.BigEndian.PutUint64(value, fees)
+		binary.BigEndian.PutUint64(value[8:], payments)
+		return bucket.Put(policySpendKey(account, day), value)
+	})
+}
+
+func policySpendKey(account, day string) []byte {
+	return []byte(day + "/" + account)
+}
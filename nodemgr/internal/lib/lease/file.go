@@ -0,0 +1,129 @@
+package lease
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileBackend stores leases as small json files within a directory.  The directory is expected to be shared between
+// all the candidates (ie: a local directory for replicas on the same host, or a shared volume).  Updates to a lease
+// are serialized through an OS lock (flock, or LockFileEx on windows) on a lock file - which is released by the OS
+// if its holder dies, so a crashed candidate can never leave a lease locked.
+type FileBackend struct {
+	dir string
+}
+
+func NewFileBackend(dir string) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create lease directory:%s, err:%w", dir, err)
+	}
+	return &FileBackend{dir: dir}, nil
+}
+
+func (f *FileBackend) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	var acquired bool
+	err := f.withLock(ctx, name, func() error {
+		now := time.Now()
+		rec, err := f.read(name)
+		if err != nil {
+			return err
+		}
+		if rec.heldByOther(holder, now) {
+			return nil
+		}
+		acquired = true
+		return f.write(name, Record{Holder: holder, Expires: now.Add(ttl)})
+	})
+	if err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
+
+func (f *FileBackend) Release(ctx context.Context, name, holder string) error {
+	return f.withLock(ctx, name, func() error {
+		rec, err := f.read(name)
+		if err != nil {
+			return err
+		}
+		if rec.Holder != holder {
+			return nil
+		}
+		err = os.Remove(f.leasePath(name))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	})
+}
+
+func (f *FileBackend) leasePath(name string) string {
+	return filepath.Join(f.dir, name+".lease")
+}
+
+func (f *FileBackend) read(name string) (Record, error) {
+	var rec Record
+	data, err := os.ReadFile(f.leasePath(name))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return rec, nil
+		}
+		return rec, fmt.Errorf("unable to read lease file: %w", err)
+	}
+	if err = json.Unmarshal(data, &rec); err != nil {
+		// treat a corrupt lease as no lease at all - it'll be overwritten
+		return Record{}, nil
+	}
+	return rec, nil
+}
+
+// write replaces the lease file atomically (write to temp then rename) so readers never see partial data.
+func (f *FileBackend) write(name string, rec Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.dir, name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("unable to write lease file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("unable to write lease file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("unable to write lease file: %w", err)
+	}
+	return os.Rename(tmp.Name(), f.leasePath(name))
+}
+
+// withLock runs fn while holding the lock on the lock file of the named lease.  The lock file itself is never
+// removed, as that would let a waiter lock a file no one else will see.
+func (f *FileBackend) withLock(ctx context.Context, name string, fn func() error) error {
+	lockFile, err := os.OpenFile(filepath.Join(f.dir, name+".lock"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return fmt.Errorf("unable to open lease lock file: %w", err)
+	}
+	defer lockFile.Close()
+	for {
+		locked, err := tryLockFile(lockFile)
+		if err != nil {
+			return fmt.Errorf("unable to lock lease lock file: %w", err)
+		}
+		if locked {
+			defer unlockFile(lockFile)
+			return fn()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package lease
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// HTTPBackend talks to a remote lease service using a minimal json protocol:
+//
+//	PUT    {baseURL}/leases/{name}  body: {"holder":"x","ttlSeconds":30}
+//	       200 w/ Record body if holder now owns the lease, 409 if owned by someone else
+//	DELETE {baseURL}/leases/{name}?holder=x
+//	       204 (or 200) on success
+//
+// Any service implementing this protocol can be used - Server in this package is a simple in-memory implementation
+// that can be used as a local stand-in.
+type HTTPBackend struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+type acquireRequest struct {
+	Holder     string  `json:"holder"`
+	TTLSeconds float64 `json:"ttlSeconds"`
+}
+
+// NewHTTPBackend returns a Backend using the lease service at baseURL.  If token is non-empty it's sent as a bearer
+// token on every request.
+func NewHTTPBackend(baseURL string, token string) *HTTPBackend {
+	return &HTTPBackend{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (h *HTTPBackend) Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error) {
+	body, _ := json.Marshal(acquireRequest{Holder: holder, TTLSeconds: ttl.Seconds()})
+	resp, err := h.do(ctx, http.MethodPut, h.leaseURL(name), bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var rec Record
+		if err = json.NewDecoder(resp.Body).Decode(&rec); err != nil {
+			return false, fmt.Errorf("invalid lease response: %w", err)
+		}
+		return rec.Holder == holder, nil
+	case http.StatusConflict:
+		return false, nil
+	default:
+		return false, responseError(resp)
+	}
+}
+
+func (h *HTTPBackend) Release(ctx context.Context, name, holder string) error {
+	resp, err := h.do(ctx, http.MethodDelete, h.leaseURL(name)+"?holder="+url.QueryEscape(holder), nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return responseError(resp)
+	}
+	return nil
+}
+
+func (h *HTTPBackend) leaseURL(name string) string {
+	return fmt.Sprintf("%s/leases/%s", h.baseURL, url.PathEscape(name))
+}
+
+func (h *HTTPBackend) do(ctx context.Context, method, reqURL string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.token != "" {
+		req.Header.Set("Authorization", "Bearer "+h.token)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("lease service request failed: %w", err)
+	}
+	return resp, nil
+}
+
+func responseError(resp *http.Response) error {
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("lease service returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+}
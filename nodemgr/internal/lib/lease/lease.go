@@ -0,0 +1,27 @@
+package lease
+
+import (
+	"context"
+	"time"
+)
+
+// Backend is implemented by anything able to hand out a time-limited, exclusive lease on a named resource.
+// Only one holder can own a given lease at a time - holders are expected to call Acquire again before the ttl
+// expires to keep (renew) the lease.
+type Backend interface {
+	// Acquire tries to take the named lease for holder, for ttl duration.  If holder already owns the lease, its
+	// expiration is extended.  Returns true if holder owns the lease when the call returns.
+	Acquire(ctx context.Context, name, holder string, ttl time.Duration) (bool, error)
+	// Release gives up the named lease if (and only if) it's currently owned by holder.
+	Release(ctx context.Context, name, holder string) error
+}
+
+// Record is the persisted/transmitted state of a single lease.
+type Record struct {
+	Holder  string    `json:"holder"`
+	Expires time.Time `json:"expires"`
+}
+
+func (r Record) heldByOther(holder string, now time.Time) bool {
+	return r.Holder != "" && r.Holder != holder && now.Before(r.Expires)
+}
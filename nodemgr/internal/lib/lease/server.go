@@ -0,0 +1,74 @@
+package lease
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a minimal in-memory implementation of the lease service protocol used by HTTPBackend.
+// It's intended as a local stand-in (testing, or a single shared host) for a real lease/lock service.
+type Server struct {
+	token string
+
+	sync.Mutex
+	leases map[string]Record
+}
+
+// NewServer returns a lease service http.Handler.  If token is non-empty, requests must supply it as a bearer token.
+func NewServer(token string) *Server {
+	return &Server{token: token, leases: map[string]Record{}}
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" && r.Header.Get("Authorization") != "Bearer "+s.token {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	name, found := strings.CutPrefix(r.URL.Path, "/leases/")
+	if !found || name == "" {
+		http.NotFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var req acquireRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Holder == "" || req.TTLSeconds <= 0 {
+			http.Error(w, "invalid lease request", http.StatusBadRequest)
+			return
+		}
+		rec, ok := s.acquire(name, req.Holder, time.Duration(req.TTLSeconds*float64(time.Second)))
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			w.WriteHeader(http.StatusConflict)
+		}
+		_ = json.NewEncoder(w).Encode(rec)
+	case http.MethodDelete:
+		s.release(name, r.URL.Query().Get("holder"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) acquire(name, holder string, ttl time.Duration) (Record, bool) {
+	s.Lock()
+	defer s.Unlock()
+	now := time.Now()
+	if rec := s.leases[name]; rec.heldByOther(holder, now) {
+		return rec, false
+	}
+	rec := Record{Holder: holder, Expires: now.Add(ttl)}
+	s.leases[name] = rec
+	return rec, true
+}
+
+func (s *Server) release(name, holder string) {
+	s.Lock()
+	defer s.Unlock()
+	if s.leases[name].Holder == holder {
+		delete(s.leases, name)
+	}
+}
@@ -0,0 +1,107 @@
+package misc
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// scrypt parameters for newly encrypted files (~100ms to derive on current hardware)
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	// limits on the scrypt parameters read from files - so an edited file can't force a huge allocation or
+	// (practically) endless derivation
+	scryptMaxN      = 1 << 20
+	scryptMaxR      = 32
+	scryptMaxP      = 16
+	scryptMaxMemory = 256 << 20
+	scryptMinSalt   = 16
+)
+
+// ScryptParams are the parameters (and salt) a key is derived from a passphrase with, as stored alongside what it
+// encrypts.
+type ScryptParams struct {
+	Name string `json:"name,omitempty"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+// NewScryptParams returns the parameters (w/ a new random salt) for encrypting something new
+func NewScryptParams() (ScryptParams, error) {
+	params := ScryptParams{Name: "scrypt", Salt: make([]byte, 32), N: scryptN, R: scryptR, P: scryptP}
+	if _, err := rand.Read(params.Salt); err != nil {
+		return ScryptParams{}, err
+	}
+	return params, nil
+}
+
+// Validate checks the parameters are within sane bounds - they're read from files which could have been edited.
+func (s ScryptParams) Validate() error {
+	switch {
+	case s.Name != "" && s.Name != "scrypt":
+		return fmt.Errorf("unsupported kdf:%s", s.Name)
+	case len(s.Salt) < scryptMinSalt:
+		return fmt.Errorf("scrypt salt must be at least %d bytes", scryptMinSalt)
+	case s.N < 2 || s.N > scryptMaxN || s.N&(s.N-1) != 0:
+		return fmt.Errorf("invalid scrypt n:%d, must be a power of 2 no larger than %d", s.N, scryptMaxN)
+	case s.R < 1 || s.R > scryptMaxR:
+		return fmt.Errorf("invalid scrypt r:%d, must be between 1 and %d", s.R, scryptMaxR)
+	case s.P < 1 || s.P > scryptMaxP:
+		return fmt.Errorf("invalid scrypt p:%d, must be between 1 and %d", s.P, scryptMaxP)
+	case 128*s.N*s.R > scryptMaxMemory:
+		return fmt.Errorf("scrypt n:%d, r:%d would need more than %dMB", s.N, s.R, scryptMaxMemory>>20)
+	}
+	return nil
+}
+
+// DeriveKey derives the (XChaCha20-Poly1305) key from the passphrase.  The caller should clear it when done.
+func (s ScryptParams) DeriveKey(passphrase []byte) ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	key, err := scrypt.Key(passphrase, s.Salt, s.N, s.R, s.P, chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// Sealed is plaintext encrypted w/ XChaCha20-Poly1305 (see Seal)
+type Sealed struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Seal encrypts plaintext w/ key (see DeriveKey), authenticating additionalData along with it
+func Seal(key, plaintext, additionalData []byte) (Sealed, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return Sealed{}, err
+	}
+	sealed := Sealed{Nonce: make([]byte, aead.NonceSize())}
+	if _, err = rand.Read(sealed.Nonce); err != nil {
+		return Sealed{}, err
+	}
+	sealed.Ciphertext = aead.Seal(nil, sealed.Nonce, plaintext, additionalData)
+	return sealed, nil
+}
+
+// Unseal decrypts what Seal encrypted - failing if the key or additionalData don't match
+func Unseal(key []byte, sealed Sealed, additionalData []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid nonce size")
+	}
+	return aead.Open(nil, sealed.Nonce, sealed.Ciphertext, additionalData)
+}
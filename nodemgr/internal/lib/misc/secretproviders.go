@@ -0,0 +1,219 @@
+package misc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// NewFileSecretProvider returns a provider of the secrets in dir (ie: /run/secrets), as mounted by Docker or
+// Kubernetes - each file is a secret named by the file name, its contents (without trailing newlines) the value.
+func NewFileSecretProvider(dir string) SecretProvider {
+	return &fileSecretProvider{dir: dir}
+}
+
+type fileSecretProvider struct {
+	dir string
+}
+
+func (f *fileSecretProvider) Name() string {
+	return "file:" + f.dir
+}
+
+func (f *fileSecretProvider) Load(_ context.Context) (map[string]string, error) {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	secrets := map[string]string{}
+	for _, entry := range entries {
+		// kubernetes mounts have hidden (..data, etc.) entries the visible files are symlinked to
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(f.dir, entry.Name())
+		// stat (rather than the entry's type) so symlinks are followed
+		if info, err := os.Stat(path); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		value, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		secrets[entry.Name()] = strings.TrimRight(string(value), "\r\n")
+	}
+	return secrets, nil
+}
+
+// VaultConfig defines a secret in a Vault (compatible) KV version 2 secrets engine - every key of the secret is a
+// secret of ours.
+type VaultConfig struct {
+	// Addr is the vault server (ie: http://127.0.0.1:8200 for a local dev server)
+	Addr string
+	// Secret is the mount and path of the secret, ie: secret/reti
+	Secret string
+	// Token returns the vault token to use - it's called for every load so the token can itself be a (rotated)
+	// secret.
+	Token func() string
+}
+
+// NewVaultSecretProvider returns a provider of the secrets in a Vault KV v2 secret
+func NewVaultSecretProvider(config VaultConfig) (SecretProvider, error) {
+	mount, path, found := strings.Cut(strings.Trim(config.Secret, "/"), "/")
+	if !found || mount == "" || path == "" {
+		return nil, fmt.Errorf("vault secret must be of the form mount/path, not:%s", config.Secret)
+	}
+	if config.Addr == "" {
+		return nil, errors.New("vault address must be set")
+	}
+	return &vaultSecretProvider{
+		config:     config,
+		url:        fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(config.Addr, "/"), mount, path),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+type vaultSecretProvider struct {
+	config     VaultConfig
+	url        string
+	httpClient *http.Client
+}
+
+func (v *vaultSecretProvider) Name() string {
+	return fmt.Sprintf("vault:%s/%s", strings.TrimRight(v.config.Addr, "/"), strings.Trim(v.config.Secret, "/"))
+}
+
+// vaultKVResponse is the (relevant part of the) response to reading a KV v2 secret
+type vaultKVResponse struct {
+	Data struct {
+		Data map[string]any `json:"data"`
+	} `json:"data"`
+}
+
+func (v *vaultSecretProvider) Load(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if v.config.Token != nil {
+		req.Header.Set("X-Vault-Token", v.config.Token())
+	}
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("vault returned status:%d, %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var kvResp vaultKVResponse
+	if err = json.NewDecoder(resp.Body).Decode(&kvResp); err != nil {
+		return nil, fmt.Errorf("invalid vault response: %w", err)
+	}
+	secrets := map[string]string{}
+	for key, value := range kvResp.Data.Data {
+		if str, ok := value.(string); ok {
+			secrets[key] = str
+		} else {
+			secrets[key] = fmt.Sprint(value)
+		}
+	}
+	return secrets, nil
+}
+
+const secretsFileVersion = 1
+
+// encryptedSecretsFile is the on-disk (json) format of an encrypted secrets file - the secrets (as a json object)
+// are encrypted w/ XChaCha20-Poly1305 using a key derived from the passphrase via scrypt (see Seal).
+type encryptedSecretsFile struct {
+	Version int `json:"version"`
+	ScryptParams
+	Sealed
+}
+
+// WriteEncryptedSecrets writes the secrets to path, encrypted w/ passphrase
+func WriteEncryptedSecrets(path string, passphrase []byte, secrets map[string]string) error {
+	if len(passphrase) == 0 {
+		return errors.New("secrets file passphrase can't be empty")
+	}
+	kdf, err := NewScryptParams()
+	if err != nil {
+		return err
+	}
+	key, err := kdf.DeriveKey(passphrase)
+	if err != nil {
+		return err
+	}
+	defer clear(key)
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	defer clear(plaintext)
+	file := encryptedSecretsFile{Version: secretsFileVersion, ScryptParams: kdf}
+	if file.Sealed, err = Seal(key, plaintext, nil); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// NewEncryptedFileSecretProvider returns a provider of the secrets in an encrypted secrets file (see
+// WriteEncryptedSecrets).  The file is read (and decrypted) on every load, so it can be replaced while running.
+func NewEncryptedFileSecretProvider(path string, passphrase []byte) SecretProvider {
+	return &encryptedFileSecretProvider{path: path, passphrase: passphrase}
+}
+
+type encryptedFileSecretProvider struct {
+	path       string
+	passphrase []byte
+}
+
+func (e *encryptedFileSecretProvider) Name() string {
+	return "encrypted file:" + e.path
+}
+
+func (e *encryptedFileSecretProvider) Load(_ context.Context) (map[string]string, error) {
+	return ReadEncryptedSecrets(e.path, e.passphrase)
+}
+
+// ReadEncryptedSecrets reads and decrypts the secrets file at path
+func ReadEncryptedSecrets(path string, passphrase []byte) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file encryptedSecretsFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid secrets file %s: %w", path, err)
+	}
+	if file.Version != secretsFileVersion {
+		return nil, fmt.Errorf("unsupported secrets file version:%d", file.Version)
+	}
+	key, err := file.DeriveKey(passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("secrets file %s: %w", path, err)
+	}
+	defer clear(key)
+	plaintext, err := Unseal(key, file.Sealed, nil)
+	if err != nil {
+		return nil, errors.New("unable to decrypt secrets file - incorrect passphrase?")
+	}
+	defer clear(plaintext)
+	var secrets map[string]string
+	if err = json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, fmt.Errorf("invalid secrets in %s: %w", path, err)
+	}
+	return secrets, nil
+}
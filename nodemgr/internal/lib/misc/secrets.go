@@ -1,11 +1,41 @@
 package misc
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
 	"os"
 	"strings"
+	"sync"
+	"time"
 )
 
-var secretsMap = map[string]string{}
+// SecretProvider is a source of secrets (beyond environment variables, which always take precedence)
+type SecretProvider interface {
+	// Name describes the provider (ie: vault:https://vault:8200/secret/reti) - it's recorded as the source of every
+	// secret it supplies
+	Name() string
+	// Load fetches all the secrets the provider has
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// Secret is a secret value along with the provider it came from
+type Secret struct {
+	Value    string
+	Provider string
+	Loaded   time.Time
+}
+
+// envProviderName is the source of secrets read from environment variables
+const envProviderName = "env"
+
+var (
+	secretsLock      sync.RWMutex
+	secretsMap       = map[string]Secret{}
+	secretsProviders []SecretProvider
+)
 
 func SecretKeys() []string {
 	var uniqKeys = map[string]bool{}
@@ -13,11 +43,13 @@ func SecretKeys() []string {
 		key := envVal[0:strings.IndexByte(envVal, '=')]
 		uniqKeys[key] = true
 	}
-	for k, _ := range secretsMap {
+	secretsLock.RLock()
+	for k := range secretsMap {
 		uniqKeys[k] = true
 	}
+	secretsLock.RUnlock()
 	var retStrings []string
-	for k, _ := range uniqKeys {
+	for k := range uniqKeys {
 		retStrings = append(retStrings, k)
 	}
 	return retStrings
@@ -25,12 +57,128 @@ func SecretKeys() []string {
 
 // GetSecret retrieves the value of a secret identified by the given key.
 // If the secret is found in an environment variable, it returns the value.
-// Otherwise, it return the value secretsMap if found.
-// This abstraction is just an env abstraction at the moment but could easily
-// be changed to fetch secrets other ways if necessary.
+// Otherwise, it returns the value from the first secret provider (see InitSecrets) having it.
 func GetSecret(key string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
-	return secretsMap[key]
+	secretsLock.RLock()
+	defer secretsLock.RUnlock()
+	return secretsMap[key].Value
+}
+
+// SecretSource returns the name of the provider the secret came from ("env" for environment variables), or "" if
+// the secret isn't defined.
+func SecretSource(key string) string {
+	if os.Getenv(key) != "" {
+		return envProviderName
+	}
+	secretsLock.RLock()
+	defer secretsLock.RUnlock()
+	return secretsMap[key].Provider
+}
+
+// Secrets returns every secret from the providers (not including environment variables), keyed by name
+func Secrets() map[string]Secret {
+	secretsLock.RLock()
+	defer secretsLock.RUnlock()
+	return maps.Clone(secretsMap)
+}
+
+// InitSecrets loads the secrets of the providers, in order - if several providers have the same secret, the first
+// one's is used.  Any provider failing to load is an error.
+func InitSecrets(ctx context.Context, log *slog.Logger, providers ...SecretProvider) error {
+	secretsLock.Lock()
+	secretsProviders = providers
+	secretsLock.Unlock()
+	if err := RefreshSecrets(ctx, log); err != nil {
+		return err
+	}
+	if len(providers) > 0 {
+		Infof(log, "Loaded %d secrets from: %s", len(Secrets()), strings.Join(providerNames(providers), ", "))
+	}
+	return nil
+}
+
+// RefreshSecrets reloads the secrets of every provider - so rotated secrets (ie: the algod admin token) are picked
+// up.  If a provider fails to load, the secrets it previously supplied are kept.
+func RefreshSecrets(ctx context.Context, log *slog.Logger) error {
+	secretsLock.RLock()
+	providers := secretsProviders
+	previous := secretsMap
+	secretsLock.RUnlock()
+
+	var (
+		now     = time.Now()
+		updated = map[string]Secret{}
+		errs    []error
+	)
+	for _, provider := range providers {
+		secrets, err := provider.Load(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("secret provider %s: %w", provider.Name(), err))
+			// keep what we had from this provider
+			for key, secret := range previous {
+				if _, found := updated[key]; !found && secret.Provider == provider.Name() {
+					updated[key] = secret
+				}
+			}
+			continue
+		}
+		for key, value := range secrets {
+			if _, found := updated[key]; !found {
+				updated[key] = Secret{Value: value, Provider: provider.Name(), Loaded: now}
+			}
+		}
+	}
+	for key, secret := range updated {
+		prevSecret, found := previous[key]
+		switch {
+		case !found:
+			if len(previous) > 0 {
+				Infof(log, "secret %s added (from %s)", key, secret.Provider)
+			}
+		case prevSecret.Value != secret.Value:
+			Infof(log, "secret %s changed (from %s)", key, secret.Provider)
+		case prevSecret.Provider == secret.Provider:
+			// unchanged - so keep when it was first loaded
+			updated[key] = prevSecret
+		}
+	}
+	for key, prevSecret := range previous {
+		if _, found := updated[key]; !found {
+			Infof(log, "secret %s removed (was from %s)", key, prevSecret.Provider)
+		}
+	}
+	secretsLock.Lock()
+	secretsMap = updated
+	secretsLock.Unlock()
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to load secrets: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// RefreshSecretsEvery refreshes the secrets every interval until the context is cancelled
+func RefreshSecretsEvery(ctx context.Context, log *slog.Logger, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := RefreshSecrets(ctx, log); err != nil {
+				Warnf(log, "error refreshing secrets, err:%v", err)
+			}
+		}
+	}
+}
+
+func providerNames(providers []SecretProvider) []string {
+	var names []string
+	for _, provider := range providers {
+		names = append(names, provider.Name())
+	}
+	return names
 }
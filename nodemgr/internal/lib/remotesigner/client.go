@@ -0,0 +1,177 @@
+package remotesigner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+// accountsRefreshInterval is the minimum time between refetching the signer's accounts when asked about an account
+// we don't know about
+const accountsRefreshInterval = 1 * time.Minute
+
+// ClientConfig defines the signing service to use, and the client certificate to authenticate with
+type ClientConfig struct {
+	URL      string
+	CertFile string
+	KeyFile  string
+	// CAFile holds the ca certificate(s) the server's certificate must be signed by
+	CAFile string
+}
+
+// Client signs transactions via a remote signing service.  It's a MultipleWalletSigner for the accounts the service
+// signs for.
+type Client struct {
+	log        *slog.Logger
+	baseURL    string
+	httpClient *http.Client
+
+	sync.RWMutex
+	accounts    []string
+	lastRefresh time.Time
+}
+
+// NewClient returns a client for the signing service in config, fetching the accounts it signs for (which also
+// verifies it can be reached).
+func NewClient(ctx context.Context, log *slog.Logger, config ClientConfig) (*Client, error) {
+	if !strings.HasPrefix(config.URL, "https://") {
+		return nil, fmt.Errorf("remote signer url must be https, not:%s", config.URL)
+	}
+	tlsConfig, caPool, err := loadTLSConfig(config.CertFile, config.KeyFile, config.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.RootCAs = caPool
+	client := &Client{
+		log:     log,
+		baseURL: strings.TrimRight(config.URL, "/"),
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+	}
+	if err = client.refreshAccounts(ctx); err != nil {
+		return nil, err
+	}
+	misc.Infof(log, "Signing with remote signer at:%s, %d accounts", client.baseURL, len(client.accounts))
+	return client, nil
+}
+
+// call makes a request of the signing service, decoding the json response into response
+func (c *Client) call(ctx context.Context, method, path string, request any, response any) error {
+	var body io.Reader
+	if request != nil {
+		reqBody, err := json.Marshal(request)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(reqBody)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("remote signer request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var errResp ErrorResponse
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&errResp)
+		return fmt.Errorf("remote signer returned status:%d, error:%s", resp.StatusCode, errResp.Error)
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// refreshAccounts refetches the accounts the service signs for
+func (c *Client) refreshAccounts(ctx context.Context) error {
+	var resp AccountsResponse
+	err := c.call(ctx, http.MethodGet, accountsPath, nil, &resp)
+	c.Lock()
+	defer c.Unlock()
+	c.lastRefresh = time.Now()
+	if err != nil {
+		return err
+	}
+	c.accounts = resp.Accounts
+	return nil
+}
+
+func (c *Client) HasAccount(publicAddress string) bool {
+	c.RLock()
+	found, stale := slices.Contains(c.accounts, publicAddress), time.Since(c.lastRefresh) > accountsRefreshInterval
+	c.RUnlock()
+	if found || !stale {
+		return found
+	}
+	if err := c.refreshAccounts(context.Background()); err != nil {
+		misc.Warnf(c.log, "unable to refresh remote signer accounts, err:%v", err)
+		return false
+	}
+	c.RLock()
+	defer c.RUnlock()
+	return slices.Contains(c.accounts, publicAddress)
+}
+
+// FindFirstSigner returns the first of addresses the service signs for - or the first account it signs for if
+// addresses is empty.
+func (c *Client) FindFirstSigner(addresses []string) (string, error) {
+	if len(addresses) == 0 {
+		c.RLock()
+		defer c.RUnlock()
+		if len(c.accounts) > 0 {
+			return c.accounts[0], nil
+		}
+	}
+	for _, address := range addresses {
+		if c.HasAccount(address) {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("no signer found for any of the addresses")
+}
+
+// SignWithAccount has the service sign tx with the key of publicAddress.  The signed transaction returned is
+// verified to be tx, validly signed by publicAddress, so a misbehaving service can't substitute anything else.
+func (c *Client) SignWithAccount(ctx context.Context, tx types.Transaction, publicAddress string) (string, []byte, error) {
+	signerAddress, err := types.DecodeAddress(publicAddress)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid signing address:%s, error:%w", publicAddress, err)
+	}
+	var resp SignResponse
+	if err = c.call(ctx, http.MethodPost, signPath, SignRequest{Signer: publicAddress, Txn: msgpack.Encode(tx)}, &resp); err != nil {
+		return "", nil, err
+	}
+	var signed types.SignedTxn
+	if err = msgpack.Decode(resp.SignedTxn, &signed); err != nil {
+		return "", nil, fmt.Errorf("invalid signed transaction from remote signer: %w", err)
+	}
+	txid := crypto.GetTxID(tx)
+	if crypto.GetTxID(signed.Txn) != txid {
+		return "", nil, errors.New("remote signer returned a different transaction than the one sent")
+	}
+	// transactions are signed w/ a "TX" prefix, as with txids
+	if !ed25519.Verify(signerAddress[:], append([]byte("TX"), msgpack.Encode(tx)...), signed.Sig[:]) {
+		return "", nil, fmt.Errorf("remote signer returned an invalid signature for account:%s", publicAddress)
+	}
+	return txid, resp.SignedTxn, nil
+}
@@ -0,0 +1,65 @@
+// Package remotesigner lets transactions be signed by a separate (hardened) host so keys needn't live on the node
+// host.  The client is a MultipleWalletSigner sending transactions to the signing service over HTTPS w/ mutual TLS,
+// and the server is a reference implementation of that service, which only signs transactions its policy allows.
+package remotesigner
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	accountsPath = "/v1/accounts"
+	signPath     = "/v1/sign"
+)
+
+// AccountsResponse is the response to GET /v1/accounts
+type AccountsResponse struct {
+	Accounts []string `json:"accounts"`
+}
+
+// SignRequest is the body of POST /v1/sign
+type SignRequest struct {
+	// Signer is the account to sign with - normally the sender, unless the sender was rekeyed to it
+	Signer string `json:"signer"`
+	// Txn is the msgpack encoded transaction
+	Txn []byte `json:"txn"`
+}
+
+// SignResponse is the response to POST /v1/sign
+type SignResponse struct {
+	// SignedTxn is the msgpack encoded signed transaction
+	SignedTxn []byte `json:"signedTxn"`
+}
+
+// ErrorResponse is the body of any non-200 response
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// loadTLSConfig returns a tls config presenting the certFile/keyFile certificate, and trusting caFile certificates
+// (for verifying the other side).
+func loadTLSConfig(certFile, keyFile, caFile string) (*tls.Config, *x509.CertPool, error) {
+	if certFile == "" || keyFile == "" || caFile == "" {
+		return nil, nil, errors.New("certificate, key and ca files must all be set for mutual tls")
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to load certificate: %w", err)
+	}
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read ca file: %w", err)
+	}
+	caPool := x509.NewCertPool()
+	if !caPool.AppendCertsFromPEM(caPEM) {
+		return nil, nil, fmt.Errorf("no certificates found in ca file:%s", caFile)
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, caPool, nil
+}
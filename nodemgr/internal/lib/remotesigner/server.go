@@ -0,0 +1,136 @@
+package remotesigner
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+// Server is the reference signing service - signing with the accounts of signer, but only what the signing policy
+// allows.  Every request is logged.
+type Server struct {
+	log *slog.Logger
+	// signer is the policy signer - refusing anything the policy doesn't allow
+	signer algo.MultipleWalletSigner
+	// accounts is the (fixed) set of accounts we sign for
+	accounts []string
+}
+
+// NewServer returns a server signing for accounts (which signer must have the keys for) - only what policy allows.
+// The apps created by the policy's appsCreatedBy apps are fetched via chain, and daily spending is kept in spends.
+func NewServer(log *slog.Logger, signer algo.MultipleWalletSigner, accounts []string, policy algo.SigningPolicy, chain algo.AccountReader, spends algo.SpendStore) (*Server, error) {
+	for _, account := range accounts {
+		if !signer.HasAccount(account) {
+			return nil, fmt.Errorf("no key available for account:%s", account)
+		}
+		if _, found := policy.Accounts[account]; !found {
+			return nil, fmt.Errorf("no signing policy for account:%s", account)
+		}
+	}
+	return &Server{log: log, signer: algo.NewPolicySigner(log, signer, policy, chain, spends), accounts: accounts}, nil
+}
+
+// ServeTLS serves the signing api on listenAddr (until the context is cancelled), requiring clients present a
+// certificate signed by a clientCAFile certificate.
+func (s *Server) ServeTLS(ctx context.Context, listenAddr, certFile, keyFile, clientCAFile string) error {
+	tlsConfig, caPool, err := loadTLSConfig(certFile, keyFile, clientCAFile)
+	if err != nil {
+		return err
+	}
+	tlsConfig.ClientCAs = caPool
+	tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+accountsPath, s.handleAccounts)
+	mux.HandleFunc("POST "+signPath, s.handleSign)
+	server := &http.Server{
+		Addr:              listenAddr,
+		Handler:           mux,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	misc.Infof(s.log, "Remote signer listening on %s, signing for:%v", listenAddr, s.accounts)
+	if err = server.ListenAndServeTLS("", ""); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// clientName returns the common name of the client's certificate, for logging
+func clientName(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return r.RemoteAddr
+	}
+	return fmt.Sprintf("%s (%s)", r.TLS.PeerCertificates[0].Subject.CommonName, r.RemoteAddr)
+}
+
+func (s *Server) handleAccounts(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, AccountsResponse{Accounts: s.accounts})
+}
+
+func (s *Server) handleSign(w http.ResponseWriter, r *http.Request) {
+	var request SignRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024)).Decode(&request); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid request"})
+		return
+	}
+	var tx types.Transaction
+	if err := msgpack.Decode(request.Txn, &tx); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "invalid transaction"})
+		return
+	}
+	txid := crypto.GetTxID(tx)
+	describe := fmt.Sprintf("client:%s, signer:%s, txid:%s, type:%s, sender:%s", clientName(r), request.Signer, txid, tx.Type, tx.Sender)
+	if tx.Type == types.ApplicationCallTx {
+		describe += fmt.Sprintf(", app id:%d", tx.ApplicationID)
+		if len(tx.ApplicationArgs) > 0 {
+			describe += fmt.Sprintf(", selector:%x", tx.ApplicationArgs[0])
+		}
+	} else if tx.Type == types.PaymentTx {
+		describe += fmt.Sprintf(", receiver:%s, amount:%s", tx.Receiver, algo.FormattedAlgoAmount(uint64(tx.Amount)))
+	}
+
+	if !slices.Contains(s.accounts, request.Signer) {
+		misc.Warnf(s.log, "REFUSED sign request, %s: not an account we sign for", describe)
+		writeJSON(w, http.StatusForbidden, ErrorResponse{Error: fmt.Sprintf("not signing for account:%s", request.Signer)})
+		return
+	}
+	_, signed, err := s.signer.SignWithAccount(r.Context(), tx, request.Signer)
+	if errors.Is(err, algo.ErrPolicyViolation) {
+		misc.Warnf(s.log, "REFUSED sign request, %s: %v", describe, err)
+		writeJSON(w, http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err != nil {
+		misc.Errorf(s.log, "FAILED sign request, %s: %v", describe, err)
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: "signing failed"})
+		return
+	}
+	misc.Infof(s.log, "SIGNED %s", describe)
+	writeJSON(w, http.StatusOK, SignResponse{SignedTxn: signed})
+}
+
+func writeJSON(w http.ResponseWriter, status int, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,60 @@
+// Package contracts is a typed client for the réti ValidatorRegistry and StakingPool contracts.  The clients and
+// structs are generated from the ARC-32 application specs of the contracts, along with structs.json, which names
+// the structs the ABI tuples are generated as (ARC-32 specs don't).
+package contracts
+
+//go:generate go run ../../../tools/arc32gen -structs structs.json -out . ../artifacts/contracts/ValidatorRegistry.arc32.json ../artifacts/contracts/StakingPool.arc32.json
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+)
+
+// CallOpts are the parts of a method call which aren't specific to the method
+type CallOpts struct {
+	Sender          types.Address
+	Signer          transaction.TransactionSigner
+	SuggestedParams types.SuggestedParams
+}
+
+func (o CallOpts) methodCall(appID uint64, method abi.Method, args ...any) transaction.AddMethodCallParams {
+	return transaction.AddMethodCallParams{
+		AppID:           appID,
+		Method:          method,
+		MethodArgs:      args,
+		SuggestedParams: o.SuggestedParams,
+		OnComplete:      types.NoOpOC,
+		Sender:          o.Sender,
+		Signer:          o.Signer,
+	}
+}
+
+func mustMethod(signature string) abi.Method {
+	method, err := abi.MethodFromSignature(signature)
+	if err != nil {
+		panic(fmt.Sprintf("invalid method signature:%s, err:%v", signature, err))
+	}
+	return method
+}
+
+// Simulate simulates a single method call (unsigned, so the sender needn't be an account we have keys for),
+// returning its result.  This is how read-only methods are called.
+func Simulate(ctx context.Context, submitter algo.TxnSubmitter, params transaction.AddMethodCallParams) (transaction.ABIMethodResult, error) {
+	comp := algo.Composer{}
+	comp.AddMethodCall(params)
+	result, err := comp.Simulate(ctx, submitter, models.SimulateRequest{AllowUnnamedResources: true})
+	if err != nil {
+		return transaction.ABIMethodResult{}, err
+	}
+	if failure := result.SimulateResponse.TxnGroups[0].FailureMessage; failure != "" {
+		return transaction.ABIMethodResult{}, fmt.Errorf("%s failed: %s", params.Method.Name, failure)
+	}
+	return result.MethodResults[0], nil
+}
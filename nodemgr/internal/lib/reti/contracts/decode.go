@@ -0,0 +1,113 @@
+package contracts
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// The sdk decodes ABI values into untyped values (tuples and arrays as []any, etc.) - these convert them to our
+// types, returning errors rather than panicking if the value isn't what's expected.
+
+func decodeResult[T any](result transaction.ABIMethodResult, decode func(any) (T, error)) (T, error) {
+	if result.DecodeError != nil {
+		var empty T
+		return empty, fmt.Errorf("%s: unable to decode return value: %w", result.Method.Name, result.DecodeError)
+	}
+	value, err := decode(result.ReturnValue)
+	if err != nil {
+		return value, fmt.Errorf("%s: unexpected return value: %w", result.Method.Name, err)
+	}
+	return value, nil
+}
+
+func decodeAs[T any](value any) (T, error) {
+	typed, ok := value.(T)
+	if !ok {
+		return typed, fmt.Errorf("expected %T, got %T", typed, value)
+	}
+	return typed, nil
+}
+
+func decodeUint8(value any) (uint8, error)     { return decodeAs[uint8](value) }
+func decodeUint16(value any) (uint16, error)   { return decodeAs[uint16](value) }
+func decodeUint32(value any) (uint32, error)   { return decodeAs[uint32](value) }
+func decodeUint64(value any) (uint64, error)   { return decodeAs[uint64](value) }
+func decodeBool(value any) (bool, error)       { return decodeAs[bool](value) }
+func decodeByte(value any) (byte, error)       { return decodeAs[byte](value) }
+func decodeString(value any) (string, error)   { return decodeAs[string](value) }
+func decodeBigInt(value any) (*big.Int, error) { return decodeAs[*big.Int](value) }
+
+func decodeBytes(value any) ([]byte, error) {
+	switch typed := value.(type) {
+	case []byte:
+		return typed, nil
+	case []any:
+		return decodeSlice(typed, decodeByte)
+	}
+	return nil, fmt.Errorf("expected bytes, got %T", value)
+}
+
+func decodeAddress(value any) (types.Address, error) {
+	var addr types.Address
+	addrBytes, err := decodeBytes(value)
+	if err != nil {
+		return addr, err
+	}
+	if len(addrBytes) != len(addr) {
+		return addr, fmt.Errorf("expected %d byte address, got %d bytes", len(addr), len(addrBytes))
+	}
+	copy(addr[:], addrBytes)
+	return addr, nil
+}
+
+func decodeTuple(value any, numFields int) ([]any, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected tuple, got %T", value)
+	}
+	if len(values) != numFields {
+		return nil, fmt.Errorf("expected %d fields, got %d", numFields, len(values))
+	}
+	return values, nil
+}
+
+// decodeArray decodes a static array into dst, which must be the length of the array
+func decodeArray[T any](value any, dst []T, decode func(any) (T, error)) error {
+	values, ok := value.([]any)
+	if !ok {
+		return fmt.Errorf("expected array, got %T", value)
+	}
+	if len(values) != len(dst) {
+		return fmt.Errorf("expected %d elements, got %d", len(dst), len(values))
+	}
+	var err error
+	for i, elem := range values {
+		if dst[i], err = decode(elem); err != nil {
+			return fmt.Errorf("[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func decodeSlice[T any](value any, decode func(any) (T, error)) ([]T, error) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected array, got %T", value)
+	}
+	slice := make([]T, len(values))
+	if err := decodeArray(values, slice, decode); err != nil {
+		return nil, err
+	}
+	return slice, nil
+}
+
+func encodeArray[T any](values []T, encode func(T) any) []any {
+	encoded := make([]any, len(values))
+	for i, value := range values {
+		encoded[i] = encode(value)
+	}
+	return encoded
+}
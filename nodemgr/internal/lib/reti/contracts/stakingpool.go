@@ -0,0 +1,175 @@
+// Code generated by arc32gen from StakingPool.arc32.json. DO NOT EDIT.
+
+package contracts
+
+import (
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// StakingPool is a typed client for the methods of the StakingPool contract.
+// Each method returns the params for calling it (to add to a transaction group) - methods with a return value
+// have a matching Result method to decode it.
+type StakingPool struct {
+	AppID uint64
+}
+
+var (
+	methodStakingPoolCreateApplication          = mustMethod("createApplication(uint64,uint64,uint64,uint64)void")
+	methodStakingPoolGas                        = mustMethod("gas()void")
+	methodStakingPoolInitStorage                = mustMethod("initStorage(pay)void")
+	methodStakingPoolAddStake                   = mustMethod("addStake(pay,address)uint64")
+	methodStakingPoolRemoveStake                = mustMethod("removeStake(address,uint64)void")
+	methodStakingPoolClaimTokens                = mustMethod("claimTokens()void")
+	methodStakingPoolGetStakerInfo              = mustMethod("getStakerInfo(address)(address,uint64,uint64,uint64,uint64)")
+	methodStakingPoolPayTokenReward             = mustMethod("payTokenReward(address,uint64,uint64)void")
+	methodStakingPoolUpdateAlgodVer             = mustMethod("updateAlgodVer(string)void")
+	methodStakingPoolEpochBalanceUpdate         = mustMethod("epochBalanceUpdate()void")
+	methodStakingPoolGoOnline                   = mustMethod("goOnline(pay,byte[],byte[],byte[],uint64,uint64,uint64)void")
+	methodStakingPoolGoOffline                  = mustMethod("goOffline()void")
+	methodStakingPoolLinkToNFD                  = mustMethod("linkToNFD(uint64,string)void")
+	methodStakingPoolProxiedSetTokenPayoutRatio = mustMethod("proxiedSetTokenPayoutRatio((uint64,uint64,uint64))(uint64[24],uint64)")
+)
+
+// CreateApplication returns the params for calling createApplication(uint64,uint64,uint64,uint64)void.
+//
+// Initialize the staking pool w/ owner and manager, but can only be created by the validator contract.
+func (c StakingPool) CreateApplication(opts CallOpts, creatingContractId uint64, validatorId uint64, poolId uint64, minEntryStake uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolCreateApplication, creatingContractId, validatorId, poolId, minEntryStake)
+}
+
+// Gas returns the params for calling gas()void.
+//
+// gas is a dummy no-op call that can be used to pool-up resource references and opcode cost
+func (c StakingPool) Gas(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolGas)
+}
+
+// InitStorage returns the params for calling initStorage(pay)void.
+//
+// Called after we're created and then funded, so we can create our large stakers ledger storage
+// Caller has to get MBR amounts from ValidatorRegistry to know how much to fund us to cover the box storage cost
+// If this is pool 1 AND the validator has specified a reward token, opt-in to that token
+// so that the validator can seed the pool with future rewards of that token.
+func (c StakingPool) InitStorage(opts CallOpts, mbrPayment transaction.TransactionWithSigner) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolInitStorage, mbrPayment)
+}
+
+// AddStake returns the params for calling addStake(pay,address)uint64.
+//
+// Adds stake to the given account.
+// Can ONLY be called by the validator contract that created us
+// Must receive payment from the validator contract for amount being staked.
+func (c StakingPool) AddStake(opts CallOpts, stakedAmountPayment transaction.TransactionWithSigner, staker types.Address) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolAddStake, stakedAmountPayment, staker)
+}
+
+// AddStakeResult decodes the return value of a addStake call.
+//
+// uint64 new 'entry round' round number of stake add
+func (c StakingPool) AddStakeResult(result transaction.ABIMethodResult) (uint64, error) {
+	return decodeResult(result, decodeUint64)
+}
+
+// RemoveStake returns the params for calling removeStake(address,uint64)void.
+//
+// Removes stake on behalf of caller (removing own stake).  If any token rewards exist, those are always sent in
+// full. Also notifies the validator contract for this pools validator of the staker / balance changes.
+func (c StakingPool) RemoveStake(opts CallOpts, staker types.Address, amountToUnstake uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolRemoveStake, staker, amountToUnstake)
+}
+
+// ClaimTokens returns the params for calling claimTokens()void.
+//
+// Claims all the available reward tokens a staker has available, sending their entire balance to the staker from
+// pool 1 (either directly, or via validator-pool1 to pay it out)
+// Also notifies the validator contract for this pools validator of the staker / balance changes.
+func (c StakingPool) ClaimTokens(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolClaimTokens)
+}
+
+// GetStakerInfo returns the params for calling getStakerInfo(address)(address,uint64,uint64,uint64,uint64).
+//
+// Retrieves the staked information for a given staker.
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c StakingPool) GetStakerInfo(opts CallOpts, staker types.Address) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolGetStakerInfo, staker)
+}
+
+// GetStakerInfoResult decodes the return value of a getStakerInfo call.
+//
+// StakedInfo - The staked information for the given staker.
+func (c StakingPool) GetStakerInfoResult(result transaction.ABIMethodResult) (StakedInfo, error) {
+	return decodeResult(result, decodeStakedInfo)
+}
+
+// PayTokenReward returns the params for calling payTokenReward(address,uint64,uint64)void.
+//
+// [Internal protocol method] Remove a specified amount of 'community token' rewards for a staker.
+// This can ONLY be called by our validator and only if we're pool 1 - with the token.
+// Note: this can also be called by validator as part of OWNER wanting to send the reward tokens
+// somewhere else (ie if they're sunsetting their validator and need the reward tokens back).
+// It's up to the validator to ensure that the balance in rewardTokenHeldBack is honored.
+func (c StakingPool) PayTokenReward(opts CallOpts, staker types.Address, rewardToken uint64, amountToSend uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolPayTokenReward, staker, rewardToken, amountToSend)
+}
+
+// UpdateAlgodVer returns the params for calling updateAlgodVer(string)void.
+//
+// Update the (honor system) algod version for the node associated to this pool.  The node management daemon
+// should compare its current nodes version to the version stored in global state, updating when different.
+// The reti node daemon composes its own version string using format:
+// major.minor.build branch [commit hash],
+// ie: 3.22.0 rel/stable [6b508975]
+// [ ONLY OWNER OR MANAGER CAN CALL ]
+func (c StakingPool) UpdateAlgodVer(opts CallOpts, algodVer string) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolUpdateAlgodVer, algodVer)
+}
+
+// EpochBalanceUpdate returns the params for calling epochBalanceUpdate()void.
+//
+// Updates the balance of stakers in the pool based on the received 'rewards' (current balance vs known staked balance)
+// stakers outstanding balance is adjusted based on their % of stake and time in the current epoch - so that balance
+// compounds over time and staker can remove that amount at will.
+// The validator is paid their percentage each epoch payout.
+//
+// Note: ANYONE can call this.
+func (c StakingPool) EpochBalanceUpdate(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolEpochBalanceUpdate)
+}
+
+// GoOnline returns the params for calling goOnline(pay,byte[],byte[],byte[],uint64,uint64,uint64)void.
+//
+// Registers a staking pool key online against a participation key.
+// [ ONLY OWNER OR MANAGER CAN CALL ]
+func (c StakingPool) GoOnline(opts CallOpts, feePayment transaction.TransactionWithSigner, votePK []byte, selectionPK []byte, stateProofPK []byte, voteFirst uint64, voteLast uint64, voteKeyDilution uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolGoOnline, feePayment, votePK, selectionPK, stateProofPK, voteFirst, voteLast, voteKeyDilution)
+}
+
+// GoOffline returns the params for calling goOffline()void.
+//
+// Marks a staking pool key OFFLINE.
+// [ ONLY OWNER OR MANAGER CAN CALL ]
+func (c StakingPool) GoOffline(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolGoOffline)
+}
+
+// LinkToNFD returns the params for calling linkToNFD(uint64,string)void.
+func (c StakingPool) LinkToNFD(opts CallOpts, nfdAppId uint64, nfdName string) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolLinkToNFD, nfdAppId, nfdName)
+}
+
+// ProxiedSetTokenPayoutRatio returns the params for calling proxiedSetTokenPayoutRatio((uint64,uint64,uint64))(uint64[24],uint64).
+//
+// proxiedSetTokenPayoutRatio is meant to be called by pools != 1 - calling US, pool #1
+// We need to verify that we are in fact being called by another of OUR pools (not us)
+// and then we'll call the validator on their behalf to update the token payouts
+func (c StakingPool) ProxiedSetTokenPayoutRatio(opts CallOpts, poolKey ValidatorPoolKey) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodStakingPoolProxiedSetTokenPayoutRatio, poolKey.abiValue())
+}
+
+// ProxiedSetTokenPayoutRatioResult decodes the return value of a proxiedSetTokenPayoutRatio call.
+func (c StakingPool) ProxiedSetTokenPayoutRatioResult(result transaction.ABIMethodResult) (PoolTokenPayoutRatio, error) {
+	return decodeResult(result, decodePoolTokenPayoutRatio)
+}
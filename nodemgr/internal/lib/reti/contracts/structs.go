@@ -0,0 +1,529 @@
+// Code generated by arc32gen from ValidatorRegistry.arc32.json, StakingPool.arc32.json. DO NOT EDIT.
+
+package contracts
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// ValidatorPoolKey uniquely identifies a staking pool of a validator
+type ValidatorPoolKey struct {
+	// validator id - 0 is invalid, ids start at 1
+	ID uint64
+	// pool id - 0 is invalid, ids start at 1
+	PoolId    uint64
+	PoolAppId uint64
+}
+
+func (s ValidatorPoolKey) abiValue() []any {
+	return []any{
+		s.ID,
+		s.PoolId,
+		s.PoolAppId,
+	}
+}
+
+func decodeValidatorPoolKey(value any) (s ValidatorPoolKey, err error) {
+	values, err := decodeTuple(value, 3)
+	if err != nil {
+		return s, fmt.Errorf("ValidatorPoolKey: %w", err)
+	}
+	if s.ID, err = decodeUint64(values[0]); err != nil {
+		return s, fmt.Errorf("ValidatorPoolKey.ID: %w", err)
+	}
+	if s.PoolId, err = decodeUint64(values[1]); err != nil {
+		return s, fmt.Errorf("ValidatorPoolKey.PoolId: %w", err)
+	}
+	if s.PoolAppId, err = decodeUint64(values[2]); err != nil {
+		return s, fmt.Errorf("ValidatorPoolKey.PoolAppId: %w", err)
+	}
+	return s, nil
+}
+
+// ValidatorConfig is the configuration of a validator
+type ValidatorConfig struct {
+	// sequentially assigned id of the validator
+	ID uint64
+	// account that controls the config - presumably a cold wallet
+	Owner types.Address
+	// account that triggers/pays for payouts and keyreg transactions - must be a hot wallet
+	Manager types.Address
+	// optional NFD app id used to describe the validator
+	NFDForInfo            uint64
+	EntryGatingType       uint8
+	EntryGatingAddress    types.Address
+	EntryGatingAssets     [4]uint64
+	GatingAssetMinBalance uint64
+	// optional reward token asa id
+	RewardTokenId uint64
+	// amount of reward token paid out per epoch across all pools
+	RewardPerPayout uint64
+	// number of rounds per epoch
+	EpochRoundLength uint32
+	// payout percentage w/ four decimals - ie: 50000 = 5%
+	PercentToValidator         uint32
+	ValidatorCommissionAddress types.Address
+	MinEntryStake              uint64
+	// 0 means to use the protocol maximum
+	MaxAlgoPerPool uint64
+	PoolsPerNode   uint8
+	// timestamp when the validator will sunset (if != 0)
+	SunsettingOn uint64
+	// validator id the validator is moving to (if known)
+	SunsettingTo uint64
+}
+
+func (s ValidatorConfig) abiValue() []any {
+	return []any{
+		s.ID,
+		s.Owner,
+		s.Manager,
+		s.NFDForInfo,
+		s.EntryGatingType,
+		s.EntryGatingAddress,
+		s.EntryGatingAssets,
+		s.GatingAssetMinBalance,
+		s.RewardTokenId,
+		s.RewardPerPayout,
+		s.EpochRoundLength,
+		s.PercentToValidator,
+		s.ValidatorCommissionAddress,
+		s.MinEntryStake,
+		s.MaxAlgoPerPool,
+		s.PoolsPerNode,
+		s.SunsettingOn,
+		s.SunsettingTo,
+	}
+}
+
+func decodeValidatorConfig(value any) (s ValidatorConfig, err error) {
+	values, err := decodeTuple(value, 18)
+	if err != nil {
+		return s, fmt.Errorf("ValidatorConfig: %w", err)
+	}
+	if s.ID, err = decodeUint64(values[0]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.ID: %w", err)
+	}
+	if s.Owner, err = decodeAddress(values[1]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.Owner: %w", err)
+	}
+	if s.Manager, err = decodeAddress(values[2]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.Manager: %w", err)
+	}
+	if s.NFDForInfo, err = decodeUint64(values[3]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.NFDForInfo: %w", err)
+	}
+	if s.EntryGatingType, err = decodeUint8(values[4]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.EntryGatingType: %w", err)
+	}
+	if s.EntryGatingAddress, err = decodeAddress(values[5]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.EntryGatingAddress: %w", err)
+	}
+	if s.EntryGatingAssets, err = func(value any) (arr [4]uint64, err error) {
+		err = decodeArray(value, arr[:], decodeUint64)
+		return arr, err
+	}(values[6]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.EntryGatingAssets: %w", err)
+	}
+	if s.GatingAssetMinBalance, err = decodeUint64(values[7]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.GatingAssetMinBalance: %w", err)
+	}
+	if s.RewardTokenId, err = decodeUint64(values[8]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.RewardTokenId: %w", err)
+	}
+	if s.RewardPerPayout, err = decodeUint64(values[9]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.RewardPerPayout: %w", err)
+	}
+	if s.EpochRoundLength, err = decodeUint32(values[10]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.EpochRoundLength: %w", err)
+	}
+	if s.PercentToValidator, err = decodeUint32(values[11]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.PercentToValidator: %w", err)
+	}
+	if s.ValidatorCommissionAddress, err = decodeAddress(values[12]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.ValidatorCommissionAddress: %w", err)
+	}
+	if s.MinEntryStake, err = decodeUint64(values[13]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.MinEntryStake: %w", err)
+	}
+	if s.MaxAlgoPerPool, err = decodeUint64(values[14]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.MaxAlgoPerPool: %w", err)
+	}
+	if s.PoolsPerNode, err = decodeUint8(values[15]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.PoolsPerNode: %w", err)
+	}
+	if s.SunsettingOn, err = decodeUint64(values[16]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.SunsettingOn: %w", err)
+	}
+	if s.SunsettingTo, err = decodeUint64(values[17]); err != nil {
+		return s, fmt.Errorf("ValidatorConfig.SunsettingTo: %w", err)
+	}
+	return s, nil
+}
+
+// ValidatorCurState is the current state of a validator across all its pools
+type ValidatorCurState struct {
+	NumPools        uint16
+	TotalStakers    uint64
+	TotalAlgoStaked uint64
+	// reward tokens held back in pool 1 for paying out stakers
+	RewardTokenHeldBack uint64
+}
+
+func (s ValidatorCurState) abiValue() []any {
+	return []any{
+		s.NumPools,
+		s.TotalStakers,
+		s.TotalAlgoStaked,
+		s.RewardTokenHeldBack,
+	}
+}
+
+func decodeValidatorCurState(value any) (s ValidatorCurState, err error) {
+	values, err := decodeTuple(value, 4)
+	if err != nil {
+		return s, fmt.Errorf("ValidatorCurState: %w", err)
+	}
+	if s.NumPools, err = decodeUint16(values[0]); err != nil {
+		return s, fmt.Errorf("ValidatorCurState.NumPools: %w", err)
+	}
+	if s.TotalStakers, err = decodeUint64(values[1]); err != nil {
+		return s, fmt.Errorf("ValidatorCurState.TotalStakers: %w", err)
+	}
+	if s.TotalAlgoStaked, err = decodeUint64(values[2]); err != nil {
+		return s, fmt.Errorf("ValidatorCurState.TotalAlgoStaked: %w", err)
+	}
+	if s.RewardTokenHeldBack, err = decodeUint64(values[3]); err != nil {
+		return s, fmt.Errorf("ValidatorCurState.RewardTokenHeldBack: %w", err)
+	}
+	return s, nil
+}
+
+// PoolInfo is the state of a single staking pool
+type PoolInfo struct {
+	PoolAppId       uint64
+	TotalStakers    uint16
+	TotalAlgoStaked uint64
+}
+
+func (s PoolInfo) abiValue() []any {
+	return []any{
+		s.PoolAppId,
+		s.TotalStakers,
+		s.TotalAlgoStaked,
+	}
+}
+
+func decodePoolInfo(value any) (s PoolInfo, err error) {
+	values, err := decodeTuple(value, 3)
+	if err != nil {
+		return s, fmt.Errorf("PoolInfo: %w", err)
+	}
+	if s.PoolAppId, err = decodeUint64(values[0]); err != nil {
+		return s, fmt.Errorf("PoolInfo.PoolAppId: %w", err)
+	}
+	if s.TotalStakers, err = decodeUint16(values[1]); err != nil {
+		return s, fmt.Errorf("PoolInfo.TotalStakers: %w", err)
+	}
+	if s.TotalAlgoStaked, err = decodeUint64(values[2]); err != nil {
+		return s, fmt.Errorf("PoolInfo.TotalAlgoStaked: %w", err)
+	}
+	return s, nil
+}
+
+// NodeConfig is the list of pool app ids assigned to a node
+type NodeConfig struct {
+	PoolAppIds [3]uint64
+}
+
+func (s NodeConfig) abiValue() []any {
+	return []any{
+		s.PoolAppIds,
+	}
+}
+
+func decodeNodeConfig(value any) (s NodeConfig, err error) {
+	values, err := decodeTuple(value, 1)
+	if err != nil {
+		return s, fmt.Errorf("NodeConfig: %w", err)
+	}
+	if s.PoolAppIds, err = func(value any) (arr [3]uint64, err error) {
+		err = decodeArray(value, arr[:], decodeUint64)
+		return arr, err
+	}(values[0]); err != nil {
+		return s, fmt.Errorf("NodeConfig.PoolAppIds: %w", err)
+	}
+	return s, nil
+}
+
+// NodePoolAssignmentConfig is the assignment of pools to each node of a validator
+type NodePoolAssignmentConfig struct {
+	Nodes [8]NodeConfig
+}
+
+func (s NodePoolAssignmentConfig) abiValue() []any {
+	return []any{
+		encodeArray(s.Nodes[:], func(elem NodeConfig) any { return elem.abiValue() }),
+	}
+}
+
+func decodeNodePoolAssignmentConfig(value any) (s NodePoolAssignmentConfig, err error) {
+	values, err := decodeTuple(value, 1)
+	if err != nil {
+		return s, fmt.Errorf("NodePoolAssignmentConfig: %w", err)
+	}
+	if s.Nodes, err = func(value any) (arr [8]NodeConfig, err error) {
+		err = decodeArray(value, arr[:], decodeNodeConfig)
+		return arr, err
+	}(values[0]); err != nil {
+		return s, fmt.Errorf("NodePoolAssignmentConfig.Nodes: %w", err)
+	}
+	return s, nil
+}
+
+// PoolTokenPayoutRatio is the share of reward tokens paid out to each pool
+type PoolTokenPayoutRatio struct {
+	// percentage of each pool vs all pools
+	PoolPctOfWhole [24]uint64
+	// round the ratio was last updated
+	UpdatedForPayout uint64
+}
+
+func (s PoolTokenPayoutRatio) abiValue() []any {
+	return []any{
+		s.PoolPctOfWhole,
+		s.UpdatedForPayout,
+	}
+}
+
+func decodePoolTokenPayoutRatio(value any) (s PoolTokenPayoutRatio, err error) {
+	values, err := decodeTuple(value, 2)
+	if err != nil {
+		return s, fmt.Errorf("PoolTokenPayoutRatio: %w", err)
+	}
+	if s.PoolPctOfWhole, err = func(value any) (arr [24]uint64, err error) {
+		err = decodeArray(value, arr[:], decodeUint64)
+		return arr, err
+	}(values[0]); err != nil {
+		return s, fmt.Errorf("PoolTokenPayoutRatio.PoolPctOfWhole: %w", err)
+	}
+	if s.UpdatedForPayout, err = decodeUint64(values[1]); err != nil {
+		return s, fmt.Errorf("PoolTokenPayoutRatio.UpdatedForPayout: %w", err)
+	}
+	return s, nil
+}
+
+// MbrAmounts are the minimum balance requirements for the various protocol operations
+type MbrAmounts struct {
+	AddValidatorMbr uint64
+	AddPoolMbr      uint64
+	PoolInitMbr     uint64
+	AddStakerMbr    uint64
+}
+
+func (s MbrAmounts) abiValue() []any {
+	return []any{
+		s.AddValidatorMbr,
+		s.AddPoolMbr,
+		s.PoolInitMbr,
+		s.AddStakerMbr,
+	}
+}
+
+func decodeMbrAmounts(value any) (s MbrAmounts, err error) {
+	values, err := decodeTuple(value, 4)
+	if err != nil {
+		return s, fmt.Errorf("MbrAmounts: %w", err)
+	}
+	if s.AddValidatorMbr, err = decodeUint64(values[0]); err != nil {
+		return s, fmt.Errorf("MbrAmounts.AddValidatorMbr: %w", err)
+	}
+	if s.AddPoolMbr, err = decodeUint64(values[1]); err != nil {
+		return s, fmt.Errorf("MbrAmounts.AddPoolMbr: %w", err)
+	}
+	if s.PoolInitMbr, err = decodeUint64(values[2]); err != nil {
+		return s, fmt.Errorf("MbrAmounts.PoolInitMbr: %w", err)
+	}
+	if s.AddStakerMbr, err = decodeUint64(values[3]); err != nil {
+		return s, fmt.Errorf("MbrAmounts.AddStakerMbr: %w", err)
+	}
+	return s, nil
+}
+
+// Constraints are the protocol limits, some static and some based on current consensus data
+type Constraints struct {
+	EpochPayoutRoundsMin           uint64
+	EpochPayoutRoundsMax           uint64
+	MinPctToValidatorWFourDecimals uint64
+	MaxPctToValidatorWFourDecimals uint64
+	MinEntryStake                  uint64
+	MaxAlgoPerPool                 uint64
+	MaxAlgoPerValidator            uint64
+	// soft stake limit, where saturation starts
+	AmtConsideredSaturated uint64
+	MaxNodes               uint64
+	MaxPoolsPerNode        uint64
+	MaxStakersPerPool      uint64
+}
+
+func (s Constraints) abiValue() []any {
+	return []any{
+		s.EpochPayoutRoundsMin,
+		s.EpochPayoutRoundsMax,
+		s.MinPctToValidatorWFourDecimals,
+		s.MaxPctToValidatorWFourDecimals,
+		s.MinEntryStake,
+		s.MaxAlgoPerPool,
+		s.MaxAlgoPerValidator,
+		s.AmtConsideredSaturated,
+		s.MaxNodes,
+		s.MaxPoolsPerNode,
+		s.MaxStakersPerPool,
+	}
+}
+
+func decodeConstraints(value any) (s Constraints, err error) {
+	values, err := decodeTuple(value, 11)
+	if err != nil {
+		return s, fmt.Errorf("Constraints: %w", err)
+	}
+	if s.EpochPayoutRoundsMin, err = decodeUint64(values[0]); err != nil {
+		return s, fmt.Errorf("Constraints.EpochPayoutRoundsMin: %w", err)
+	}
+	if s.EpochPayoutRoundsMax, err = decodeUint64(values[1]); err != nil {
+		return s, fmt.Errorf("Constraints.EpochPayoutRoundsMax: %w", err)
+	}
+	if s.MinPctToValidatorWFourDecimals, err = decodeUint64(values[2]); err != nil {
+		return s, fmt.Errorf("Constraints.MinPctToValidatorWFourDecimals: %w", err)
+	}
+	if s.MaxPctToValidatorWFourDecimals, err = decodeUint64(values[3]); err != nil {
+		return s, fmt.Errorf("Constraints.MaxPctToValidatorWFourDecimals: %w", err)
+	}
+	if s.MinEntryStake, err = decodeUint64(values[4]); err != nil {
+		return s, fmt.Errorf("Constraints.MinEntryStake: %w", err)
+	}
+	if s.MaxAlgoPerPool, err = decodeUint64(values[5]); err != nil {
+		return s, fmt.Errorf("Constraints.MaxAlgoPerPool: %w", err)
+	}
+	if s.MaxAlgoPerValidator, err = decodeUint64(values[6]); err != nil {
+		return s, fmt.Errorf("Constraints.MaxAlgoPerValidator: %w", err)
+	}
+	if s.AmtConsideredSaturated, err = decodeUint64(values[7]); err != nil {
+		return s, fmt.Errorf("Constraints.AmtConsideredSaturated: %w", err)
+	}
+	if s.MaxNodes, err = decodeUint64(values[8]); err != nil {
+		return s, fmt.Errorf("Constraints.MaxNodes: %w", err)
+	}
+	if s.MaxPoolsPerNode, err = decodeUint64(values[9]); err != nil {
+		return s, fmt.Errorf("Constraints.MaxPoolsPerNode: %w", err)
+	}
+	if s.MaxStakersPerPool, err = decodeUint64(values[10]); err != nil {
+		return s, fmt.Errorf("Constraints.MaxStakersPerPool: %w", err)
+	}
+	return s, nil
+}
+
+// StakedInfo is the data stored per staker in a staking pool
+type StakedInfo struct {
+	Account            types.Address
+	Balance            uint64
+	TotalRewarded      uint64
+	RewardTokenBalance uint64
+	// round the stake counts from (including the consensus balance delay)
+	EntryRound uint64
+}
+
+func (s StakedInfo) abiValue() []any {
+	return []any{
+		s.Account,
+		s.Balance,
+		s.TotalRewarded,
+		s.RewardTokenBalance,
+		s.EntryRound,
+	}
+}
+
+func decodeStakedInfo(value any) (s StakedInfo, err error) {
+	values, err := decodeTuple(value, 5)
+	if err != nil {
+		return s, fmt.Errorf("StakedInfo: %w", err)
+	}
+	if s.Account, err = decodeAddress(values[0]); err != nil {
+		return s, fmt.Errorf("StakedInfo.Account: %w", err)
+	}
+	if s.Balance, err = decodeUint64(values[1]); err != nil {
+		return s, fmt.Errorf("StakedInfo.Balance: %w", err)
+	}
+	if s.TotalRewarded, err = decodeUint64(values[2]); err != nil {
+		return s, fmt.Errorf("StakedInfo.TotalRewarded: %w", err)
+	}
+	if s.RewardTokenBalance, err = decodeUint64(values[3]); err != nil {
+		return s, fmt.Errorf("StakedInfo.RewardTokenBalance: %w", err)
+	}
+	if s.EntryRound, err = decodeUint64(values[4]); err != nil {
+		return s, fmt.Errorf("StakedInfo.EntryRound: %w", err)
+	}
+	return s, nil
+}
+
+// OwnerAndManager is the owner and manager accounts of a validator
+type OwnerAndManager struct {
+	Owner   types.Address
+	Manager types.Address
+}
+
+func (s OwnerAndManager) abiValue() []any {
+	return []any{
+		s.Owner,
+		s.Manager,
+	}
+}
+
+func decodeOwnerAndManager(value any) (s OwnerAndManager, err error) {
+	values, err := decodeTuple(value, 2)
+	if err != nil {
+		return s, fmt.Errorf("OwnerAndManager: %w", err)
+	}
+	if s.Owner, err = decodeAddress(values[0]); err != nil {
+		return s, fmt.Errorf("OwnerAndManager.Owner: %w", err)
+	}
+	if s.Manager, err = decodeAddress(values[1]); err != nil {
+		return s, fmt.Errorf("OwnerAndManager.Manager: %w", err)
+	}
+	return s, nil
+}
+
+// PoolForStaker is the pool a staker's stake would be added to
+type PoolForStaker struct {
+	// pool id is 0 if no pool has room
+	PoolKey                ValidatorPoolKey
+	IsNewStakerToValidator bool
+	IsNewStakerToProtocol  bool
+}
+
+func (s PoolForStaker) abiValue() []any {
+	return []any{
+		s.PoolKey.abiValue(),
+		s.IsNewStakerToValidator,
+		s.IsNewStakerToProtocol,
+	}
+}
+
+func decodePoolForStaker(value any) (s PoolForStaker, err error) {
+	values, err := decodeTuple(value, 3)
+	if err != nil {
+		return s, fmt.Errorf("PoolForStaker: %w", err)
+	}
+	if s.PoolKey, err = decodeValidatorPoolKey(values[0]); err != nil {
+		return s, fmt.Errorf("PoolForStaker.PoolKey: %w", err)
+	}
+	if s.IsNewStakerToValidator, err = decodeBool(values[1]); err != nil {
+		return s, fmt.Errorf("PoolForStaker.IsNewStakerToValidator: %w", err)
+	}
+	if s.IsNewStakerToProtocol, err = decodeBool(values[2]); err != nil {
+		return s, fmt.Errorf("PoolForStaker.IsNewStakerToProtocol: %w", err)
+	}
+	return s, nil
+}
@@ -0,0 +1,471 @@
+// Code generated by arc32gen from ValidatorRegistry.arc32.json. DO NOT EDIT.
+
+package contracts
+
+import (
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// ValidatorRegistry is a typed client for the methods of the ValidatorRegistry contract.
+// Each method returns the params for calling it (to add to a transaction group) - methods with a return value
+// have a matching Result method to decode it.
+type ValidatorRegistry struct {
+	AppID uint64
+}
+
+var (
+	methodValidatorRegistryCreateApplication                = mustMethod("createApplication()void")
+	methodValidatorRegistryInitStakingContract              = mustMethod("initStakingContract(uint64)void")
+	methodValidatorRegistryLoadStakingContractData          = mustMethod("loadStakingContractData(uint64,byte[])void")
+	methodValidatorRegistryFinalizeStakingContract          = mustMethod("finalizeStakingContract()void")
+	methodValidatorRegistryGas                              = mustMethod("gas()void")
+	methodValidatorRegistryGetMbrAmounts                    = mustMethod("getMbrAmounts()(uint64,uint64,uint64,uint64)")
+	methodValidatorRegistryGetProtocolConstraints           = mustMethod("getProtocolConstraints()(uint64,uint64,uint64,uint64,uint64,uint64,uint64,uint64,uint64,uint64,uint64)")
+	methodValidatorRegistryGetNumValidators                 = mustMethod("getNumValidators()uint64")
+	methodValidatorRegistryGetValidatorConfig               = mustMethod("getValidatorConfig(uint64)(uint64,address,address,uint64,uint8,address,uint64[4],uint64,uint64,uint64,uint32,uint32,address,uint64,uint64,uint8,uint64,uint64)")
+	methodValidatorRegistryGetValidatorState                = mustMethod("getValidatorState(uint64)(uint16,uint64,uint64,uint64)")
+	methodValidatorRegistryGetValidatorOwnerAndManager      = mustMethod("getValidatorOwnerAndManager(uint64)(address,address)")
+	methodValidatorRegistryGetPools                         = mustMethod("getPools(uint64)(uint64,uint16,uint64)[]")
+	methodValidatorRegistryGetPoolAppId                     = mustMethod("getPoolAppId(uint64,uint64)uint64")
+	methodValidatorRegistryGetPoolInfo                      = mustMethod("getPoolInfo((uint64,uint64,uint64))(uint64,uint16,uint64)")
+	methodValidatorRegistryGetCurMaxStakePerPool            = mustMethod("getCurMaxStakePerPool(uint64)uint64")
+	methodValidatorRegistryDoesStakerNeedToPayMBR           = mustMethod("doesStakerNeedToPayMBR(address)bool")
+	methodValidatorRegistryGetStakedPoolsForAccount         = mustMethod("getStakedPoolsForAccount(address)(uint64,uint64,uint64)[]")
+	methodValidatorRegistryGetTokenPayoutRatio              = mustMethod("getTokenPayoutRatio(uint64)(uint64[24],uint64)")
+	methodValidatorRegistryGetNodePoolAssignments           = mustMethod("getNodePoolAssignments(uint64)((uint64[3])[8])")
+	methodValidatorRegistryGetNFDRegistryID                 = mustMethod("getNFDRegistryID()uint64")
+	methodValidatorRegistryAddValidator                     = mustMethod("addValidator(pay,string,(uint64,address,address,uint64,uint8,address,uint64[4],uint64,uint64,uint64,uint32,uint32,address,uint64,uint64,uint8,uint64,uint64))uint64")
+	methodValidatorRegistryChangeValidatorManager           = mustMethod("changeValidatorManager(uint64,address)void")
+	methodValidatorRegistryChangeValidatorSunsetInfo        = mustMethod("changeValidatorSunsetInfo(uint64,uint64,uint64)void")
+	methodValidatorRegistryChangeValidatorNFD               = mustMethod("changeValidatorNFD(uint64,uint64,string)void")
+	methodValidatorRegistryChangeValidatorCommissionAddress = mustMethod("changeValidatorCommissionAddress(uint64,address)void")
+	methodValidatorRegistryChangeValidatorRewardInfo        = mustMethod("changeValidatorRewardInfo(uint64,uint8,address,uint64[4],uint64,uint64)void")
+	methodValidatorRegistryAddPool                          = mustMethod("addPool(pay,uint64,uint64)(uint64,uint64,uint64)")
+	methodValidatorRegistryAddStake                         = mustMethod("addStake(pay,uint64,uint64)(uint64,uint64,uint64)")
+	methodValidatorRegistrySetTokenPayoutRatio              = mustMethod("setTokenPayoutRatio(uint64)(uint64[24],uint64)")
+	methodValidatorRegistryStakeUpdatedViaRewards           = mustMethod("stakeUpdatedViaRewards((uint64,uint64,uint64),uint64,uint64,uint64,uint64)void")
+	methodValidatorRegistryStakeRemoved                     = mustMethod("stakeRemoved((uint64,uint64,uint64),address,uint64,uint64,bool)void")
+	methodValidatorRegistryFindPoolForStaker                = mustMethod("findPoolForStaker(uint64,address,uint64)((uint64,uint64,uint64),bool,bool)")
+	methodValidatorRegistryMovePoolToNode                   = mustMethod("movePoolToNode(uint64,uint64,uint64)void")
+	methodValidatorRegistryEmptyTokenRewards                = mustMethod("emptyTokenRewards(uint64,address)uint64")
+)
+
+// CreateApplication returns the params for calling createApplication()void.
+func (c ValidatorRegistry) CreateApplication(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryCreateApplication)
+}
+
+// InitStakingContract returns the params for calling initStakingContract(uint64)void.
+func (c ValidatorRegistry) InitStakingContract(opts CallOpts, approvalProgramSize uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryInitStakingContract, approvalProgramSize)
+}
+
+// LoadStakingContractData returns the params for calling loadStakingContractData(uint64,byte[])void.
+func (c ValidatorRegistry) LoadStakingContractData(opts CallOpts, offset uint64, data []byte) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryLoadStakingContractData, offset, data)
+}
+
+// FinalizeStakingContract returns the params for calling finalizeStakingContract()void.
+func (c ValidatorRegistry) FinalizeStakingContract(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryFinalizeStakingContract)
+}
+
+// Gas returns the params for calling gas()void.
+//
+// gas is a dummy no-op call that can be used to pool-up resource references and opcode cost
+func (c ValidatorRegistry) Gas(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGas)
+}
+
+// GetMbrAmounts returns the params for calling getMbrAmounts()(uint64,uint64,uint64,uint64).
+//
+// Returns the MBR amounts needed for various actions:
+// [
+//
+//	addValidatorMbr: uint64 - mbr needed to add a new validator - paid to validator contract
+//	addPoolMbr: uint64 - mbr needed to add a new pool - paid to validator
+//	poolInitMbr: uint64 - mbr needed to initStorage() of pool - paid to pool itself
+//	addStakerMbr: uint64 - mbr staker needs to add to first staking payment (stays w/ validator)
+//
+// ]
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetMbrAmounts(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetMbrAmounts)
+}
+
+// GetMbrAmountsResult decodes the return value of a getMbrAmounts call.
+func (c ValidatorRegistry) GetMbrAmountsResult(result transaction.ABIMethodResult) (MbrAmounts, error) {
+	return decodeResult(result, decodeMbrAmounts)
+}
+
+// GetProtocolConstraints returns the params for calling getProtocolConstraints()(uint64,uint64,uint64,uint64,uint64,uint64,uint64,uint64,uint64,uint64,uint64).
+//
+// Returns the protocol constraints so that UIs can limit what users specify for validator configuration parameters.
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetProtocolConstraints(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetProtocolConstraints)
+}
+
+// GetProtocolConstraintsResult decodes the return value of a getProtocolConstraints call.
+func (c ValidatorRegistry) GetProtocolConstraintsResult(result transaction.ABIMethodResult) (Constraints, error) {
+	return decodeResult(result, decodeConstraints)
+}
+
+// GetNumValidators returns the params for calling getNumValidators()uint64.
+//
+// # Returns the current number of validators
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetNumValidators(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetNumValidators)
+}
+
+// GetNumValidatorsResult decodes the return value of a getNumValidators call.
+func (c ValidatorRegistry) GetNumValidatorsResult(result transaction.ABIMethodResult) (uint64, error) {
+	return decodeResult(result, decodeUint64)
+}
+
+// GetValidatorConfig returns the params for calling getValidatorConfig(uint64)(uint64,address,address,uint64,uint8,address,uint64[4],uint64,uint64,uint64,uint32,uint32,address,uint64,uint64,uint8,uint64,uint64).
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetValidatorConfig(opts CallOpts, validatorId uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetValidatorConfig, validatorId)
+}
+
+// GetValidatorConfigResult decodes the return value of a getValidatorConfig call.
+func (c ValidatorRegistry) GetValidatorConfigResult(result transaction.ABIMethodResult) (ValidatorConfig, error) {
+	return decodeResult(result, decodeValidatorConfig)
+}
+
+// GetValidatorState returns the params for calling getValidatorState(uint64)(uint16,uint64,uint64,uint64).
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetValidatorState(opts CallOpts, validatorId uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetValidatorState, validatorId)
+}
+
+// GetValidatorStateResult decodes the return value of a getValidatorState call.
+func (c ValidatorRegistry) GetValidatorStateResult(result transaction.ABIMethodResult) (ValidatorCurState, error) {
+	return decodeResult(result, decodeValidatorCurState)
+}
+
+// GetValidatorOwnerAndManager returns the params for calling getValidatorOwnerAndManager(uint64)(address,address).
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetValidatorOwnerAndManager(opts CallOpts, validatorId uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetValidatorOwnerAndManager, validatorId)
+}
+
+// GetValidatorOwnerAndManagerResult decodes the return value of a getValidatorOwnerAndManager call.
+func (c ValidatorRegistry) GetValidatorOwnerAndManagerResult(result transaction.ABIMethodResult) (OwnerAndManager, error) {
+	return decodeResult(result, decodeOwnerAndManager)
+}
+
+// GetPools returns the params for calling getPools(uint64)(uint64,uint16,uint64)[].
+//
+// Return list of all pools for this validator.
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetPools(opts CallOpts, validatorId uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetPools, validatorId)
+}
+
+// GetPoolsResult decodes the return value of a getPools call.
+func (c ValidatorRegistry) GetPoolsResult(result transaction.ABIMethodResult) ([]PoolInfo, error) {
+	return decodeResult(result, func(value any) ([]PoolInfo, error) {
+		return decodeSlice(value, decodePoolInfo)
+	})
+}
+
+// GetPoolAppId returns the params for calling getPoolAppId(uint64,uint64)uint64.
+//
+// getPoolAppId is useful for callers to determine app to call for removing stake if they don't have staking or
+// want to get staker list for an account.  The staking pool also uses it to get the app id of staking pool 1
+// (which contains reward tokens if being used) so that the amount available can be determined.
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetPoolAppId(opts CallOpts, validatorId uint64, poolId uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetPoolAppId, validatorId, poolId)
+}
+
+// GetPoolAppIdResult decodes the return value of a getPoolAppId call.
+func (c ValidatorRegistry) GetPoolAppIdResult(result transaction.ABIMethodResult) (uint64, error) {
+	return decodeResult(result, decodeUint64)
+}
+
+// GetPoolInfo returns the params for calling getPoolInfo((uint64,uint64,uint64))(uint64,uint16,uint64).
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetPoolInfo(opts CallOpts, poolKey ValidatorPoolKey) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetPoolInfo, poolKey.abiValue())
+}
+
+// GetPoolInfoResult decodes the return value of a getPoolInfo call.
+func (c ValidatorRegistry) GetPoolInfoResult(result transaction.ABIMethodResult) (PoolInfo, error) {
+	return decodeResult(result, decodePoolInfo)
+}
+
+// GetCurMaxStakePerPool returns the params for calling getCurMaxStakePerPool(uint64)uint64.
+//
+// Calculate the maximum stake per pool for a given validator.
+// Normally this would be maxAlgoPerPool, but it should also never go above MaxAllowedStake / numPools so
+// as pools are added the max allowed per pool can reduce.
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetCurMaxStakePerPool(opts CallOpts, validatorId uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetCurMaxStakePerPool, validatorId)
+}
+
+// GetCurMaxStakePerPoolResult decodes the return value of a getCurMaxStakePerPool call.
+func (c ValidatorRegistry) GetCurMaxStakePerPoolResult(result transaction.ABIMethodResult) (uint64, error) {
+	return decodeResult(result, decodeUint64)
+}
+
+// DoesStakerNeedToPayMBR returns the params for calling doesStakerNeedToPayMBR(address)bool.
+//
+// Helper callers can call w/ simulate to determine if 'AddStaker' MBR should be included w/ staking amount
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) DoesStakerNeedToPayMBR(opts CallOpts, staker types.Address) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryDoesStakerNeedToPayMBR, staker)
+}
+
+// DoesStakerNeedToPayMBRResult decodes the return value of a doesStakerNeedToPayMBR call.
+func (c ValidatorRegistry) DoesStakerNeedToPayMBRResult(result transaction.ABIMethodResult) (bool, error) {
+	return decodeResult(result, decodeBool)
+}
+
+// GetStakedPoolsForAccount returns the params for calling getStakedPoolsForAccount(address)(uint64,uint64,uint64)[].
+//
+// Retrieves the staked pools for an account.
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetStakedPoolsForAccount(opts CallOpts, staker types.Address) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetStakedPoolsForAccount, staker)
+}
+
+// GetStakedPoolsForAccountResult decodes the return value of a getStakedPoolsForAccount call.
+func (c ValidatorRegistry) GetStakedPoolsForAccountResult(result transaction.ABIMethodResult) ([]ValidatorPoolKey, error) {
+	return decodeResult(result, func(value any) ([]ValidatorPoolKey, error) {
+		return decodeSlice(value, decodeValidatorPoolKey)
+	})
+}
+
+// GetTokenPayoutRatio returns the params for calling getTokenPayoutRatio(uint64)(uint64[24],uint64).
+//
+// Retrieves the token payout ratio for a given validator - returning the pool ratios of whole so that token
+// payouts across pools can be based on a stable snaphost of stake.
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetTokenPayoutRatio(opts CallOpts, validatorId uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetTokenPayoutRatio, validatorId)
+}
+
+// GetTokenPayoutRatioResult decodes the return value of a getTokenPayoutRatio call.
+func (c ValidatorRegistry) GetTokenPayoutRatioResult(result transaction.ABIMethodResult) (PoolTokenPayoutRatio, error) {
+	return decodeResult(result, decodePoolTokenPayoutRatio)
+}
+
+// GetNodePoolAssignments returns the params for calling getNodePoolAssignments(uint64)((uint64[3])[8]).
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetNodePoolAssignments(opts CallOpts, validatorId uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetNodePoolAssignments, validatorId)
+}
+
+// GetNodePoolAssignmentsResult decodes the return value of a getNodePoolAssignments call.
+func (c ValidatorRegistry) GetNodePoolAssignmentsResult(result transaction.ABIMethodResult) (NodePoolAssignmentConfig, error) {
+	return decodeResult(result, decodeNodePoolAssignmentConfig)
+}
+
+// GetNFDRegistryID returns the params for calling getNFDRegistryID()uint64.
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) GetNFDRegistryID(opts CallOpts) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryGetNFDRegistryID)
+}
+
+// GetNFDRegistryIDResult decodes the return value of a getNFDRegistryID call.
+func (c ValidatorRegistry) GetNFDRegistryIDResult(result transaction.ABIMethodResult) (uint64, error) {
+	return decodeResult(result, decodeUint64)
+}
+
+// AddValidator returns the params for calling addValidator(pay,string,(uint64,address,address,uint64,uint8,address,uint64[4],uint64,uint64,uint64,uint32,uint32,address,uint64,uint64,uint8,uint64,uint64))uint64.
+//
+// Adds a new validator
+// Requires at least 10 ALGO as the 'fee' for the transaction to help dissuade spammed validator adds.
+func (c ValidatorRegistry) AddValidator(opts CallOpts, mbrPayment transaction.TransactionWithSigner, nfdName string, config ValidatorConfig) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryAddValidator, mbrPayment, nfdName, config.abiValue())
+}
+
+// AddValidatorResult decodes the return value of a addValidator call.
+//
+// uint64 validator id
+func (c ValidatorRegistry) AddValidatorResult(result transaction.ABIMethodResult) (uint64, error) {
+	return decodeResult(result, decodeUint64)
+}
+
+// ChangeValidatorManager returns the params for calling changeValidatorManager(uint64,address)void.
+//
+// Changes the Validator manager for a specific Validator id.
+// [ ONLY OWNER CAN CHANGE ]
+func (c ValidatorRegistry) ChangeValidatorManager(opts CallOpts, validatorId uint64, manager types.Address) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryChangeValidatorManager, validatorId, manager)
+}
+
+// ChangeValidatorSunsetInfo returns the params for calling changeValidatorSunsetInfo(uint64,uint64,uint64)void.
+//
+// Updates the sunset information for a given validator.
+// [ ONLY OWNER CAN CHANGE ]
+func (c ValidatorRegistry) ChangeValidatorSunsetInfo(opts CallOpts, validatorId uint64, sunsettingOn uint64, sunsettingTo uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryChangeValidatorSunsetInfo, validatorId, sunsettingOn, sunsettingTo)
+}
+
+// ChangeValidatorNFD returns the params for calling changeValidatorNFD(uint64,uint64,string)void.
+//
+// Changes the NFD for a validator in the validatorList contract.
+// [ ONLY OWNER CAN CHANGE ]
+func (c ValidatorRegistry) ChangeValidatorNFD(opts CallOpts, validatorId uint64, nfdAppID uint64, nfdName string) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryChangeValidatorNFD, validatorId, nfdAppID, nfdName)
+}
+
+// ChangeValidatorCommissionAddress returns the params for calling changeValidatorCommissionAddress(uint64,address)void.
+//
+// Change the commission address that validator rewards are sent to.
+//
+//	[ ONLY OWNER CAN CHANGE ]
+func (c ValidatorRegistry) ChangeValidatorCommissionAddress(opts CallOpts, validatorId uint64, commissionAddress types.Address) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryChangeValidatorCommissionAddress, validatorId, commissionAddress)
+}
+
+// ChangeValidatorRewardInfo returns the params for calling changeValidatorRewardInfo(uint64,uint8,address,uint64[4],uint64,uint64)void.
+//
+// Allow the additional rewards (gating entry, additional token rewards) information be changed at will.
+// [ ONLY OWNER CAN CHANGE ]
+func (c ValidatorRegistry) ChangeValidatorRewardInfo(opts CallOpts, validatorId uint64, entryGatingType uint8, entryGatingAddress types.Address, entryGatingAssets [4]uint64, gatingAssetMinBalance uint64, rewardPerPayout uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryChangeValidatorRewardInfo, validatorId, entryGatingType, entryGatingAddress, entryGatingAssets, gatingAssetMinBalance, rewardPerPayout)
+}
+
+// AddPool returns the params for calling addPool(pay,uint64,uint64)(uint64,uint64,uint64).
+//
+// Adds a new pool to a validator's pool set, returning the 'key' to reference the pool in the future for staking, etc.
+// The caller must pay the cost of the validators MBR increase as well as the MBR that will be needed for the pool itself.
+//
+// [ ONLY OWNER OR MANAGER CAN call ]
+func (c ValidatorRegistry) AddPool(opts CallOpts, mbrPayment transaction.TransactionWithSigner, validatorId uint64, nodeNum uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryAddPool, mbrPayment, validatorId, nodeNum)
+}
+
+// AddPoolResult decodes the return value of a addPool call.
+//
+// ValidatorPoolKey pool key to created pool
+func (c ValidatorRegistry) AddPoolResult(result transaction.ABIMethodResult) (ValidatorPoolKey, error) {
+	return decodeResult(result, decodeValidatorPoolKey)
+}
+
+// AddStake returns the params for calling addStake(pay,uint64,uint64)(uint64,uint64,uint64).
+//
+// Adds stake to a validator pool.
+func (c ValidatorRegistry) AddStake(opts CallOpts, stakedAmountPayment transaction.TransactionWithSigner, validatorId uint64, valueToVerify uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryAddStake, stakedAmountPayment, validatorId, valueToVerify)
+}
+
+// AddStakeResult decodes the return value of a addStake call.
+//
+// ValidatorPoolKey - The key of the validator pool.
+func (c ValidatorRegistry) AddStakeResult(result transaction.ABIMethodResult) (ValidatorPoolKey, error) {
+	return decodeResult(result, decodeValidatorPoolKey)
+}
+
+// SetTokenPayoutRatio returns the params for calling setTokenPayoutRatio(uint64)(uint64[24],uint64).
+//
+// setTokenPayoutRatio is called by Staking Pool # 1 (ONLY) to ask the validator (us) to calculate the ratios
+// of stake in the pools for subsequent token payouts (ie: 2 pools, '100' algo total staked, 60 in pool 1, and 40
+// in pool 2)  This is done so we have a stable snapshot of stake - taken once per epoch - only triggered by
+// pool 1 doing payout.  pools other than 1 doing payout call pool 1 to ask it do it first.
+// It would be 60/40% in the poolPctOfWhole values.  The token reward payouts then use these values instead of
+// their 'current' stake which changes as part of the payouts themselves (and people could be changing stake
+// during the epoch updates across pools)
+//
+// Multiple pools will call us via pool 1 (pool2-pool1-validator, etc.) so don't assert on pool1 calling multiple
+// times in same epoch.  Just return.
+func (c ValidatorRegistry) SetTokenPayoutRatio(opts CallOpts, validatorId uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistrySetTokenPayoutRatio, validatorId)
+}
+
+// SetTokenPayoutRatioResult decodes the return value of a setTokenPayoutRatio call.
+//
+// PoolTokenPayoutRatio - the finished ratio data
+func (c ValidatorRegistry) SetTokenPayoutRatioResult(result transaction.ABIMethodResult) (PoolTokenPayoutRatio, error) {
+	return decodeResult(result, decodePoolTokenPayoutRatio)
+}
+
+// StakeUpdatedViaRewards returns the params for calling stakeUpdatedViaRewards((uint64,uint64,uint64),uint64,uint64,uint64,uint64)void.
+//
+// stakeUpdatedViaRewards is called by Staking pools to inform the validator (us) that a particular amount of total
+// stake has been added to the specified pool.  This is used to update the stats we have in our PoolInfo storage.
+// The calling App id is validated against our pool list as well.
+func (c ValidatorRegistry) StakeUpdatedViaRewards(opts CallOpts, poolKey ValidatorPoolKey, algoToAdd uint64, rewardTokenAmountReserved uint64, validatorCommission uint64, saturatedBurnToFeeSink uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryStakeUpdatedViaRewards, poolKey.abiValue(), algoToAdd, rewardTokenAmountReserved, validatorCommission, saturatedBurnToFeeSink)
+}
+
+// StakeRemoved returns the params for calling stakeRemoved((uint64,uint64,uint64),address,uint64,uint64,bool)void.
+//
+// stakeRemoved is called by Staking pools to inform the validator (us) that a particular amount of total stake has been removed
+// from the specified pool.  This is used to update the stats we have in our PoolInfo storage.
+// If any amount of rewardRemoved is specified, then that amount of reward is sent to the use
+// The calling App id is validated against our pool list as well.
+func (c ValidatorRegistry) StakeRemoved(opts CallOpts, poolKey ValidatorPoolKey, staker types.Address, amountRemoved uint64, rewardRemoved uint64, stakerRemoved bool) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryStakeRemoved, poolKey.abiValue(), staker, amountRemoved, rewardRemoved, stakerRemoved)
+}
+
+// FindPoolForStaker returns the params for calling findPoolForStaker(uint64,address,uint64)((uint64,uint64,uint64),bool,bool).
+//
+// Finds the pool for a staker based on the provided validator id, staker address, and amount to stake.
+// First checks the stakers 'already staked list' for the validator preferring those (adding if possible) then adds
+// to new pool if necessary.
+//
+// The method is read-only, so it can be simulated rather than sent.
+func (c ValidatorRegistry) FindPoolForStaker(opts CallOpts, validatorId uint64, staker types.Address, amountToStake uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryFindPoolForStaker, validatorId, staker, amountToStake)
+}
+
+// FindPoolForStakerResult decodes the return value of a findPoolForStaker call.
+//
+// ValidatorPoolKey, boolean, boolean - The pool for the staker, true/false on whether the staker is 'new'
+// to this VALIDATOR, and true/false if staker is new to the protocol.
+func (c ValidatorRegistry) FindPoolForStakerResult(result transaction.ABIMethodResult) (PoolForStaker, error) {
+	return decodeResult(result, decodePoolForStaker)
+}
+
+// MovePoolToNode returns the params for calling movePoolToNode(uint64,uint64,uint64)void.
+//
+// Find the specified pool (in any node number) and move it to the specified node.
+// The pool account is forced offline if moved so prior node will still run for 320 rounds but
+// new key goes online on new node soon after (320 rounds after it goes online)
+// No-op if success, asserts if not found or can't move  (no space in target)
+// [ ONLY OWNER OR MANAGER CAN CHANGE ]
+func (c ValidatorRegistry) MovePoolToNode(opts CallOpts, validatorId uint64, poolAppId uint64, nodeNum uint64) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryMovePoolToNode, validatorId, poolAppId, nodeNum)
+}
+
+// EmptyTokenRewards returns the params for calling emptyTokenRewards(uint64,address)uint64.
+//
+// Sends the reward tokens held in pool 1 to specified receiver.
+// This is intended to be used by the owner when they want to get reward tokens 'back' which they sent to
+// the first pool (likely because validator is sunsetting.  Any tokens currently 'reserved' for stakers to claim will
+// NOT be sent as they must be held back for stakers to later claim.
+// [ ONLY OWNER CAN CALL]
+func (c ValidatorRegistry) EmptyTokenRewards(opts CallOpts, validatorId uint64, receiver types.Address) transaction.AddMethodCallParams {
+	return opts.methodCall(c.AppID, methodValidatorRegistryEmptyTokenRewards, validatorId, receiver)
+}
+
+// EmptyTokenRewardsResult decodes the return value of a emptyTokenRewards call.
+//
+// uint64 the amount of reward token sent
+func (c ValidatorRegistry) EmptyTokenRewardsResult(result transaction.ABIMethodResult) (uint64, error) {
+	return decodeResult(result, decodeUint64)
+}
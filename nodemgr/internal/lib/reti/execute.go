@@ -0,0 +1,164 @@
+package reti
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+// TxnResult describes the outcome of a transaction group submitted by the client
+type TxnResult struct {
+	// Method is the name of the contract method called
+	Method string
+	// AppID is the application called - the validator registry or a staking pool
+	AppID uint64
+	// Detail is optional call specific information (ie: staker being removed)
+	Detail string
+	TxIDs  []string
+	// Round the group was confirmed in - 0 if it wasn't
+	Round uint64
+	// Fees is the total fees of all the transactions in the group (not including inner transactions)
+	Fees uint64
+	// Spent is the fees plus algo payments of the transactions in the group, keyed by sender
+	Spent map[string]uint64
+	// DryRun is true if the group was only simulated (Err is the simulation failure, if any)
+	DryRun bool
+	// Exported is true if the group was only written (unsigned) to the export file, to be signed elsewhere
+	Exported bool
+	Err      error
+}
+
+// TxnObserver is called with the outcome of every transaction group the client submits (or tries to)
+type TxnObserver func(result TxnResult)
+
+// AddTxnObserver adds an observer to be called after every transaction group is executed
+func (r *Reti) AddTxnObserver(observer TxnObserver) {
+	r.Lock()
+	defer r.Unlock()
+	r.observers = append(r.observers, observer)
+}
+
+// EnableDryRun puts the client into dry-run mode - transaction groups are built and simulated, but never signed
+// or sent.
+func (r *Reti) EnableDryRun() {
+	r.Lock()
+	defer r.Unlock()
+	r.dryRun = true
+	r.signer = algo.NewDryRunSigner(r.signer)
+}
+
+// DryRun returns true if the client is in dry-run mode
+func (r *Reti) DryRun() bool {
+	r.RLock()
+	defer r.RUnlock()
+	return r.dryRun
+}
+
+// ExportUnsigned puts the client into export mode - rather than being signed and sent, transaction groups are
+// written (unsigned) to path, to be signed elsewhere (ie: by the members of a multisig) and submitted later.
+func (r *Reti) ExportUnsigned(path string) {
+	r.Lock()
+	defer r.Unlock()
+	r.exportPath = path
+}
+
+func (r *Reti) exportFile() string {
+	r.RLock()
+	defer r.RUnlock()
+	return r.exportPath
+}
+
+// execute sends the transaction group, waiting for confirmation, and then notifies any observers of the result.
+// In dry-run mode, the group is only simulated, and in export mode it's only written to the export file.
+func (r *Reti) execute(group *algo.Group, method string, appID uint64, detail string) (transaction.ExecuteResult, error) {
+	exportPath := r.exportFile()
+	txnResult := TxnResult{Method: method, AppID: appID, Detail: detail, Spent: map[string]uint64{}, DryRun: r.DryRun(), Exported: exportPath != ""}
+
+	result, err := func() (transaction.ExecuteResult, error) {
+		txns, err := group.Transactions()
+		if err != nil {
+			return transaction.ExecuteResult{}, err
+		}
+		for _, txn := range txns {
+			txnResult.Fees += uint64(txn.Txn.Fee)
+			txnResult.TxIDs = append(txnResult.TxIDs, crypto.GetTxID(txn.Txn))
+			spent := uint64(txn.Txn.Fee)
+			if txn.Txn.Type == types.PaymentTx {
+				spent += uint64(txn.Txn.Amount)
+			}
+			txnResult.Spent[txn.Txn.Sender.String()] += spent
+		}
+		if txnResult.DryRun {
+			return transaction.ExecuteResult{}, r.simulate(group, method, appID, detail)
+		}
+		if txnResult.Exported {
+			return transaction.ExecuteResult{TxIDs: txnResult.TxIDs}, r.export(exportPath, txns, method, appID, detail)
+		}
+		return group.Execute(context.Background(), r.chain, 4)
+	}()
+	txnResult.Round = result.ConfirmedRound
+	txnResult.Err = err
+
+	r.RLock()
+	observers := r.observers
+	r.RUnlock()
+	for _, observer := range observers {
+		observer(txnResult)
+	}
+	return result, err
+}
+
+// simulate simulates (w/ empty signatures) the group, reporting what would have been done
+func (r *Reti) simulate(group *algo.Group, method string, appID uint64, detail string) error {
+	simResult, err := group.Simulate(context.Background(), r.chain, models.SimulateRequest{
+		AllowEmptySignatures: true,
+	})
+	if err == nil && simResult.SimulateResponse.TxnGroups[0].FailureMessage != "" {
+		err = errors.New(simResult.SimulateResponse.TxnGroups[0].FailureMessage)
+	}
+	if err != nil {
+		promDryRunSimulations.WithLabelValues(method, "failed").Inc()
+		misc.Warnf(r.Logger, "[DRY-RUN] would have called %s on app id:%d (%s) - simulation FAILED, err:%v", method, appID, detail, err)
+		return fmt.Errorf("dry-run simulation of %s failed: %w", method, err)
+	}
+	promDryRunSimulations.WithLabelValues(method, "passed").Inc()
+	misc.Infof(r.Logger, "[DRY-RUN] would have called %s on app id:%d (%s) - simulation passed", method, appID, detail)
+	return nil
+}
+
+// export writes the unsigned transactions of the group to path - w/ the auth address of any rekeyed senders set,
+// so they're signed by the right key(s).
+func (r *Reti) export(path string, txns []transaction.TransactionWithSigner, method string, appID uint64, detail string) error {
+	var (
+		unsigned  []types.SignedTxn
+		lastValid types.Round
+	)
+	for _, txn := range txns {
+		stxn := types.SignedTxn{Txn: txn.Txn}
+		authAddr, err := algo.AuthAddress(context.Background(), r.chain, txn.Txn.Sender.String())
+		if err != nil {
+			return err
+		}
+		if authAddr != txn.Txn.Sender.String() {
+			if stxn.AuthAddr, err = types.DecodeAddress(authAddr); err != nil {
+				return err
+			}
+		}
+		unsigned = append(unsigned, stxn)
+		lastValid = txn.Txn.LastValid
+	}
+	if err := os.WriteFile(path, algo.EncodeSignedGroup(unsigned), 0o644); err != nil {
+		return fmt.Errorf("unable to export transactions: %w", err)
+	}
+	misc.Infof(r.Logger, "Exported unsigned %s call on app id:%d (%s) to %s - it must be signed and submitted by round %d", method, appID, detail, path, lastValid)
+	return nil
+}
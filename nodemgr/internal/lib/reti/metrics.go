@@ -30,4 +30,9 @@ var (
 		Subsystem: "reti",
 		Name:      "max_stake_allowed_total",
 	})
+	promDryRunSimulations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "reti",
+		Name:      "dryrun_simulations_total",
+		Help:      "Transaction groups simulated in dry-run mode, by method and whether simulation passed",
+	}, []string{"method", "result"})
 )
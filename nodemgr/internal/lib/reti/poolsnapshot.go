@@ -0,0 +1,165 @@
+package reti
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/mailgun/holster/v4/syncutil"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+)
+
+// PoolSnapshot is the state of a staking pool, decoded from a single fetch of the pool app's global state and a
+// single fetch of the pool's account.  Snapshots are cached (per round) and shared, so must not be modified.
+type PoolSnapshot struct {
+	PoolAppID uint64
+	// Round is the round the pool account was fetched at
+	Round uint64
+
+	CreatorApp    uint64
+	ValidatorID   uint64
+	PoolID        uint64
+	NumStakers    uint64
+	Staked        uint64
+	MinEntryStake uint64
+	MaxStake      uint64
+	LastPayout    uint64
+	// AlgodVer is empty if the version has never been set in the pool
+	AlgodVer string
+	// EWMA is the average APR w/ 4 decimals, ie: 52000 = 5.2%
+	EWMA *big.Int
+	// StakeAccum is the accumulated (stake * rounds) within the current epoch
+	StakeAccum *big.Int
+
+	Account models.Account
+}
+
+// AvailableRewards returns the pool balance beyond its stake and MBR - ie: what would be paid out as rewards
+func (s *PoolSnapshot) AvailableRewards() uint64 {
+	if s.Account.Amount < s.Staked+s.Account.MinBalance {
+		// pool isn't properly initialized yet - so don't underflow on 'reward amount'
+		return 0
+	}
+	return s.Account.Amount - s.Staked - s.Account.MinBalance
+}
+
+// APR returns the average APR of the pool as a percentage
+func (s *PoolSnapshot) APR() *big.Float {
+	return new(big.Float).Quo(new(big.Float).SetInt(s.EWMA), big.NewFloat(10000.0))
+}
+
+// GetPoolSnapshot returns the snapshot of a single staking pool - see GetPoolSnapshots
+func (r *Reti) GetPoolSnapshot(ctx context.Context, poolAppID uint64) (*PoolSnapshot, error) {
+	snapshots, err := r.GetPoolSnapshots(ctx, []uint64{poolAppID})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots[0], nil
+}
+
+// GetPoolSnapshots returns snapshots of the specified staking pools (in the same order), fetching them concurrently.
+// Snapshots already fetched in the current round are returned from the cache.
+func (r *Reti) GetPoolSnapshots(ctx context.Context, poolAppIDs []uint64) ([]*PoolSnapshot, error) {
+	status, err := r.chain.Status(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get algod status: %w", err)
+	}
+
+	var (
+		snapshots = make([]*PoolSnapshot, len(poolAppIDs))
+		wg        syncutil.WaitGroup
+	)
+	for i, poolAppID := range poolAppIDs {
+		if snapshot := r.cachedSnapshot(poolAppID, status.LastRound); snapshot != nil {
+			snapshots[i] = snapshot
+			continue
+		}
+		wg.Run(func(val any) error {
+			snapshot, err := r.fetchPoolSnapshot(ctx, poolAppID)
+			if err != nil {
+				return fmt.Errorf("unable to fetch state of staking pool app id:%d: %w", poolAppID, err)
+			}
+			r.cacheSnapshot(snapshot, status.LastRound)
+			snapshots[i] = snapshot
+			return nil
+		}, nil)
+	}
+	if errs := wg.Wait(); len(errs) > 0 {
+		return nil, errs[0]
+	}
+	return snapshots, nil
+}
+
+type cachedSnapshot struct {
+	round    uint64
+	snapshot *PoolSnapshot
+}
+
+func (r *Reti) cachedSnapshot(poolAppID uint64, round uint64) *PoolSnapshot {
+	r.snapshotLock.Lock()
+	defer r.snapshotLock.Unlock()
+	if cached, found := r.snapshots[poolAppID]; found && cached.round == round {
+		return cached.snapshot
+	}
+	return nil
+}
+
+func (r *Reti) cacheSnapshot(snapshot *PoolSnapshot, round uint64) {
+	r.snapshotLock.Lock()
+	defer r.snapshotLock.Unlock()
+	if r.snapshots == nil {
+		r.snapshots = map[uint64]cachedSnapshot{}
+	}
+	r.snapshots[snapshot.PoolAppID] = cachedSnapshot{round: round, snapshot: snapshot}
+}
+
+func (r *Reti) fetchPoolSnapshot(ctx context.Context, poolAppID uint64) (*PoolSnapshot, error) {
+	appInfo, err := r.chain.Application(ctx, poolAppID)
+	if err != nil {
+		return nil, err
+	}
+	account, err := algo.GetBareAccount(ctx, r.chain, crypto.GetApplicationAddress(poolAppID).String())
+	if err != nil {
+		return nil, err
+	}
+	snapshot := &PoolSnapshot{
+		PoolAppID:  poolAppID,
+		Round:      account.Round,
+		EWMA:       new(big.Int),
+		StakeAccum: new(big.Int),
+		Account:    account,
+	}
+	for _, gs := range appInfo.Params.GlobalState {
+		rawKey, _ := base64.StdEncoding.DecodeString(gs.Key)
+		value, _ := base64.StdEncoding.DecodeString(gs.Value.Bytes)
+		switch string(rawKey) {
+		case StakePoolCreatorApp:
+			snapshot.CreatorApp = gs.Value.Uint
+		case StakePoolValidatorId:
+			snapshot.ValidatorID = gs.Value.Uint
+		case StakePoolPoolId:
+			snapshot.PoolID = gs.Value.Uint
+		case StakePoolNumStakers:
+			snapshot.NumStakers = gs.Value.Uint
+		case StakePoolStaked:
+			snapshot.Staked = gs.Value.Uint
+		case StakePoolMinEntryStake:
+			snapshot.MinEntryStake = gs.Value.Uint
+		case StakePoolMaxStake:
+			snapshot.MaxStake = gs.Value.Uint
+		case StakePoolLastPayout:
+			snapshot.LastPayout = gs.Value.Uint
+		case StakePoolAlgodVer:
+			snapshot.AlgodVer = string(value)
+		case StakePoolEWMA:
+			snapshot.EWMA.SetBytes(value)
+		case StakePoolStakeAccum:
+			snapshot.StakeAccum.SetBytes(value)
+		}
+	}
+	return snapshot, nil
+}
@@ -2,25 +2,22 @@ package reti
 
 import (
 	"context"
-	"embed"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
 
-	"github.com/algorand/go-algorand-sdk/v2/abi"
-	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 
 	"github.com/TxnLab/reti/internal/lib/algo"
 	"github.com/TxnLab/reti/internal/lib/misc"
+	"github.com/TxnLab/reti/internal/lib/reti/contracts"
 )
 
 type Reti struct {
-	Logger     *slog.Logger
-	algoClient *algod.Client
-	signer     algo.MultipleWalletSigner
+	Logger *slog.Logger
+	chain  algo.Chain
+	signer algo.MultipleWalletSigner
 
 	// RetiAppId is simply the master validator contract id
 	RetiAppId   uint64
@@ -29,13 +26,19 @@ type Reti struct {
 
 	poolTmplAppId uint64
 
-	validatorContract *abi.Contract
-	poolContract      *abi.Contract
-
 	// Loaded from on-chain state at start and on-demand via LoadStateFromChain
 	// Mutex wrap is just lazy way of allowing single shared-state of instance data that's periodically updated
 	sync.RWMutex
-	info ValidatorInfo
+	info      ValidatorInfo
+	observers []TxnObserver
+	// dryRun is true if transactions are only simulated, never sent
+	dryRun bool
+	// exportPath, if set, is the file transaction groups are written to (unsigned) rather than being sent
+	exportPath string
+
+	// pool snapshots, by pool app id - only valid for the round they were fetched in
+	snapshotLock sync.Mutex
+	snapshots    map[uint64]cachedSnapshot
 }
 
 func (r *Reti) Info() ValidatorInfo {
@@ -44,7 +47,9 @@ func (r *Reti) Info() ValidatorInfo {
 	return r.info
 }
 
-func (r *Reti) setInfo(Info ValidatorInfo) {
+// SetInfo replaces the validator state - normally it's loaded from the chain via LoadState, but this allows
+// using the client against state that isn't on chain (ie: an algo.FakeLedger).
+func (r *Reti) SetInfo(Info ValidatorInfo) {
 	r.Lock()
 	defer r.Unlock()
 	r.info = Info
@@ -53,7 +58,7 @@ func (r *Reti) setInfo(Info ValidatorInfo) {
 func New(
 	validatorAppId uint64,
 	logger *slog.Logger,
-	algoClient *algod.Client,
+	chain algo.Chain,
 	signer algo.MultipleWalletSigner,
 	validatorId uint64,
 	nodeNum uint64,
@@ -64,20 +69,10 @@ func New(
 		ValidatorId: validatorId,
 		NodeNum:     nodeNum,
 
-		Logger:     logger,
-		algoClient: algoClient,
-		signer:     signer,
-	}
-	validatorContract, err := loadContract("artifacts/contracts/ValidatorRegistry.arc32.json")
-	if err != nil {
-		return nil, err
+		Logger: logger,
+		chain:  chain,
+		signer: signer,
 	}
-	poolContract, err := loadContract("artifacts/contracts/StakingPool.arc32.json")
-	if err != nil {
-		return nil, err
-	}
-	retReti.validatorContract = validatorContract
-	retReti.poolContract = poolContract
 
 	misc.Infof(logger, "client initialized, Protocol App id:%d, Validator id:%d, Node Number:%d", validatorAppId, validatorId, nodeNum)
 
@@ -97,7 +92,7 @@ func (r *Reti) LoadState(ctx context.Context) error {
 	if r.RetiAppId == 0 {
 		return errors.New("reti App id not defined")
 	}
-	appInfo, err := r.algoClient.GetApplicationByID(r.RetiAppId).Do(ctx)
+	appInfo, err := r.chain.Application(ctx, r.RetiAppId)
 	if err != nil {
 		return err
 	}
@@ -116,10 +111,12 @@ func (r *Reti) LoadState(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("unable to GetValidatorConfig: %w", err)
 		}
-		// verify this validator is one we have either owner or manager keys for !!
+		// verify this validator is one we have either owner or manager keys for !! (or the keys of the accounts
+		// they're rekeyed to)
 		_, err = r.signer.FindFirstSigner([]string{config.Owner, config.Manager})
 		if err != nil {
-			return fmt.Errorf("neither owner or manager address for validator id:%d has local keys present", r.ValidatorId)
+			return fmt.Errorf("neither owner:%s or manager:%s address for validator id:%d has local keys present",
+				algo.DescribeSigningAccount(r.signer, config.Owner), algo.DescribeSigningAccount(r.signer, config.Manager), r.ValidatorId)
 		}
 		constraints, err := r.GetProtocolConstraints()
 		if err != nil {
@@ -156,14 +153,19 @@ func (r *Reti) LoadState(ctx context.Context) error {
 			localStakers      uint64
 			localTotalStaked  uint64
 			localTotalRewards float64
+			localPoolAppIDs   = newInfo.NodePoolAssignments.Nodes[r.NodeNum-1].PoolAppIds
 		)
-		for _, poolAppID := range newInfo.NodePoolAssignments.Nodes[r.NodeNum-1].PoolAppIds {
+		snapshots, err := r.GetPoolSnapshots(ctx, localPoolAppIDs)
+		if err != nil {
+			return err
+		}
+		for localIdx, poolAppID := range localPoolAppIDs {
 			var poolID uint64
 			for poolIdx, pool := range pools {
 				if pool.PoolAppId == poolAppID {
 					localStakers += uint64(pool.TotalStakers)
 					localTotalStaked += pool.TotalAlgoStaked
-					localTotalRewards += float64(r.PoolAvailableRewards(pool.PoolAppId, pool.TotalAlgoStaked)) / 1e6
+					localTotalRewards += float64(snapshots[localIdx].AvailableRewards()) / 1e6
 
 					poolID = uint64(poolIdx + 1)
 					break
@@ -185,38 +187,31 @@ func (r *Reti) LoadState(ctx context.Context) error {
 		promAmtConsideredSaturated.Set(float64(constraints.AmtConsideredSaturated) / 1e6)
 		promMaxStakeAllowed.Set(float64(constraints.MaxAlgoPerValidator) / 1e6)
 
-		r.setInfo(newInfo)
+		r.SetInfo(newInfo)
 	}
 	return nil
 }
 
-func (r *Reti) getLocalSignerForSimulateCalls() (types.Address, error) {
-	return DummyAlgoSender, nil
+// registry returns the client for the (master) validator registry contract
+func (r *Reti) registry() contracts.ValidatorRegistry {
+	return contracts.ValidatorRegistry{AppID: r.RetiAppId}
 }
 
-//go:embed artifacts/contracts/ValidatorRegistry.arc32.json
-//go:embed artifacts/contracts/StakingPool.arc32.json
-var embeddedF embed.FS
-
-func loadContract(fname string) (*abi.Contract, error) {
-	data, err := embeddedF.ReadFile(fname)
-	if err != nil {
-		return nil, err
+// callOpts returns the options for contract method calls sent by (and signed for) sender
+func (r *Reti) callOpts(params types.SuggestedParams, sender types.Address) contracts.CallOpts {
+	return contracts.CallOpts{
+		Sender:          sender,
+		Signer:          algo.SignWithAccountForATC(r.signer, sender.String()),
+		SuggestedParams: params,
 	}
-	return loadContractFromArc32(data)
-}
-
-// ABIContractWrap struct is just so we can unmarshal an arc32 document into the abi.contract type
-// we ignore everything else in arc32
-type ABIContractWrap struct {
-	Contract abi.Contract `json:"contract"`
 }
 
-func loadContractFromArc32(arc32 []byte) (*abi.Contract, error) {
-	var contractWrap ABIContractWrap
-	err := json.Unmarshal(arc32, &contractWrap)
+// readOnlyOpts returns the options for simulating read-only contract methods.  They're never signed, so the
+// sender can be any account.
+func (r *Reti) readOnlyOpts(sender types.Address) (contracts.CallOpts, error) {
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
-		return nil, err
+		return contracts.CallOpts{}, err
 	}
-	return &contractWrap.Contract, nil
+	return contracts.CallOpts{Sender: sender, SuggestedParams: params}, nil
 }
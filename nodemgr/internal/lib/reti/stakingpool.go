@@ -3,17 +3,15 @@ package reti
 import (
 	"context"
 	"encoding/binary"
-	"errors"
 	"fmt"
-	"math/big"
 
-	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/v2/crypto"
 	"github.com/algorand/go-algorand-sdk/v2/transaction"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 
 	"github.com/TxnLab/reti/internal/lib/algo"
 	"github.com/TxnLab/reti/internal/lib/misc"
+	"github.com/TxnLab/reti/internal/lib/reti/contracts"
 )
 
 type StakedInfo struct {
@@ -26,7 +24,7 @@ type StakedInfo struct {
 
 func (r *Reti) GetLedgerForPool(poolAppID uint64) ([]StakedInfo, error) {
 	var retLedger []StakedInfo
-	boxData, err := r.algoClient.GetApplicationBoxByName(poolAppID, GetStakerLedgerBoxName()).Do(context.Background())
+	boxData, err := r.chain.ApplicationBox(context.Background(), poolAppID, GetStakerLedgerBoxName())
 	if err != nil {
 		return nil, err
 	}
@@ -48,79 +46,109 @@ func (r *Reti) GetLedgerForPool(poolAppID uint64) ([]StakedInfo, error) {
 	return retLedger, nil
 }
 
-func (r *Reti) GetPoolID(poolAppID uint64) (uint64, error) {
-	appInfo, err := r.algoClient.GetApplicationByID(poolAppID).Do(context.Background())
+// GetStakerInfo returns the staking data of a single staker in a pool - an error if they're not in the pool
+func (r *Reti) GetStakerInfo(poolAppID uint64, staker types.Address) (StakedInfo, error) {
+	opts, err := r.readOnlyOpts(staker)
 	if err != nil {
-		return 0, err
+		return StakedInfo{}, err
 	}
-	return algo.GetUint64FromGlobalState(appInfo.Params.GlobalState, StakePoolPoolId)
+	pool := contracts.StakingPool{AppID: poolAppID}
+	result, err := contracts.Simulate(context.Background(), r.chain, pool.GetStakerInfo(opts, staker))
+	if err != nil {
+		return StakedInfo{}, err
+	}
+	info, err := pool.GetStakerInfoResult(result)
+	if err != nil {
+		return StakedInfo{}, err
+	}
+	return StakedInfo(info), nil
 }
 
-func (r *Reti) GetLastPayout(poolAppID uint64) (uint64, error) {
-	appInfo, err := r.algoClient.GetApplicationByID(poolAppID).Do(context.Background())
+// ClaimTokens sends the staker all the reward tokens they've accrued in the specified pool
+func (r *Reti) ClaimTokens(poolKey ValidatorPoolKey, staker types.Address) error {
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
-		return 0, err
+		return err
 	}
-	return algo.GetUint64FromGlobalState(appInfo.Params.GlobalState, StakePoolLastPayout)
-}
+	params.LastRoundValid = params.FirstRoundValid + 100
 
-func (r *Reti) GetAvgApr(poolAppID uint64) (*big.Int, error) {
-	appInfo, err := r.algoClient.GetApplicationByID(poolAppID).Do(context.Background())
+	comp := algo.Composer{}
+	opts := r.callOpts(params, staker)
+	// the gas calls give the group room for all the references paying out reward tokens needs (pooled across the group)
+	for range 2 {
+		comp.AddMethodCall(r.registry().Gas(opts))
+	}
+	comp.AddMethodCall(contracts.StakingPool{AppID: poolKey.PoolAppId}.ClaimTokens(opts))
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return algo.GetUint128FromGlobalState(appInfo.Params.GlobalState, StakePoolEWMA)
+
+	_, err = r.execute(group, "claimTokens", poolKey.PoolAppId, fmt.Sprintf("staker:%s", staker))
+	return err
 }
 
-func (r *Reti) GetStakeAccum(poolAppID uint64) (*big.Int, error) {
-	appInfo, err := r.algoClient.GetApplicationByID(poolAppID).Do(context.Background())
+// nfdAddressBoxMbr is the MBR of the NFD box storage a new verified algorand address needs, paid by the NFD owner
+const nfdAddressBoxMbr = 20_500
+
+// LinkPoolToNFD adds the pool's account to the NFD as a verified algorand address.  The NFD owner sets the pool
+// address as the NFD's pending verified address (paying the box MBR), and the pool then verifies it through the NFD
+// registry.  caller must be the owner or manager of the validator (which can be the same account as nfdOwner).
+func (r *Reti) LinkPoolToNFD(poolAppID uint64, nfdAppID uint64, nfdName string, nfdOwner types.Address, caller types.Address) error {
+	// the pool calls the NFD registry it was created with, which must be referenced along with the NFD itself
+	registryAppID, err := r.GetNFDRegistryID()
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("unable to get nfd registry id: %w", err)
+	}
+	params, err := r.chain.SuggestedParams(context.Background())
+	if err != nil {
+		return err
+	}
+	poolAddress := crypto.GetApplicationAddress(poolAppID)
+	ownerSigner := algo.SignWithAccountForATC(r.signer, nfdOwner.String())
+
+	payTxn, err := transaction.MakePaymentTxn(nfdOwner.String(), crypto.GetApplicationAddress(nfdAppID).String(), nfdAddressBoxMbr, nil, "", params)
+	if err != nil {
+		return err
+	}
+	updateTxn, err := transaction.MakeApplicationNoOpTx(nfdAppID, [][]byte{[]byte("update_field"), []byte("u.cav.algo.a"), poolAddress[:]},
+		nil, nil, nil, params, nfdOwner, nil, types.Digest{}, [32]byte{}, types.ZeroAddress)
+	if err != nil {
+		return err
 	}
-	return algo.GetUint128FromGlobalState(appInfo.Params.GlobalState, StakePoolStakeAccum)
-}
 
-func (r *Reti) GetAlgodVer(poolAppID uint64) (string, error) {
-	appInfo, err := r.algoClient.GetApplicationByID(poolAppID).Do(context.Background())
+	comp := algo.Composer{}
+	comp.AddTransaction(transaction.TransactionWithSigner{Txn: payTxn, Signer: ownerSigner})
+	comp.AddTransaction(transaction.TransactionWithSigner{Txn: updateTxn, Signer: ownerSigner})
+	linkParams := contracts.StakingPool{AppID: poolAppID}.LinkToNFD(r.callOpts(params, caller), nfdAppID, nfdName)
+	linkParams.ForeignApps = []uint64{registryAppID, nfdAppID}
+	comp.AddMethodCall(linkParams)
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
-		return "", err
+		return err
 	}
-	return algo.GetStringFromGlobalState(appInfo.Params.GlobalState, StakePoolAlgodVer)
+
+	_, err = r.execute(group, "linkToNFD", poolAppID, nfdName)
+	return err
 }
 
 func (r *Reti) UpdateAlgodVer(poolAppID uint64, algodVer string, caller types.Address) error {
 	var err error
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
 
-	atc := transaction.AtomicTransactionComposer{}
-	updateAlgodVerMethod, _ := r.poolContract.GetMethodByName("updateAlgodVer")
-
-	params.FlatFee = true
-	params.Fee = transaction.MinTxnFee * 2
-
-	err = atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:       poolAppID,
-		Method:      updateAlgodVerMethod,
-		MethodArgs:  []any{algodVer},
-		ForeignApps: []uint64{r.RetiAppId},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: r.RetiAppId, Name: GetValidatorListBoxName(r.ValidatorId)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          caller,
-		Signer:          algo.SignWithAccountForATC(r.signer, caller.String()),
-	})
+	comp := algo.Composer{}
+	pool := contracts.StakingPool{AppID: poolAppID}
+	comp.AddMethodCall(pool.UpdateAlgodVer(r.callOpts(params, caller), algodVer))
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
 		return err
 	}
 
-	_, err = atc.Execute(r.algoClient, context.Background(), 4)
+	_, err = r.execute(group, "updateAlgodVer", poolAppID, algodVer)
 	if err != nil {
 		return err
 	}
@@ -134,13 +162,12 @@ func (r *Reti) EpochBalanceUpdate(poolID int, poolAppID uint64, caller types.Add
 	)
 
 	// make sure we even have enough rewards to do the payout
-	pools, err := r.GetValidatorPools(r.ValidatorId)
+	snapshot, err := r.GetPoolSnapshot(context.Background(), poolAppID)
 	if err != nil {
-		return fmt.Errorf("failed to get validator pools: %w", err)
+		return err
 	}
-	rewardAvail := r.PoolAvailableRewards(poolAppID, pools[poolID-1].TotalAlgoStaked)
 
-	status, err := r.algoClient.Status().Do(context.Background())
+	status, err := r.chain.Status(context.Background())
 	if err != nil {
 		return fmt.Errorf("failed to get algod status at start: %w", err)
 	}
@@ -151,13 +178,9 @@ func (r *Reti) EpochBalanceUpdate(poolID int, poolAppID uint64, caller types.Add
 	} else {
 		epochStr = fmt.Sprintf("EpochStart:%d", epochStart)
 	}
-	apr, _ := r.GetAvgApr(poolAppID)
-	floatApr, _, _ := new(big.Float).Parse(apr.String(), 10)
-	floatApr.Quo(floatApr, big.NewFloat(10000.0))
-
-	misc.Infof(r.Logger, "[EpochBalanceUpdate] pool:%d epoch update at %s for app id:%d, avail rewards:%s, pre-epoch apr:%s", poolID, epochStr, poolAppID, algo.FormattedAlgoAmount(rewardAvail), floatApr.String())
+	misc.Infof(r.Logger, "[EpochBalanceUpdate] pool:%d epoch update at %s for app id:%d, avail rewards:%s, pre-epoch apr:%s", poolID, epochStr, poolAppID, algo.FormattedAlgoAmount(snapshot.AvailableRewards()), snapshot.APR().String())
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
@@ -165,118 +188,21 @@ func (r *Reti) EpochBalanceUpdate(poolID int, poolAppID uint64, caller types.Add
 	// reduce validity window - good practice - but also required by the reti contracts to have smaller validity window so accessing prior blocks works
 	params.LastRoundValid = params.FirstRoundValid + 100
 
-	getAtc := func(feesToUse uint64) (transaction.AtomicTransactionComposer, error) {
-		atc := transaction.AtomicTransactionComposer{}
-		gasMethod, _ := r.poolContract.GetMethodByName("gas")
-		epochUpdateMethod, _ := r.poolContract.GetMethodByName("epochBalanceUpdate")
-
-		newParams := params
-
-		newParams.FlatFee = true
-		newParams.Fee = 0
-
-		extraApps := []uint64{}
-		extraAssets := []uint64{}
-
-		if r.info.Config.NFDForInfo != 0 {
-			extraApps = append(extraApps, r.info.Config.NFDForInfo)
-		}
-		if r.info.Config.RewardTokenId != 0 {
-			extraAssets = append(extraAssets, r.info.Config.RewardTokenId)
-			if poolID != 1 {
-				// If not pool 1 then we need to add reference for pool 1, so it can be called to update the pool token payout ratio
-				extraApps = append(extraApps, r.info.Pools[0].PoolAppId)
-			}
-		}
-
-		// we need to stack up references in these two gas methods for resource pooling
-		err = atc.AddMethodCall(transaction.AddMethodCallParams{
-			AppID:       poolAppID,
-			Method:      gasMethod,
-			ForeignApps: []uint64{r.RetiAppId},
-			BoxReferences: []types.AppBoxReference{
-				{AppID: r.RetiAppId, Name: GetValidatorListBoxName(r.ValidatorId)},
-				{AppID: 0, Name: GetStakerLedgerBoxName()},
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-			},
-			SuggestedParams: newParams,
-			OnComplete:      types.NoOpOC,
-			Sender:          caller,
-			Signer:          algo.SignWithAccountForATC(r.signer, caller.String()),
-		})
-		if err != nil {
-			return atc, err
-		}
-		err = atc.AddMethodCall(transaction.AddMethodCallParams{
-			AppID:         poolAppID,
-			Method:        gasMethod,
-			ForeignAssets: extraAssets,
-			ForeignApps:   extraApps,
-			ForeignAccounts: []string{
-				info.Config.ValidatorCommissionAddress,
-				r.info.Config.Manager,
-			},
-			SuggestedParams: newParams,
-			OnComplete:      types.NoOpOC,
-			Sender:          caller,
-			Signer:          algo.SignWithAccountForATC(r.signer, caller.String()),
-		})
-		if err != nil {
-			return atc, err
-		}
-		if feesToUse == 0 {
-			// we're simulating so go with super high budget
-			feesToUse = 240 * transaction.MinTxnFee
-		}
-		newParams.Fee = types.MicroAlgos(feesToUse)
-		err = atc.AddMethodCall(transaction.AddMethodCallParams{
-			AppID:  poolAppID,
-			Method: epochUpdateMethod,
-			BoxReferences: []types.AppBoxReference{
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-			},
-			SuggestedParams: newParams,
-			OnComplete:      types.NoOpOC,
-			Sender:          caller,
-			Signer:          algo.SignWithAccountForATC(r.signer, caller.String()),
-		})
-		if err != nil {
-			return atc, err
-		}
-		return atc, err
-	}
-
-	// simulate first
-	atc, err := getAtc(0)
-	if err != nil {
-		return err
-	}
-	simResult, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowUnnamedResources: true,
-	})
-	if err != nil {
-		return err
-	}
-	if simResult.SimulateResponse.TxnGroups[0].FailureMessage != "" {
-		return errors.New(simResult.SimulateResponse.TxnGroups[0].FailureMessage)
+	comp := algo.Composer{}
+	pool := contracts.StakingPool{AppID: poolAppID}
+	opts := r.callOpts(params, caller)
+
+	// the gas calls just give the group room for all the references the epoch update needs (pooled across the group)
+	for range 2 {
+		comp.AddMethodCall(pool.Gas(opts))
 	}
-	// Figure out how much app budget was added so we can know the real fees to use when we execute
-	atc, err = getAtc(transaction.MinTxnFee * (simResult.SimulateResponse.TxnGroups[0].AppBudgetAdded / 700))
+	comp.AddMethodCall(pool.EpochBalanceUpdate(opts))
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
 		return err
 	}
 
-	_, err = atc.Execute(r.algoClient, context.Background(), 4)
+	_, err = r.execute(group, "epochBalanceUpdate", poolAppID, fmt.Sprintf("pool:%d", poolID))
 	if err != nil {
 		return err
 	}
@@ -290,19 +216,16 @@ func (r *Reti) GoOnline(poolAppID uint64, caller types.Address, votePK []byte, s
 		goOnlineFee uint64 = 0
 	)
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
 
-	atc := transaction.AtomicTransactionComposer{}
-	goOnlineMethod, _ := r.poolContract.GetMethodByName("goOnline")
-
-	params.FlatFee = true
-	params.Fee = transaction.MinTxnFee * 3
+	comp := algo.Composer{}
+	pool := contracts.StakingPool{AppID: poolAppID}
 
 	// if account isn't currently incentive eligible, we need to pay the extra fee
-	account, err := algo.GetBareAccount(context.Background(), r.algoClient, poolAddress)
+	account, err := algo.GetBareAccount(context.Background(), r.chain, poolAddress)
 	if err != nil {
 		return err
 	}
@@ -313,40 +236,22 @@ func (r *Reti) GoOnline(poolAppID uint64, caller types.Address, votePK []byte, s
 	}
 
 	paymentTxn, err := transaction.MakePaymentTxn(caller.String(), poolAddress, goOnlineFee, nil, "", params)
+	if err != nil {
+		return err
+	}
 	payTxWithSigner := transaction.TransactionWithSigner{
 		Txn:    paymentTxn,
 		Signer: algo.SignWithAccountForATC(r.signer, caller.String()),
 	}
 
-	err = atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:  poolAppID,
-		Method: goOnlineMethod,
-		MethodArgs: []any{
-			// -- payment transaction to cover fee of going online (if needed)
-			payTxWithSigner,
-			//
-			votePK,
-			selectionPK,
-			stateProofPK,
-			voteFirst,
-			voteLast,
-			voteKeyDilution,
-		},
-		ForeignApps: []uint64{r.RetiAppId},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: r.RetiAppId, Name: GetValidatorListBoxName(r.ValidatorId)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          caller,
-		Signer:          algo.SignWithAccountForATC(r.signer, caller.String()),
-	})
+	// the payment covers the fee of going online (if needed)
+	comp.AddMethodCall(pool.GoOnline(r.callOpts(params, caller), payTxWithSigner, votePK, selectionPK, stateProofPK, voteFirst, voteLast, voteKeyDilution))
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
 		return err
 	}
 
-	result, err := atc.Execute(r.algoClient, context.Background(), 4)
+	result, err := r.execute(group, "goOnline", poolAppID, fmt.Sprintf("vote first:%d, last:%d", voteFirst, voteLast))
 	if err != nil {
 		return err
 	}
@@ -357,51 +262,22 @@ func (r *Reti) GoOnline(poolAppID uint64, caller types.Address, votePK []byte, s
 func (r *Reti) GoOffline(poolAppID uint64, caller types.Address) error {
 	var err error
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
 
-	atc := transaction.AtomicTransactionComposer{}
-	goOfflineMethod, _ := r.poolContract.GetMethodByName("goOffline")
-
-	params.FlatFee = true
-	params.Fee = transaction.MinTxnFee * 3
-
-	err = atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:       poolAppID,
-		Method:      goOfflineMethod,
-		ForeignApps: []uint64{r.RetiAppId},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: r.RetiAppId, Name: GetValidatorListBoxName(r.ValidatorId)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          caller,
-		Signer:          algo.SignWithAccountForATC(r.signer, caller.String()),
-	})
+	comp := algo.Composer{}
+	pool := contracts.StakingPool{AppID: poolAppID}
+	comp.AddMethodCall(pool.GoOffline(r.callOpts(params, caller)))
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
 		return err
 	}
 
-	_, err = atc.Execute(r.algoClient, context.Background(), 4)
+	_, err = r.execute(group, "goOffline", poolAppID, "")
 	if err != nil {
 		return err
 	}
 	return nil
 }
-
-// PoolBalance just returns the currently available (minus MBR) balance for basic 'is this usable' check.
-func (r *Reti) PoolBalance(poolAppID uint64) uint64 {
-	return r.PoolAvailableRewards(poolAppID, 0)
-}
-
-func (r *Reti) PoolAvailableRewards(poolAppID uint64, totalAlgoStaked uint64) uint64 {
-	acctInfo, _ := algo.GetBareAccount(context.Background(), r.algoClient, crypto.GetApplicationAddress(poolAppID).String())
-	if acctInfo.Amount < acctInfo.MinBalance {
-		// pool isn't properly initialized yet - so don't underflow on 'reward amount'
-		return 0
-	}
-	return acctInfo.Amount - totalAlgoStaked - acctInfo.MinBalance
-}
@@ -2,19 +2,18 @@ package reti
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
 	"time"
 
-	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/v2/crypto"
 	"github.com/algorand/go-algorand-sdk/v2/transaction"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 
 	"github.com/TxnLab/reti/internal/lib/algo"
 	"github.com/TxnLab/reti/internal/lib/misc"
+	"github.com/TxnLab/reti/internal/lib/reti/contracts"
 )
 
 // ValidatorInfo is loaded at startup but also on-demand via Reti.LoadState
@@ -82,49 +81,32 @@ type ValidatorConfig struct {
 
 }
 
-func ValidatorConfigFromABIReturn(returnVal any) (*ValidatorConfig, error) {
-	if arrReturn, ok := returnVal.([]any); ok {
-		if len(arrReturn) != 18 {
-			return nil, fmt.Errorf("should be 18 elements returned in ValidatorConfig response")
-		}
-		pkAsString := func(pk []uint8) string {
-			addr, _ := types.EncodeAddress(pk)
-			return addr
-		}
-		arrValues := func(arr []any) []uint64 {
-			values := make([]uint64, len(arr))
-			for i, v := range arr {
-				values[i] = v.(uint64)
-			}
-			return values
-		}
-		config := &ValidatorConfig{}
-		config.ID = arrReturn[0].(uint64)
-		config.Owner = pkAsString(arrReturn[1].([]uint8))
-		config.Manager = pkAsString(arrReturn[2].([]uint8))
-		config.NFDForInfo = arrReturn[3].(uint64)
-		config.EntryGatingType = arrReturn[4].(uint8)
-		config.EntryGatingAddress = pkAsString(arrReturn[5].([]uint8))
-		config.EntryGatingAssets = arrValues(arrReturn[6].([]any))
-		config.GatingAssetMinBalance = arrReturn[7].(uint64)
-		config.RewardTokenId = arrReturn[8].(uint64)
-		config.RewardPerPayout = arrReturn[9].(uint64)
-		config.EpochRoundLength = int(arrReturn[10].(uint32))
-		config.PercentToValidator = int(arrReturn[11].(uint32))
-		config.ValidatorCommissionAddress = pkAsString(arrReturn[12].([]uint8))
-		config.MinEntryStake = arrReturn[13].(uint64)
-		config.MaxAlgoPerPool = arrReturn[14].(uint64)
-		config.PoolsPerNode = int(arrReturn[15].(uint8))
-		config.SunsettingOn = arrReturn[16].(uint64)
-		config.SunsettingTo = arrReturn[17].(uint64)
-		return config, nil
-	}
-	return nil, fmt.Errorf("unknown value returned from abi, type:%T", returnVal)
+func validatorConfigFromContract(config contracts.ValidatorConfig) *ValidatorConfig {
+	return &ValidatorConfig{
+		ID:                         config.ID,
+		Owner:                      config.Owner.String(),
+		Manager:                    config.Manager.String(),
+		NFDForInfo:                 config.NFDForInfo,
+		EntryGatingType:            config.EntryGatingType,
+		EntryGatingAddress:         config.EntryGatingAddress.String(),
+		EntryGatingAssets:          config.EntryGatingAssets[:],
+		GatingAssetMinBalance:      config.GatingAssetMinBalance,
+		RewardTokenId:              config.RewardTokenId,
+		RewardPerPayout:            config.RewardPerPayout,
+		EpochRoundLength:           int(config.EpochRoundLength),
+		PercentToValidator:         int(config.PercentToValidator),
+		ValidatorCommissionAddress: config.ValidatorCommissionAddress.String(),
+		MinEntryStake:              config.MinEntryStake,
+		MaxAlgoPerPool:             config.MaxAlgoPerPool,
+		PoolsPerNode:               int(config.PoolsPerNode),
+		SunsettingOn:               config.SunsettingOn,
+		SunsettingTo:               config.SunsettingTo,
+	}
 }
 
 type ProtocolConstraints struct {
-	epochPayoutRoundsMin           uint64
-	epochPayoutRoundsMax           uint64
+	EpochPayoutRoundsMin           uint64
+	EpochPayoutRoundsMax           uint64
 	MinPctToValidatorWFourDecimals uint64
 	MaxPctToValidatorWFourDecimals uint64
 	MinEntryStake                  uint64 // in microAlgo
@@ -136,28 +118,6 @@ type ProtocolConstraints struct {
 	MaxStakersPerPool              uint64
 }
 
-func ProtocolConstraintsFromABIReturn(returnVal any) (*ProtocolConstraints, error) {
-	if arrReturn, ok := returnVal.([]any); ok {
-		if len(arrReturn) != 11 {
-			return nil, fmt.Errorf("should be 10 elements returned in ProtocolConstraints response")
-		}
-		constraints := &ProtocolConstraints{}
-		constraints.epochPayoutRoundsMin = arrReturn[0].(uint64)
-		constraints.epochPayoutRoundsMax = arrReturn[1].(uint64)
-		constraints.MinPctToValidatorWFourDecimals = arrReturn[2].(uint64)
-		constraints.MaxPctToValidatorWFourDecimals = arrReturn[3].(uint64)
-		constraints.MinEntryStake = arrReturn[4].(uint64)
-		constraints.MaxAlgoPerPool = arrReturn[5].(uint64)
-		constraints.MaxAlgoPerValidator = arrReturn[6].(uint64)
-		constraints.AmtConsideredSaturated = arrReturn[7].(uint64)
-		constraints.MaxNodes = arrReturn[8].(uint64)
-		constraints.MaxPoolsPerNode = arrReturn[9].(uint64)
-		constraints.MaxStakersPerPool = arrReturn[10].(uint64)
-		return constraints, nil
-	}
-	return nil, fmt.Errorf("unknown value returned from abi, type:%T", returnVal)
-}
-
 func formattedMinutes(mins int) string {
 	// return a string expression of minutes in various forms (if applicable)
 	// minutes, hours, days
@@ -232,20 +192,13 @@ func (v *ValidatorCurState) String() string {
 	return fmt.Sprintf("numPools: %d, totalStakers: %d, totalAlgoStaked: %d", v.NumPools, v.TotalStakers, v.TotalAlgoStaked)
 }
 
-func ValidatorCurStateFromABIReturn(returnVal any) (*ValidatorCurState, error) {
-	if arrReturn, ok := returnVal.([]any); ok {
-		if len(arrReturn) != 4 {
-			return nil, fmt.Errorf("should be 4 elements returned in ValidatorCurState response")
-		}
-		state := &ValidatorCurState{}
-		state.NumPools = int(arrReturn[0].(uint16))
-		state.TotalStakers = arrReturn[1].(uint64)
-		state.TotalAlgoStaked = arrReturn[2].(uint64)
-		state.RewardTokenHeldBack = arrReturn[3].(uint64)
-
-		return state, nil
+func validatorCurStateFromContract(state contracts.ValidatorCurState) *ValidatorCurState {
+	return &ValidatorCurState{
+		NumPools:            int(state.NumPools),
+		TotalStakers:        state.TotalStakers,
+		TotalAlgoStaked:     state.TotalAlgoStaked,
+		RewardTokenHeldBack: state.RewardTokenHeldBack,
 	}
-	return nil, fmt.Errorf("unknown value returned from abi, type:%T", returnVal)
 }
 
 type ValidatorPoolKey struct {
@@ -258,66 +211,24 @@ func (v *ValidatorPoolKey) String() string {
 	return fmt.Sprintf("ValidatorPoolKey{id: %d, poolId: %d, poolAppId: %d}", v.ID, v.PoolId, v.PoolAppId)
 }
 
-func ValidatorPoolKeyFromABIReturn(returnVal any) (*ValidatorPoolKey, error) {
-	if arrReturn, ok := returnVal.([]any); ok {
-		if len(arrReturn) != 3 {
-			return nil, fmt.Errorf("should be 3 elements returned in ValidatorPoolKey response")
-		}
-		key := &ValidatorPoolKey{}
-		key.ID = arrReturn[0].(uint64)
-		key.PoolId = arrReturn[1].(uint64)
-		key.PoolAppId = arrReturn[2].(uint64)
-
-		return key, nil
-	}
-	return nil, ErrCantFetchPoolKey
-}
-
 type PoolInfo struct {
 	PoolAppId       uint64 // The App id of this staking pool contract instance
 	TotalStakers    int
 	TotalAlgoStaked uint64
 }
 
-func ValidatorPoolsFromABIReturn(returnVal any) ([]PoolInfo, error) {
-	var retPools []PoolInfo
-	if arrReturn, ok := returnVal.([]any); ok {
-		for _, poolInfoAny := range arrReturn {
-			if poolInfo, ok := poolInfoAny.([]any); ok {
-				if len(poolInfo) != 3 {
-					return nil, fmt.Errorf("should be 3 elements returned in PoolInfo response")
-				}
-				retPools = append(retPools, PoolInfo{
-					PoolAppId:       poolInfo[0].(uint64),
-					TotalStakers:    int(poolInfo[1].(uint16)),
-					TotalAlgoStaked: poolInfo[2].(uint64),
-				})
-			}
-		}
-		return retPools, nil
-	}
-	return retPools, ErrCantFetchPoolKey
-}
-
-func ValidatorPoolInfoFromABIReturn(returnVal any) (*PoolInfo, error) {
-	if arrReturn, ok := returnVal.([]any); ok {
-		if len(arrReturn) != 3 {
-			return nil, fmt.Errorf("should be 3 elements returned in PoolInfo response")
-		}
-		key := &PoolInfo{}
-		key.PoolAppId = arrReturn[0].(uint64)
-		key.TotalStakers = int(arrReturn[1].(uint16))
-		key.PoolAppId = arrReturn[2].(uint64)
-
-		return key, nil
+func poolInfoFromContract(pool contracts.PoolInfo) PoolInfo {
+	return PoolInfo{
+		PoolAppId:       pool.PoolAppId,
+		TotalStakers:    int(pool.TotalStakers),
+		TotalAlgoStaked: pool.TotalAlgoStaked,
 	}
-	return nil, ErrCantFetchPoolKey
 }
 
 func (r *Reti) AddValidator(info *ValidatorInfo, nfdName string) (uint64, error) {
 	var err error
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return 0, err
 	}
@@ -334,18 +245,8 @@ func (r *Reti) AddValidator(info *ValidatorInfo, nfdName string) (uint64, error)
 	}
 
 	// Now try to actually create the validator !!
-	atc := transaction.AtomicTransactionComposer{}
+	comp := algo.Composer{}
 
-	addValidatorMethod, err := r.validatorContract.GetMethodByName("addValidator")
-	if err != nil {
-		return 0, err
-	}
-	// We need to set all the box references ourselves still in go, so we need the id of the 'next' validator
-	// We'll do the next two just to be safe (for race condition of someone else adding validator before us)
-	curValidatorId, err := r.GetNumValidators()
-	if err != nil {
-		return 0, err
-	}
 	slog.Debug("mbrs", "validatormbr", mbrs.AddValidatorMbr)
 
 	params.FlatFee = true
@@ -353,457 +254,273 @@ func (r *Reti) AddValidator(info *ValidatorInfo, nfdName string) (uint64, error)
 
 	// Pay the mbr to add a validator then wrap for use in ATC.
 	paymentTxn, err := transaction.MakePaymentTxn(ownerAddr.String(), crypto.GetApplicationAddress(r.RetiAppId).String(), mbrs.AddValidatorMbr, nil, "", params)
+	if err != nil {
+		return 0, err
+	}
 	payTxWithSigner := transaction.TransactionWithSigner{
 		Txn:    paymentTxn,
 		Signer: algo.SignWithAccountForATC(r.signer, ownerAddr.String()),
 	}
-	params.Fee = 1000
-
-	err = atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:  r.RetiAppId,
-		Method: addValidatorMethod,
-		MethodArgs: []any{
-			// MBR payment
-			payTxWithSigner,
-			// --
-			nfdName,
-			[]any{
-				0, // id is ignored and assigned by contract
-				ownerAddr,
-				managerAddr,
-				info.Config.NFDForInfo,
-				0, // gating type none
-				types.ZeroAddress,
-				[]uint64{0, 0, 0, 0},
-				info.Config.GatingAssetMinBalance,
-				info.Config.RewardTokenId,
-				info.Config.RewardPerPayout,
-				uint32(info.Config.EpochRoundLength),
-				uint16(info.Config.PercentToValidator),
-				commissionAddr,
-				info.Config.MinEntryStake,
-				info.Config.MaxAlgoPerPool,
-				uint8(info.Config.PoolsPerNode),
-				info.Config.SunsettingOn,
-				info.Config.SunsettingTo,
-			},
-		},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(curValidatorId + 1)},
-			{AppID: 0, Name: GetValidatorListBoxName(curValidatorId + 2)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          ownerAddr,
-		Signer:          algo.SignWithAccountForATC(r.signer, ownerAddr.String()),
-	})
+
+	// id is ignored and assigned by contract, and no entry gating is set
+	comp.AddMethodCall(r.registry().AddValidator(r.callOpts(params, ownerAddr), payTxWithSigner, nfdName, contracts.ValidatorConfig{
+		Owner:                      ownerAddr,
+		Manager:                    managerAddr,
+		NFDForInfo:                 info.Config.NFDForInfo,
+		GatingAssetMinBalance:      info.Config.GatingAssetMinBalance,
+		RewardTokenId:              info.Config.RewardTokenId,
+		RewardPerPayout:            info.Config.RewardPerPayout,
+		EpochRoundLength:           uint32(info.Config.EpochRoundLength),
+		PercentToValidator:         uint32(info.Config.PercentToValidator),
+		ValidatorCommissionAddress: commissionAddr,
+		MinEntryStake:              info.Config.MinEntryStake,
+		MaxAlgoPerPool:             info.Config.MaxAlgoPerPool,
+		PoolsPerNode:               uint8(info.Config.PoolsPerNode),
+		SunsettingOn:               info.Config.SunsettingOn,
+		SunsettingTo:               info.Config.SunsettingTo,
+	}))
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
 		return 0, fmt.Errorf("error in atc compose: %w", err)
 	}
 
-	result, err := atc.Execute(r.algoClient, context.Background(), 4)
+	result, err := r.execute(group, "addValidator", r.RetiAppId, fmt.Sprintf("owner:%s, manager:%s", info.Config.Owner, info.Config.Manager))
 	if err != nil {
 		return 0, err
 	}
-	if validatorId, ok := result.MethodResults[0].ReturnValue.(uint64); ok {
-		return validatorId, nil
-	}
-	return 0, nil
+	return r.registry().AddValidatorResult(result.MethodResults[0])
 }
 
 func (r *Reti) GetProtocolConstraints() (*ProtocolConstraints, error) {
-	var err error
-
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
 	if err != nil {
 		return nil, err
 	}
-
-	dummyAddr, err := r.getLocalSignerForSimulateCalls()
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetProtocolConstraints(opts))
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error retrieving protocol constraints: %w", err)
 	}
-	// Now try to actually create the validator !!
-	atc := transaction.AtomicTransactionComposer{}
-
-	method, _ := r.validatorContract.GetMethodByName("getProtocolConstraints")
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:           r.RetiAppId,
-		Method:          method,
-		MethodArgs:      []any{},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          dummyAddr,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
+	constraints, err := r.registry().GetProtocolConstraintsResult(result)
 	if err != nil {
 		return nil, err
 	}
-	if result.SimulateResponse.TxnGroups[0].FailureMessage != "" {
-		return nil, fmt.Errorf("error retrieving protocol constraints: %s", result.SimulateResponse.TxnGroups[0].FailureMessage)
-	}
-	return ProtocolConstraintsFromABIReturn(result.MethodResults[0].ReturnValue)
+	return (*ProtocolConstraints)(&constraints), nil
 }
 
 func (r *Reti) GetValidatorConfig(id uint64) (*ValidatorConfig, error) {
-	var err error
-
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
 	if err != nil {
 		return nil, err
 	}
-
-	dummyAddr, err := r.getLocalSignerForSimulateCalls()
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetValidatorConfig(opts, id))
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving validator config: %w", err)
+	}
+	config, err := r.registry().GetValidatorConfigResult(result)
 	if err != nil {
 		return nil, err
 	}
-	// Now try to actually create the validator !!
-	atc := transaction.AtomicTransactionComposer{}
+	return validatorConfigFromContract(config), nil
+}
 
-	method, err := r.validatorContract.GetMethodByName("getValidatorConfig")
+func (r *Reti) GetValidatorState(id uint64) (*ValidatorCurState, error) {
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
 	if err != nil {
 		return nil, err
 	}
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:      r.RetiAppId,
-		Method:     method,
-		MethodArgs: []any{id},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(id)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          dummyAddr,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetValidatorState(opts, id))
 	if err != nil {
 		return nil, err
 	}
-	if result.SimulateResponse.TxnGroups[0].FailureMessage != "" {
-		return nil, fmt.Errorf("error retrieving validator config: %s", result.SimulateResponse.TxnGroups[0].FailureMessage)
-	}
-	return ValidatorConfigFromABIReturn(result.MethodResults[0].ReturnValue)
-}
-
-func (r *Reti) GetValidatorState(id uint64) (*ValidatorCurState, error) {
-	var err error
-
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	state, err := r.registry().GetValidatorStateResult(result)
 	if err != nil {
 		return nil, err
 	}
+	return validatorCurStateFromContract(state), nil
+}
 
-	dummyAddr, err := r.getLocalSignerForSimulateCalls()
+func (r *Reti) GetValidatorPools(id uint64) ([]PoolInfo, error) {
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
 	if err != nil {
 		return nil, err
 	}
-
-	// Now try to actually create the validator !!
-	atc := transaction.AtomicTransactionComposer{}
-
-	method, err := r.validatorContract.GetMethodByName("getValidatorState")
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetPools(opts, id))
 	if err != nil {
 		return nil, err
 	}
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:      r.RetiAppId,
-		Method:     method,
-		MethodArgs: []any{id},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(id)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          dummyAddr,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
+	pools, err := r.registry().GetPoolsResult(result)
 	if err != nil {
 		return nil, err
 	}
-	return ValidatorCurStateFromABIReturn(result.MethodResults[0].ReturnValue)
+	var retPools []PoolInfo
+	for _, pool := range pools {
+		retPools = append(retPools, poolInfoFromContract(pool))
+	}
+	return retPools, nil
 }
 
-func (r *Reti) GetValidatorPools(id uint64) ([]PoolInfo, error) {
-	var err error
-
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+func (r *Reti) GetValidatorPoolInfo(poolKey ValidatorPoolKey) (*PoolInfo, error) {
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
 	if err != nil {
 		return nil, err
 	}
-
-	dummyAddr, err := r.getLocalSignerForSimulateCalls()
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetPoolInfo(opts, contracts.ValidatorPoolKey(poolKey)))
 	if err != nil {
 		return nil, err
 	}
+	pool, err := r.registry().GetPoolInfoResult(result)
+	if err != nil {
+		return nil, err
+	}
+	poolInfo := poolInfoFromContract(pool)
+	return &poolInfo, nil
+}
 
-	// Now try to actually create the validator !!
-	atc := transaction.AtomicTransactionComposer{}
+// GetPoolAppId returns the app id of a validator's pool (by its 1-based pool id)
+func (r *Reti) GetPoolAppId(id uint64, poolId uint64) (uint64, error) {
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
+	if err != nil {
+		return 0, err
+	}
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetPoolAppId(opts, id, poolId))
+	if err != nil {
+		return 0, err
+	}
+	return r.registry().GetPoolAppIdResult(result)
+}
 
-	getPoolInfoMethod, err := r.validatorContract.GetMethodByName("getPools")
+// GetCurMaxStakePerPool returns the maximum stake currently allowed in each of a validator's pools - which depends
+// on the number of pools the validator has, as well as the protocol limits.
+func (r *Reti) GetCurMaxStakePerPool(id uint64) (uint64, error) {
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:      r.RetiAppId,
-		Method:     getPoolInfoMethod,
-		MethodArgs: []any{id},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(id)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          dummyAddr,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowMoreLogging:      true,
-		AllowUnnamedResources: true,
-	})
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetCurMaxStakePerPool(opts, id))
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
-	return ValidatorPoolsFromABIReturn(result.MethodResults[0].ReturnValue)
+	return r.registry().GetCurMaxStakePerPoolResult(result)
 }
 
-func (r *Reti) GetValidatorPoolInfo(poolKey ValidatorPoolKey) (*PoolInfo, error) {
-	var err error
+// PoolTokenPayoutRatio is the percentage (of the whole, w/ 6 decimals) of the validator's total stake each pool had
+// at the time of the last payout - used for dividing reward tokens between pools.
+type PoolTokenPayoutRatio struct {
+	PoolPctOfWhole   []uint64
+	UpdatedForPayout uint64
+}
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+func (r *Reti) GetTokenPayoutRatio(id uint64) (*PoolTokenPayoutRatio, error) {
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
 	if err != nil {
 		return nil, err
 	}
-
-	dummyAddr, err := r.getLocalSignerForSimulateCalls()
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetTokenPayoutRatio(opts, id))
 	if err != nil {
 		return nil, err
 	}
-
-	// Now try to actually create the validator !!
-	atc := transaction.AtomicTransactionComposer{}
-
-	getPoolInfoMethod, _ := r.validatorContract.GetMethodByName("getPoolInfo")
-	_ = atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:       r.RetiAppId,
-		Method:      getPoolInfoMethod,
-		MethodArgs:  []any{poolKey.ID, poolKey.PoolId, poolKey.PoolAppId},
-		ForeignApps: []uint64{poolKey.PoolAppId},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(poolKey.PoolAppId)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          dummyAddr,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
+	ratio, err := r.registry().GetTokenPayoutRatioResult(result)
 	if err != nil {
 		return nil, err
 	}
-	return ValidatorPoolInfoFromABIReturn(result.MethodResults[0].ReturnValue)
+	return &PoolTokenPayoutRatio{
+		PoolPctOfWhole:   ratio.PoolPctOfWhole[:],
+		UpdatedForPayout: ratio.UpdatedForPayout,
+	}, nil
 }
 
-func (r *Reti) GetStakedPoolsForAccount(staker types.Address) ([]*ValidatorPoolKey, error) {
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+// GetNFDRegistryID returns the app id of the NFD registry the protocol verifies NFDs against
+func (r *Reti) GetNFDRegistryID() (uint64, error) {
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
 	if err != nil {
-		return nil, err
+		return 0, err
+	}
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetNFDRegistryID(opts))
+	if err != nil {
+		return 0, err
 	}
+	return r.registry().GetNFDRegistryIDResult(result)
+}
 
-	method, err := r.validatorContract.GetMethodByName("getStakedPoolsForAccount")
+func (r *Reti) GetStakedPoolsForAccount(staker types.Address) ([]*ValidatorPoolKey, error) {
+	opts, err := r.readOnlyOpts(staker)
 	if err != nil {
 		return nil, err
 	}
-	atc := transaction.AtomicTransactionComposer{}
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:           r.RetiAppId,
-		Method:          method,
-		MethodArgs:      []any{staker},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          staker,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetStakedPoolsForAccount(opts, staker))
 	if err != nil {
 		return nil, err
 	}
-	if result.SimulateResponse.TxnGroups[0].FailureMessage != "" {
-		return nil, errors.New(result.SimulateResponse.TxnGroups[0].FailureMessage)
+	poolKeys, err := r.registry().GetStakedPoolsForAccountResult(result)
+	if err != nil {
+		return nil, err
 	}
 	var retPools []*ValidatorPoolKey
-	if arrReturn, ok := result.MethodResults[0].ReturnValue.([]any); ok {
-		for _, poolInfoAny := range arrReturn {
-			poolKey, err := ValidatorPoolKeyFromABIReturn(poolInfoAny)
-			if err != nil {
-				return nil, err
-			}
-			retPools = append(retPools, poolKey)
-		}
-		return retPools, nil
+	for _, poolKey := range poolKeys {
+		retPools = append(retPools, (*ValidatorPoolKey)(&poolKey))
 	}
-
-	return nil, fmt.Errorf("unknown result type:%#v", result.MethodResults)
+	return retPools, nil
 }
 
 func (r *Reti) GetValidatorNodePoolAssignments(id uint64) (*NodePoolAssignmentConfig, error) {
-	var err error
-
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
-	if err != nil {
-		return nil, err
-	}
-
-	dummyAddr, err := r.getLocalSignerForSimulateCalls()
+	opts, err := r.readOnlyOpts(DummyAlgoSender)
 	if err != nil {
 		return nil, err
 	}
-
-	// Now try to actually create the validator !!
-	atc := transaction.AtomicTransactionComposer{}
-
-	getNodePoolAssignmentsMethod, err := r.validatorContract.GetMethodByName("getNodePoolAssignments")
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetNodePoolAssignments(opts, id))
 	if err != nil {
 		return nil, err
 	}
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:      r.RetiAppId,
-		Method:     getNodePoolAssignmentsMethod,
-		MethodArgs: []any{id},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(id)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          dummyAddr,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowMoreLogging:      true,
-		AllowUnnamedResources: true,
-	})
+	assignments, err := r.registry().GetNodePoolAssignmentsResult(result)
 	if err != nil {
 		return nil, err
 	}
-	return NodePoolAssignmentFromABIReturn(result.MethodResults[0].ReturnValue)
-}
-
-func NodePoolAssignmentFromABIReturn(returnVal any) (*NodePoolAssignmentConfig, error) {
+	// the contract has fixed size arrays of nodes and pool slots - only return the assigned pools
 	var retPAC = &NodePoolAssignmentConfig{}
-	if arrReturn, ok := returnVal.([]any); ok {
-		for _, nodeConfigAny := range arrReturn {
-			if nodes, ok := nodeConfigAny.([]any); ok {
-				for _, pools := range nodes {
-					if poolIDs, ok := pools.([]any); ok {
-						var ids []uint64
-						for _, id := range poolIDs[0].([]any) {
-							convertedID := id.(uint64)
-							if convertedID == 0 {
-								continue
-							}
-							ids = append(ids, convertedID)
-						}
-						retPAC.Nodes = append(retPAC.Nodes, NodeConfig{PoolAppIds: ids})
-					}
-				}
+	for _, node := range assignments.Nodes {
+		var ids []uint64
+		for _, id := range node.PoolAppIds {
+			if id == 0 {
+				continue
 			}
+			ids = append(ids, id)
 		}
-		return retPAC, nil
+		retPAC.Nodes = append(retPAC.Nodes, NodeConfig{PoolAppIds: ids})
 	}
-	return nil, ErrCantFetchPoolKey
+	return retPAC, nil
 }
 
 func (r *Reti) FindPoolForStaker(id uint64, staker types.Address, amount uint64) (*ValidatorPoolKey, error) {
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	opts, err := r.readOnlyOpts(staker)
 	if err != nil {
 		return nil, err
 	}
-
-	findPoolMethod, _ := r.validatorContract.GetMethodByName("findPoolForStaker")
-	atc := transaction.AtomicTransactionComposer{}
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:           r.RetiAppId,
-		Method:          findPoolMethod,
-		MethodArgs:      []any{id, staker, amount},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          staker,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().FindPoolForStaker(opts, id, staker, amount))
 	if err != nil {
 		return nil, err
 	}
-	if result.SimulateResponse.TxnGroups[0].FailureMessage != "" {
-		return nil, errors.New(result.SimulateResponse.TxnGroups[0].FailureMessage)
+	pool, err := r.registry().FindPoolForStakerResult(result)
+	if err != nil {
+		return nil, err
 	}
-	// findPoolForStaker returns [ValidatorPoolKey, boolean]
-	return ValidatorPoolKeyFromABIReturn(result.MethodResults[0].ReturnValue.([]any)[0])
+	return (*ValidatorPoolKey)(&pool.PoolKey), nil
 }
 
 func (r *Reti) ChangeValidatorManagerAddress(id uint64, sender types.Address, managerAddress types.Address) error {
 	var err error
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
 
-	atc := transaction.AtomicTransactionComposer{}
-
-	changeAddressMethod, _ := r.validatorContract.GetMethodByName("changeValidatorManager")
-	// We have to pay MBR into the Validator contract itself for adding a pool
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:  r.RetiAppId,
-		Method: changeAddressMethod,
-		MethodArgs: []any{
-			id,
-			managerAddress,
-		},
-		ForeignApps: []uint64{r.poolTemplateAppId()},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(id)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          sender,
-		Signer:          algo.SignWithAccountForATC(r.signer, sender.String()),
-	})
-	_, err = atc.Execute(r.algoClient, context.Background(), 4)
+	comp := algo.Composer{}
+	comp.AddMethodCall(r.registry().ChangeValidatorManager(r.callOpts(params, sender), id, managerAddress))
+	group, err := comp.Prepare(context.Background(), r.chain)
+	if err != nil {
+		return err
+	}
+	_, err = r.execute(group, "changeValidatorManager", r.RetiAppId, fmt.Sprintf("validator:%d, manager:%s", id, managerAddress))
 	if err != nil {
 		return err
 	}
@@ -815,33 +532,18 @@ func (r *Reti) ChangeValidatorManagerAddress(id uint64, sender types.Address, ma
 func (r *Reti) ChangeValidatorCommissionAddress(id uint64, sender types.Address, commissionAddress types.Address) error {
 	var err error
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
 
-	atc := transaction.AtomicTransactionComposer{}
-
-	changeAddressMethod, _ := r.validatorContract.GetMethodByName("changeValidatorCommissionAddress")
-	// We have to pay MBR into the Validator contract itself for adding a pool
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:  r.RetiAppId,
-		Method: changeAddressMethod,
-		MethodArgs: []any{
-			id,
-			commissionAddress,
-		},
-		ForeignApps: []uint64{r.poolTemplateAppId()},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(id)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          sender,
-		Signer:          algo.SignWithAccountForATC(r.signer, sender.String()),
-	})
-	_, err = atc.Execute(r.algoClient, context.Background(), 4)
+	comp := algo.Composer{}
+	comp.AddMethodCall(r.registry().ChangeValidatorCommissionAddress(r.callOpts(params, sender), id, commissionAddress))
+	group, err := comp.Prepare(context.Background(), r.chain)
+	if err != nil {
+		return err
+	}
+	_, err = r.execute(group, "changeValidatorCommissionAddress", r.RetiAppId, fmt.Sprintf("validator:%d, commission address:%s", id, commissionAddress))
 	if err != nil {
 		return err
 	}
@@ -850,13 +552,77 @@ func (r *Reti) ChangeValidatorCommissionAddress(id uint64, sender types.Address,
 
 }
 
+func (r *Reti) ChangeValidatorSunsetInfo(id uint64, sender types.Address, sunsettingOn uint64, sunsettingTo uint64) error {
+	params, err := r.chain.SuggestedParams(context.Background())
+	if err != nil {
+		return err
+	}
+
+	comp := algo.Composer{}
+	comp.AddMethodCall(r.registry().ChangeValidatorSunsetInfo(r.callOpts(params, sender), id, sunsettingOn, sunsettingTo))
+	group, err := comp.Prepare(context.Background(), r.chain)
+	if err != nil {
+		return err
+	}
+	_, err = r.execute(group, "changeValidatorSunsetInfo", r.RetiAppId, fmt.Sprintf("validator:%d, sunsetting on:%d, to:%d", id, sunsettingOn, sunsettingTo))
+	return err
+}
+
+func (r *Reti) ChangeValidatorNFD(id uint64, sender types.Address, nfdAppID uint64, nfdName string) error {
+	params, err := r.chain.SuggestedParams(context.Background())
+	if err != nil {
+		return err
+	}
+
+	comp := algo.Composer{}
+	comp.AddMethodCall(r.registry().ChangeValidatorNFD(r.callOpts(params, sender), id, nfdAppID, nfdName))
+	group, err := comp.Prepare(context.Background(), r.chain)
+	if err != nil {
+		return err
+	}
+	_, err = r.execute(group, "changeValidatorNFD", r.RetiAppId, fmt.Sprintf("validator:%d, nfd:%s, nfd app id:%d", id, nfdName, nfdAppID))
+	return err
+}
+
+// ChangeValidatorRewardInfo changes the entry gating and the reward tokens paid per epoch.  Up to 4 gating assets can
+// be specified.
+func (r *Reti) ChangeValidatorRewardInfo(
+	id uint64,
+	sender types.Address,
+	entryGatingType uint8,
+	entryGatingAddress types.Address,
+	entryGatingAssets []uint64,
+	gatingAssetMinBalance uint64,
+	rewardPerPayout uint64,
+) error {
+	var gatingAssets [4]uint64
+	if len(entryGatingAssets) > len(gatingAssets) {
+		return fmt.Errorf("at most %d gating assets can be specified", len(gatingAssets))
+	}
+	copy(gatingAssets[:], entryGatingAssets)
+
+	params, err := r.chain.SuggestedParams(context.Background())
+	if err != nil {
+		return err
+	}
+
+	comp := algo.Composer{}
+	comp.AddMethodCall(r.registry().ChangeValidatorRewardInfo(r.callOpts(params, sender), id, entryGatingType, entryGatingAddress, gatingAssets, gatingAssetMinBalance, rewardPerPayout))
+	group, err := comp.Prepare(context.Background(), r.chain)
+	if err != nil {
+		return err
+	}
+	_, err = r.execute(group, "changeValidatorRewardInfo", r.RetiAppId, fmt.Sprintf("validator:%d, gating type:%d, reward per payout:%d", id, entryGatingType, rewardPerPayout))
+	return err
+}
+
 func (r *Reti) AddStakingPool(nodeNum uint64) (*ValidatorPoolKey, error) {
 	var (
 		info = r.Info()
 		err  error
 	)
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return nil, err
 	}
@@ -871,61 +637,41 @@ func (r *Reti) AddStakingPool(nodeNum uint64) (*ValidatorPoolKey, error) {
 	}
 
 	// Now try to actually create the pool !!
-	atc := transaction.AtomicTransactionComposer{}
+	comp := algo.Composer{}
 
 	misc.Infof(r.Logger, "adding staking pool to node:%d", nodeNum)
-	addPoolMethod, _ := r.validatorContract.GetMethodByName("addPool")
 	// We have to pay MBR into the Validator contract itself for adding a pool
 	paymentTxn, err := transaction.MakePaymentTxn(managerAddr.String(), crypto.GetApplicationAddress(r.RetiAppId).String(), mbrs.AddPoolMbr, nil, "", params)
+	if err != nil {
+		return nil, err
+	}
 	payTxWithSigner := transaction.TransactionWithSigner{
 		Txn:    paymentTxn,
 		Signer: algo.SignWithAccountForATC(r.signer, managerAddr.String()),
 	}
 
-	params.FlatFee = true
-	params.Fee = types.MicroAlgos(max(uint64(params.MinFee), 1000) + params.MinFee)
-
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:  r.RetiAppId,
-		Method: addPoolMethod,
-		MethodArgs: []any{
-			// MBR payment
-			payTxWithSigner,
-			// --
-			info.Config.ID,
-			nodeNum,
-		},
-		ForeignApps: []uint64{r.poolTemplateAppId()},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(info.Config.ID)},
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: []byte("poolTemplateApprovalBytes")},
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          managerAddr,
-		Signer:          algo.SignWithAccountForATC(r.signer, managerAddr.String()),
-	})
-	result, err := atc.Execute(r.algoClient, context.Background(), 4)
+	comp.AddMethodCall(r.registry().AddPool(r.callOpts(params, managerAddr), payTxWithSigner, info.Config.ID, nodeNum))
+	group, err := comp.Prepare(context.Background(), r.chain)
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.execute(group, "addPool", r.RetiAppId, fmt.Sprintf("node:%d", nodeNum))
 	if err != nil {
 		return nil, err
 	}
 
-	poolKey, err := ValidatorPoolKeyFromABIReturn(result.MethodResults[0].ReturnValue)
+	addedKey, err := r.registry().AddPoolResult(result.MethodResults[0])
 	if err != nil {
 		return nil, err
 	}
+	poolKey := ValidatorPoolKey(addedKey)
 
-	err = r.CheckAndInitStakingPoolStorage(poolKey)
+	err = r.CheckAndInitStakingPoolStorage(&poolKey)
 	if err != nil {
 		return nil, err
 	}
 
-	return poolKey, err
+	return &poolKey, err
 }
 
 func (r *Reti) MovePoolToNode(poolAppId uint64, nodeNum uint64) error {
@@ -934,52 +680,23 @@ func (r *Reti) MovePoolToNode(poolAppId uint64, nodeNum uint64) error {
 		err  error
 	)
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
 
 	managerAddr, _ := types.DecodeAddress(info.Config.Manager)
 
-	atc := transaction.AtomicTransactionComposer{}
+	comp := algo.Composer{}
 	misc.Infof(r.Logger, "trying to move pool app id:%d to node number:%d", poolAppId, nodeNum)
-	gasMethod, _ := r.validatorContract.GetMethodByName("gas")
-	movePoolMethod, _ := r.validatorContract.GetMethodByName("movePoolToNode")
-
-	// pay for go offline call as well
-	params.FlatFee = true
-	params.Fee = types.MicroAlgos(max(uint64(params.MinFee), 1000) + (2 * params.MinFee))
-
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:           r.RetiAppId,
-		Method:          gasMethod,
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          managerAddr,
-		Signer:          algo.SignWithAccountForATC(r.signer, managerAddr.String()),
-	})
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:  r.RetiAppId,
-		Method: movePoolMethod,
-		MethodArgs: []any{
-			info.Config.ID,
-			poolAppId,
-			nodeNum,
-		},
-		ForeignApps: []uint64{
-			r.poolTemplateAppId(),
-			poolAppId,
-		},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: GetValidatorListBoxName(info.Config.ID)},
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          managerAddr,
-		Signer:          algo.SignWithAccountForATC(r.signer, managerAddr.String()),
-	})
-	_, err = atc.Execute(r.algoClient, context.Background(), 4)
+	opts := r.callOpts(params, managerAddr)
+	comp.AddMethodCall(r.registry().Gas(opts))
+	comp.AddMethodCall(r.registry().MovePoolToNode(opts, info.Config.ID, poolAppId, nodeNum))
+	group, err := comp.Prepare(context.Background(), r.chain)
+	if err != nil {
+		return err
+	}
+	_, err = r.execute(group, "movePoolToNode", r.RetiAppId, fmt.Sprintf("pool app id:%d, node:%d", poolAppId, nodeNum))
 	if err != nil {
 		return err
 	}
@@ -988,22 +705,19 @@ func (r *Reti) MovePoolToNode(poolAppId uint64, nodeNum uint64) error {
 
 func (r *Reti) CheckAndInitStakingPoolStorage(poolKey *ValidatorPoolKey) error {
 	// First determine if we NEED to initialize this pool !
-	if val, err := r.algoClient.GetApplicationBoxByName(poolKey.PoolAppId, GetStakerLedgerBoxName()).Do(context.Background()); err == nil {
+	if val, err := r.chain.ApplicationBox(context.Background(), poolKey.PoolAppId, GetStakerLedgerBoxName()); err == nil {
 		if len(val.Value) > 0 {
 			// we have value already - we're already initialized.
 			return nil
 		}
 	}
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
 
-	var (
-		foreignAssets  []uint64
-		managerAddr, _ = types.DecodeAddress(r.Info().Config.Manager)
-	)
+	managerAddr, _ := types.DecodeAddress(r.Info().Config.Manager)
 
 	mbrs, err := r.getMbrAmounts(managerAddr)
 	if err != nil {
@@ -1012,64 +726,29 @@ func (r *Reti) CheckAndInitStakingPoolStorage(poolKey *ValidatorPoolKey) error {
 	poolInitMbr := mbrs.PoolInitMbr
 	if r.Info().Config.RewardTokenId != 0 && poolKey.PoolId == 1 {
 		poolInitMbr += 100_000 // cover MBR of reward token asset
-		foreignAssets = append(foreignAssets, r.Info().Config.RewardTokenId)
 	}
 
 	// Now we have to pay MBR into the staking pool itself (!) and tell it to initialize itself
-	gasMethod, _ := r.poolContract.GetMethodByName("gas")
-	initStorageMethod, _ := r.poolContract.GetMethodByName("initStorage")
-
 	misc.Infof(r.Logger, "initializing staking pool storage, mbr payment to pool:%s", algo.FormattedAlgoAmount(poolInitMbr))
-	atc := transaction.AtomicTransactionComposer{}
+	comp := algo.Composer{}
 	paymentTxn, err := transaction.MakePaymentTxn(managerAddr.String(), crypto.GetApplicationAddress(poolKey.PoolAppId).String(), poolInitMbr, nil, "", params)
+	if err != nil {
+		return err
+	}
 	payTxWithSigner := transaction.TransactionWithSigner{
 		Txn:    paymentTxn,
 		Signer: algo.SignWithAccountForATC(r.signer, managerAddr.String()),
 	}
-	// we need to stack up references in this gas method for resource pooling
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:  poolKey.PoolAppId,
-		Method: gasMethod,
-		BoxReferences: []types.AppBoxReference{
-			{AppID: r.RetiAppId, Name: GetValidatorListBoxName(poolKey.ID)},
-			{AppID: 0, Name: GetStakerLedgerBoxName()},
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		ForeignApps:     []uint64{r.RetiAppId},
-		ForeignAssets:   foreignAssets,
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          managerAddr,
-		Signer:          algo.SignWithAccountForATC(r.signer, managerAddr.String()),
-	})
-	params.FlatFee = true
-	params.Fee = 3 * transaction.MinTxnFee
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:  poolKey.PoolAppId,
-		Method: initStorageMethod,
-		MethodArgs: []any{
-			// MBR payment
-			payTxWithSigner,
-		},
-		BoxReferences: []types.AppBoxReference{
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil}, // extra i/o
-		},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          managerAddr,
-		Signer:          algo.SignWithAccountForATC(r.signer, managerAddr.String()),
-	})
-	_, err = atc.Execute(r.algoClient, context.Background(), 4)
+	pool := contracts.StakingPool{AppID: poolKey.PoolAppId}
+	opts := r.callOpts(params, managerAddr)
+	// the gas call gives the group room for all the references initStorage needs (pooled across the group)
+	comp.AddMethodCall(pool.Gas(opts))
+	comp.AddMethodCall(pool.InitStorage(opts, payTxWithSigner))
+	group, err := comp.Prepare(context.Background(), r.chain)
+	if err != nil {
+		return err
+	}
+	_, err = r.execute(group, "initStorage", poolKey.PoolAppId, "")
 	if err != nil {
 		return err
 	}
@@ -1082,7 +761,7 @@ func (r *Reti) AddStake(validatorId uint64, staker types.Address, amount uint64,
 		amountToStake = uint64(amount)
 	)
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return nil, err
 	}
@@ -1103,240 +782,69 @@ func (r *Reti) AddStake(validatorId uint64, staker types.Address, amount uint64,
 		amountToStake += mbrs.AddStakerMbr
 	}
 
-	// Because we can't do easy simulate->execute in Go we have to figure out the references ourselves which means we need to know in advance
-	// what staking pool we'll go to.  So we can just ask validator to find the pool for us and then use that (some small race conditions obviously)
-	futurePoolKey, err := r.FindPoolForStaker(validatorId, staker, amount)
+	comp := algo.Composer{}
+	paymentTxn, err := transaction.MakePaymentTxn(staker.String(), crypto.GetApplicationAddress(r.RetiAppId).String(), amountToStake, nil, "", params)
 	if err != nil {
 		return nil, err
 	}
-
-	getAtc := func(feesToUse uint64) (transaction.AtomicTransactionComposer, error) {
-		atc := transaction.AtomicTransactionComposer{}
-		gasMethod, _ := r.validatorContract.GetMethodByName("gas")
-		stakeMethod, _ := r.validatorContract.GetMethodByName("addStake")
-
-		params.FlatFee = true
-		params.Fee = transaction.MinTxnFee
-
-		paymentTxn, err := transaction.MakePaymentTxn(staker.String(), crypto.GetApplicationAddress(r.RetiAppId).String(), amountToStake, nil, "", params)
-		payTxWithSigner := transaction.TransactionWithSigner{
-			Txn:    paymentTxn,
-			Signer: algo.SignWithAccountForATC(r.signer, staker.String()),
-		}
-
-		// we need to stack up references in this gas method for resource pooling
-		err = atc.AddMethodCall(transaction.AddMethodCallParams{
-			AppID:  r.RetiAppId,
-			Method: gasMethod,
-			BoxReferences: []types.AppBoxReference{
-				{AppID: 0, Name: GetValidatorListBoxName(validatorId)},
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: GetStakerPoolSetBoxName(staker)},
-			},
-			SuggestedParams: params,
-			OnComplete:      types.NoOpOC,
-			Sender:          staker,
-			Signer:          algo.SignWithAccountForATC(r.signer, staker.String()),
-		})
-		if err != nil {
-			return atc, err
-		}
-		if feesToUse == 0 {
-			// we're simulating so go with super high budget
-			feesToUse = 240 * transaction.MinTxnFee
-		}
-		params.FlatFee = true
-		params.Fee = types.MicroAlgos(feesToUse)
-		err = atc.AddMethodCall(transaction.AddMethodCallParams{
-			AppID:  r.RetiAppId,
-			Method: stakeMethod,
-			MethodArgs: []any{
-				// MBR payment
-				payTxWithSigner,
-				// --
-				validatorId,
-				assetIDToCheck,
-			},
-			ForeignApps: []uint64{futurePoolKey.PoolAppId},
-			BoxReferences: []types.AppBoxReference{
-				{AppID: futurePoolKey.PoolAppId, Name: GetStakerLedgerBoxName()},
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-			},
-			SuggestedParams: params,
-			OnComplete:      types.NoOpOC,
-			Sender:          staker,
-			Signer:          algo.SignWithAccountForATC(r.signer, staker.String()),
-		})
-		if err != nil {
-			return atc, err
-		}
-		return atc, err
+	payTxWithSigner := transaction.TransactionWithSigner{
+		Txn:    paymentTxn,
+		Signer: algo.SignWithAccountForATC(r.signer, staker.String()),
 	}
 
-	// simulate first
-	atc, err := getAtc(0)
+	opts := r.callOpts(params, staker)
+	// the gas call gives the group room for all the references addStake needs (pooled across the group)
+	comp.AddMethodCall(r.registry().Gas(opts))
+	comp.AddMethodCall(r.registry().AddStake(opts, payTxWithSigner, validatorId, assetIDToCheck))
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
 		return nil, err
 	}
-	simResult, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
-	if err != nil {
-		return nil, err
-	}
-	if simResult.SimulateResponse.TxnGroups[0].FailureMessage != "" {
-		return nil, errors.New(simResult.SimulateResponse.TxnGroups[0].FailureMessage)
-	}
-	// Figure out how much app budget was added so we can know the real fees to use when we execute
-	atc, err = getAtc(2*transaction.MinTxnFee + transaction.MinTxnFee*(simResult.SimulateResponse.TxnGroups[0].AppBudgetAdded/700))
+
+	result, err := r.execute(group, "addStake", r.RetiAppId, fmt.Sprintf("validator:%d, staker:%s, amount:%d", validatorId, staker, amount))
 	if err != nil {
 		return nil, err
 	}
-
-	result, err := atc.Execute(r.algoClient, context.Background(), 4)
+	poolKey, err := r.registry().AddStakeResult(result.MethodResults[1])
 	if err != nil {
 		return nil, err
 	}
-	return ValidatorPoolKeyFromABIReturn(result.MethodResults[1].ReturnValue)
+	return (*ValidatorPoolKey)(&poolKey), nil
 }
 
 func (r *Reti) RemoveStake(poolKey ValidatorPoolKey, signer types.Address, staker types.Address, amount uint64) error {
 	var err error
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
 	params.LastRoundValid = params.FirstRoundValid + 100
 
-	extraApps := []uint64{}
-	extraAssets := []uint64{}
-
 	config, err := r.GetValidatorConfig(poolKey.ID)
 	if err != nil {
 		return fmt.Errorf("get validator config err:%w", err)
 	}
-	pools, err := r.GetValidatorPools(poolKey.ID)
-	if err != nil {
-		return fmt.Errorf("unable to GetValidatorPools: %w", err)
-	}
 
-	if config.RewardTokenId != 0 {
-		extraAssets = append(extraAssets, config.RewardTokenId)
-		if poolKey.PoolId != 1 {
-			// If not pool 1 then we need to add reference for pool 1, so it can be called to update the pool token payout ratio
-			extraApps = append(extraApps, pools[0].PoolAppId)
-		}
-	}
-	getAtc := func(feesToUse uint64) (transaction.AtomicTransactionComposer, error) {
-		atc := transaction.AtomicTransactionComposer{}
-		gasMethod, _ := r.validatorContract.GetMethodByName("gas")
-		unstakeMethod, _ := r.poolContract.GetMethodByName("removeStake")
-
-		params.FlatFee = true
-		params.Fee = transaction.MinTxnFee
-
-		// we need to stack up references in this gas method for resource pooling
-		err = atc.AddMethodCall(transaction.AddMethodCallParams{
-			AppID:           r.RetiAppId,
-			Method:          gasMethod,
-			ForeignAccounts: []string{staker.String()},
-			BoxReferences: []types.AppBoxReference{
-				{AppID: r.RetiAppId, Name: GetValidatorListBoxName(poolKey.ID)},
-				{AppID: r.RetiAppId, Name: nil}, // extra i/o
-				{AppID: r.RetiAppId, Name: GetStakerPoolSetBoxName(staker)},
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-			},
-			SuggestedParams: params,
-			OnComplete:      types.NoOpOC,
-			Sender:          signer,
-			Signer:          algo.SignWithAccountForATC(r.signer, signer.String()),
-		})
-		if err != nil {
-			return atc, err
-		}
-		if len(extraAssets) > 0 || len(extraApps) > 0 {
-			err = atc.AddMethodCall(transaction.AddMethodCallParams{
-				AppID:           poolKey.PoolAppId,
-				Method:          gasMethod,
-				ForeignAccounts: []string{staker.String()}, // account MUST be referenced in same txn w/ foreign asset
-				ForeignAssets:   extraAssets,
-				ForeignApps:     extraApps,
-				SuggestedParams: params,
-				OnComplete:      types.NoOpOC,
-				Sender:          signer,
-				Signer:          algo.SignWithAccountForATC(r.signer, signer.String()),
-			})
-		}
-		if feesToUse == 0 {
-			// we're simulating so go with super high budget
-			feesToUse = 240 * transaction.MinTxnFee
-		}
-		params.FlatFee = true
-		params.Fee = types.MicroAlgos(feesToUse)
-		err = atc.AddMethodCall(transaction.AddMethodCallParams{
-			AppID:  poolKey.PoolAppId,
-			Method: unstakeMethod,
-			MethodArgs: []any{
-				staker,
-				amount,
-			},
-			ForeignApps: []uint64{poolKey.PoolAppId},
-			BoxReferences: []types.AppBoxReference{
-				{AppID: 0, Name: GetStakerLedgerBoxName()},
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-				{AppID: 0, Name: nil}, // extra i/o
-			},
-			SuggestedParams: params,
-			OnComplete:      types.NoOpOC,
-			Sender:          signer,
-			Signer:          algo.SignWithAccountForATC(r.signer, signer.String()),
-		})
-		if err != nil {
-			return atc, err
-		}
-		return atc, err
-	}
+	comp := algo.Composer{}
+	opts := r.callOpts(params, signer)
 
-	// simulate first
-	atc, err := getAtc(0)
-	if err != nil {
-		return err
-	}
-	simResult, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
-	if err != nil {
-		return err
+	// the gas calls give the group room for all the references removeStake needs (pooled across the group) - paying
+	// out reward tokens needs quite a few more.
+	numGasCalls := 1
+	if config.RewardTokenId != 0 {
+		numGasCalls++
 	}
-	if simResult.SimulateResponse.TxnGroups[0].FailureMessage != "" {
-		return errors.New(simResult.SimulateResponse.TxnGroups[0].FailureMessage)
+	for range numGasCalls {
+		comp.AddMethodCall(r.registry().Gas(opts))
 	}
-	// Figure out how much app budget was added so we can know the real fees to use when we execute
-	atc, err = getAtc(2*transaction.MinTxnFee + transaction.MinTxnFee*(simResult.SimulateResponse.TxnGroups[0].AppBudgetAdded/700))
+	comp.AddMethodCall(contracts.StakingPool{AppID: poolKey.PoolAppId}.RemoveStake(opts, staker, amount))
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
 		return err
 	}
 
-	_, err = atc.Execute(r.algoClient, context.Background(), 4)
+	_, err = r.execute(group, "removeStake", poolKey.PoolAppId, fmt.Sprintf("staker:%s, amount:%d", staker, amount))
 	if err != nil {
 		return err
 	}
@@ -1346,59 +854,19 @@ func (r *Reti) RemoveStake(poolKey ValidatorPoolKey, signer types.Address, stake
 func (r *Reti) EmptyTokenRewards(id uint64, signer types.Address, receiver types.Address) error {
 	var err error
 
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	params, err := r.chain.SuggestedParams(context.Background())
 	if err != nil {
 		return err
 	}
 
-	extraApps := []uint64{}
-	extraAssets := []uint64{}
-
-	config, err := r.GetValidatorConfig(id)
-	if err != nil {
-		return fmt.Errorf("get validator config err:%w", err)
-	}
-	pools, err := r.GetValidatorPools(id)
+	comp := algo.Composer{}
+	comp.AddMethodCall(r.registry().EmptyTokenRewards(r.callOpts(params, signer), id, receiver))
+	group, err := comp.Prepare(context.Background(), r.chain)
 	if err != nil {
-		return fmt.Errorf("unable to GetValidatorPools: %w", err)
+		return fmt.Errorf("unable to compose emptyTokenRewards err:%w", err)
 	}
 
-	if config.RewardTokenId != 0 {
-		extraAssets = append(extraAssets, config.RewardTokenId)
-		// If not pool 1 then we need to add reference for pool 1, so it can be called to update the pool token payout ratio
-		extraApps = append(extraApps, pools[0].PoolAppId)
-	}
-	atc := transaction.AtomicTransactionComposer{}
-	emptyTokenRewards, _ := r.validatorContract.GetMethodByName("emptyTokenRewards")
-
-	err = atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:  r.RetiAppId,
-		Method: emptyTokenRewards,
-		MethodArgs: []any{
-			id,
-			receiver,
-		},
-		ForeignApps:   extraApps,
-		ForeignAssets: extraAssets,
-		BoxReferences: []types.AppBoxReference{
-			{AppID: r.RetiAppId, Name: GetValidatorListBoxName(id)},
-			{AppID: r.RetiAppId, Name: nil}, // extra i/o
-			{AppID: 0, Name: nil},           // extra i/o
-			{AppID: 0, Name: nil},           // extra i/o
-			{AppID: 0, Name: nil},           // extra i/o
-			{AppID: 0, Name: nil},           // extra i/o
-		},
-
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          signer,
-		Signer:          algo.SignWithAccountForATC(r.signer, signer.String()),
-	})
-	if err != nil {
-		return fmt.Errorf("ATC error in composing emptyTokenRewards err:%w", err)
-	}
-
-	_, err = atc.Execute(r.algoClient, context.Background(), 4)
+	_, err = r.execute(group, "emptyTokenRewards", r.RetiAppId, fmt.Sprintf("validator:%d, receiver:%s", id, receiver))
 	if err != nil {
 		return err
 	}
@@ -1413,91 +881,37 @@ type MbrAmounts struct {
 }
 
 func (r *Reti) getMbrAmounts(caller types.Address) (MbrAmounts, error) {
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	opts, err := r.readOnlyOpts(caller)
 	if err != nil {
 		return MbrAmounts{}, err
 	}
-
-	method, err := r.validatorContract.GetMethodByName("getMbrAmounts")
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().GetMbrAmounts(opts))
 	if err != nil {
 		return MbrAmounts{}, err
 	}
-	atc := transaction.AtomicTransactionComposer{}
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:           r.RetiAppId,
-		Method:          method,
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          caller,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
+	mbrs, err := r.registry().GetMbrAmountsResult(result)
 	if err != nil {
 		return MbrAmounts{}, err
 	}
-	if result.SimulateResponse.TxnGroups[0].FailureMessage != "" {
-		return MbrAmounts{}, errors.New(result.SimulateResponse.TxnGroups[0].FailureMessage)
-	}
-
-	if results, ok := result.MethodResults[0].ReturnValue.([]any); ok {
-		if len(results) != 4 {
-			return MbrAmounts{}, errors.New("invalid number of results")
-		}
-		var mbrs MbrAmounts
-		mbrs.AddValidatorMbr = results[0].(uint64)
-		mbrs.AddPoolMbr = results[1].(uint64)
-		mbrs.PoolInitMbr = results[2].(uint64)
-		mbrs.AddStakerMbr = results[3].(uint64)
-		return mbrs, nil
-	}
-	return MbrAmounts{}, fmt.Errorf("unknown result type:%#v", result.MethodResults)
+	return MbrAmounts(mbrs), nil
 }
 
 func (r *Reti) doesStakerNeedToPayMBR(staker types.Address) (bool, error) {
-	params, err := r.algoClient.SuggestedParams().Do(context.Background())
+	opts, err := r.readOnlyOpts(staker)
 	if err != nil {
 		return false, err
 	}
-
-	method, err := r.validatorContract.GetMethodByName("doesStakerNeedToPayMBR")
-	if err != nil {
-		return false, err
-	}
-	atc := transaction.AtomicTransactionComposer{}
-	atc.AddMethodCall(transaction.AddMethodCallParams{
-		AppID:           r.RetiAppId,
-		Method:          method,
-		MethodArgs:      []any{staker},
-		SuggestedParams: params,
-		OnComplete:      types.NoOpOC,
-		Sender:          staker,
-		Signer:          transaction.EmptyTransactionSigner{},
-	})
-	result, err := atc.Simulate(context.Background(), r.algoClient, models.SimulateRequest{
-		AllowEmptySignatures:  true,
-		AllowUnnamedResources: true,
-	})
+	result, err := contracts.Simulate(context.Background(), r.chain, r.registry().DoesStakerNeedToPayMBR(opts, staker))
 	if err != nil {
 		return false, err
 	}
-	val := result.MethodResults[0].ReturnValue
-	if boolReturn, ok := val.(bool); ok {
-		return boolReturn, nil
-	}
-	return false, errors.New("unknown return value from doesStakerNeedToPayMBR")
+	return r.registry().DoesStakerNeedToPayMBRResult(result)
 }
 
 func (r *Reti) GetNumValidators() (uint64, error) {
-	appInfo, err := r.algoClient.GetApplicationByID(r.RetiAppId).Do(context.Background())
+	appInfo, err := r.chain.Application(context.Background(), r.RetiAppId)
 	if err != nil {
 		return 0, err
 	}
 	return algo.GetUint64FromGlobalState(appInfo.Params.GlobalState, VldtrNumValidators)
 }
-
-func (r *Reti) poolTemplateAppId() uint64 {
-	return r.poolTmplAppId
-}
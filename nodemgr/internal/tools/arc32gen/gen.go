@@ -0,0 +1,274 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	importBig         = "math/big"
+	importFmt         = "fmt"
+	importTransaction = "github.com/algorand/go-algorand-sdk/v2/transaction"
+	importTypes       = "github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+type generator struct {
+	pkg     string
+	structs []*structDef
+
+	// imports needed by the file currently being generated
+	imports map[string]bool
+}
+
+// contract generates the typed client for a contract
+func (g *generator) contract(spec *arc32Spec) string {
+	g.imports = map[string]bool{importTransaction: true}
+	var (
+		name = exportedName(spec.Contract.Name)
+		body strings.Builder
+	)
+	if spec.Contract.Desc != "" {
+		writeComment(&body, "", spec.Contract.Desc)
+		body.WriteString("//\n")
+	}
+	fmt.Fprintf(&body, "// %s is a typed client for the methods of the %s contract.\n", name, spec.Contract.Name)
+	body.WriteString("// Each method returns the params for calling it (to add to a transaction group) - methods with a return value\n")
+	body.WriteString("// have a matching Result method to decode it.\n")
+	fmt.Fprintf(&body, "type %s struct {\n\tAppID uint64\n}\n\n", name)
+
+	body.WriteString("var (\n")
+	for _, method := range spec.Contract.Methods {
+		fmt.Fprintf(&body, "\t%s = mustMethod(%q)\n", methodVarName(name, method.Name), method.signature())
+	}
+	body.WriteString(")\n")
+
+	for _, method := range spec.Contract.Methods {
+		goName := exportedName(method.Name)
+		body.WriteString("\n")
+		fmt.Fprintf(&body, "// %s returns the params for calling %s.\n", goName, method.signature())
+		if method.Desc != "" {
+			body.WriteString("//\n")
+			writeComment(&body, "", method.Desc)
+		}
+		if method.Readonly {
+			body.WriteString("//\n// The method is read-only, so it can be simulated rather than sent.\n")
+		}
+		params := []string{"opts CallOpts"}
+		args := []string{"c.AppID", methodVarName(name, method.Name)}
+		used := map[string]bool{"c": true, "opts": true}
+		for i, arg := range method.Args {
+			argName := paramName(arg.Name, used)
+			params = append(params, fmt.Sprintf("%s %s", argName, g.goType(method.argTypes[i])))
+			args = append(args, g.encodeExpr(method.argTypes[i], argName))
+		}
+		fmt.Fprintf(&body, "func (c %s) %s(%s) transaction.AddMethodCallParams {\n", name, goName, strings.Join(params, ", "))
+		fmt.Fprintf(&body, "\treturn opts.methodCall(%s)\n}\n", strings.Join(args, ", "))
+
+		if method.returnType == nil {
+			continue
+		}
+		fmt.Fprintf(&body, "\n// %sResult decodes the return value of a %s call.\n", goName, method.Name)
+		if method.Returns.Desc != "" {
+			body.WriteString("//\n")
+			writeComment(&body, "", method.Returns.Desc)
+		}
+		fmt.Fprintf(&body, "func (c %s) %sResult(result transaction.ABIMethodResult) (%s, error) {\n", name, goName, g.goType(method.returnType))
+		fmt.Fprintf(&body, "\treturn decodeResult(result, %s)\n}\n", g.decodeFunc(method.returnType))
+	}
+	return g.withImports(body.String())
+}
+
+// structsFile generates every struct, with functions to encode/decode them to/from the values used by the sdk's ABI
+// encoding.
+func (g *generator) structsFile() string {
+	g.imports = map[string]bool{importFmt: true}
+	var body strings.Builder
+	for _, def := range g.structs {
+		if def.Desc != "" {
+			writeComment(&body, "", def.Desc)
+		}
+		fmt.Fprintf(&body, "type %s struct {\n", def.Name)
+		for i, field := range def.Fields {
+			if field.Desc != "" {
+				writeComment(&body, "\t", field.Desc)
+			}
+			fmt.Fprintf(&body, "\t%s %s\n", field.goName(), g.goType(def.tuple.fields[i]))
+		}
+		body.WriteString("}\n\n")
+
+		fmt.Fprintf(&body, "func (s %s) abiValue() []any {\n\treturn []any{\n", def.Name)
+		for i, field := range def.Fields {
+			fmt.Fprintf(&body, "\t\t%s,\n", g.encodeExpr(def.tuple.fields[i], "s."+field.goName()))
+		}
+		body.WriteString("\t}\n}\n\n")
+
+		fmt.Fprintf(&body, "func decode%s(value any) (s %s, err error) {\n", def.Name, def.Name)
+		fmt.Fprintf(&body, "\tvalues, err := decodeTuple(value, %d)\n", len(def.Fields))
+		fmt.Fprintf(&body, "\tif err != nil {\n\t\treturn s, fmt.Errorf(\"%s: %%w\", err)\n\t}\n", def.Name)
+		for i, field := range def.Fields {
+			fieldName := field.goName()
+			fmt.Fprintf(&body, "\tif s.%s, err = %s(values[%d]); err != nil {\n", fieldName, g.decodeFunc(def.tuple.fields[i]), i)
+			fmt.Fprintf(&body, "\t\treturn s, fmt.Errorf(\"%s.%s: %%w\", err)\n\t}\n", def.Name, fieldName)
+		}
+		body.WriteString("\treturn s, nil\n}\n\n")
+	}
+	return g.withImports(body.String())
+}
+
+func (g *generator) withImports(body string) string {
+	var imports []string
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	// standard library imports first, in their own group
+	isStd := func(imp string) bool { return !strings.Contains(strings.Split(imp, "/")[0], ".") }
+	slices.SortFunc(imports, func(a, b string) int {
+		if isStd(a) != isStd(b) {
+			if isStd(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	var out strings.Builder
+	out.WriteString("import (\n")
+	for i, imp := range imports {
+		if i > 0 && isStd(imports[i-1]) && !isStd(imp) {
+			out.WriteString("\n")
+		}
+		fmt.Fprintf(&out, "\t%q\n", imp)
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(body)
+	return out.String()
+}
+
+// goType returns the Go type an ABI type is generated as
+func (g *generator) goType(t *abiType) string {
+	switch t.kind {
+	case kindUint, kindUfixed:
+		switch {
+		case t.bits <= 8:
+			return "uint8"
+		case t.bits <= 16:
+			return "uint16"
+		case t.bits <= 32:
+			return "uint32"
+		case t.bits <= 64:
+			return "uint64"
+		}
+		g.imports[importBig] = true
+		return "*big.Int"
+	case kindBool:
+		return "bool"
+	case kindByte:
+		return "byte"
+	case kindString:
+		return "string"
+	case kindAddress:
+		g.imports[importTypes] = true
+		return "types.Address"
+	case kindStaticArray:
+		return fmt.Sprintf("[%d]%s", t.length, g.goType(t.elem))
+	case kindDynamicArray:
+		return "[]" + g.goType(t.elem)
+	case kindTuple:
+		return t.structName
+	case kindTxn:
+		g.imports[importTransaction] = true
+		return "transaction.TransactionWithSigner"
+	case kindRef:
+		if t.name == "account" {
+			g.imports[importTypes] = true
+			return "types.Address"
+		}
+		return "uint64"
+	}
+	panic(fmt.Sprintf("unhandled type:%s", t))
+}
+
+// encodeExpr returns the expression converting the Go value expr of an ABI type to what the sdk ABI encoding
+// accepts.  Structs have to be converted to a slice of their fields - everything else can be used as is.
+func (g *generator) encodeExpr(t *abiType, expr string) string {
+	switch {
+	case t.kind == kindTuple:
+		return expr + ".abiValue()"
+	case t.kind == kindStaticArray && t.hasStruct():
+		return fmt.Sprintf("encodeArray(%s[:], func(elem %s) any { return %s })", expr, g.goType(t.elem), g.encodeExpr(t.elem, "elem"))
+	case t.kind == kindDynamicArray && t.hasStruct():
+		return fmt.Sprintf("encodeArray(%s, func(elem %s) any { return %s })", expr, g.goType(t.elem), g.encodeExpr(t.elem, "elem"))
+	}
+	return expr
+}
+
+// decodeFunc returns an expression of a func(any) (T, error) decoding a value of an ABI type, as decoded by the
+// sdk ABI decoding, to its Go type.
+func (g *generator) decodeFunc(t *abiType) string {
+	switch t.kind {
+	case kindUint, kindUfixed:
+		goType := g.goType(t)
+		if goType == "*big.Int" {
+			return "decodeBigInt"
+		}
+		return "decode" + exportedName(goType)
+	case kindBool:
+		return "decodeBool"
+	case kindByte:
+		return "decodeByte"
+	case kindString:
+		return "decodeString"
+	case kindAddress:
+		return "decodeAddress"
+	case kindStaticArray:
+		return fmt.Sprintf("func(value any) (arr %s, err error) {\n\terr = decodeArray(value, arr[:], %s)\n\treturn arr, err\n}", g.goType(t), g.decodeFunc(t.elem))
+	case kindDynamicArray:
+		if t.elem.kind == kindByte {
+			return "decodeBytes"
+		}
+		return fmt.Sprintf("func(value any) (%s, error) {\n\treturn decodeSlice(value, %s)\n}", g.goType(t), g.decodeFunc(t.elem))
+	case kindTuple:
+		return "decode" + t.structName
+	}
+	panic(fmt.Sprintf("type:%s can't be decoded", t))
+}
+
+// exportedName returns name as an exported Go identifier, ie: poolAppId -> PoolAppId, id -> ID
+func exportedName(name string) string {
+	if strings.EqualFold(name, "id") {
+		return "ID"
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// paramName returns an (unused) Go parameter name for an ABI method arg
+func paramName(name string, used map[string]bool) string {
+	runes := []rune(name)
+	runes[0] = unicode.ToLower(runes[0])
+	param := string(runes)
+	for token.IsKeyword(param) || used[param] || slices.Contains([]string{"big", "fmt", "transaction", "types"}, param) {
+		param += "_"
+	}
+	used[param] = true
+	return param
+}
+
+func methodVarName(contractName, methodName string) string {
+	return "method" + contractName + exportedName(methodName)
+}
+
+func writeComment(out *strings.Builder, indent string, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if line == "" {
+			fmt.Fprintf(out, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(out, "%s// %s\n", indent, line)
+	}
+}
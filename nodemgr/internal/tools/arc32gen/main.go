@@ -0,0 +1,238 @@
+// Command arc32gen generates typed Go clients for contracts from their ARC-32 application specs.
+//
+// ARC-32 specs only describe ABI tuples by their types, so the names of the structs (and their fields) the tuples
+// are generated as are read from a separate structs file.  Every tuple used by a method must match exactly one of
+// the defined structs.
+//
+// Usage:
+//
+//	arc32gen -structs structs.json -pkg contracts -out dir app1.arc32.json [app2.arc32.json ...]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type structsFile struct {
+	Structs []*structDef `json:"structs"`
+}
+
+type structDef struct {
+	Name   string     `json:"name"`
+	Desc   string     `json:"desc"`
+	Fields []fieldDef `json:"fields"`
+
+	// tuple is the resolved ABI type of the struct
+	tuple *abiType
+}
+
+type fieldDef struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Desc string `json:"desc"`
+	// GoName optionally overrides the name of the field in Go (which is otherwise just the exported name)
+	GoName string `json:"goName"`
+}
+
+func (f fieldDef) goName() string {
+	if f.GoName != "" {
+		return f.GoName
+	}
+	return exportedName(f.Name)
+}
+
+type arc32Spec struct {
+	Contract struct {
+		Name    string      `json:"name"`
+		Desc    string      `json:"desc"`
+		Methods []methodDef `json:"methods"`
+	} `json:"contract"`
+}
+
+type methodDef struct {
+	Name     string `json:"name"`
+	Desc     string `json:"desc"`
+	Readonly bool   `json:"readonly"`
+	Args     []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+		Desc string `json:"desc"`
+	} `json:"args"`
+	Returns struct {
+		Type string `json:"type"`
+		Desc string `json:"desc"`
+	} `json:"returns"`
+
+	// parsed arg and return types - returnType is nil for void
+	argTypes   []*abiType
+	returnType *abiType
+}
+
+func (m *methodDef) signature() string {
+	args := make([]string, len(m.Args))
+	for i, arg := range m.Args {
+		args[i] = arg.Type
+	}
+	return fmt.Sprintf("%s(%s)%s", m.Name, strings.Join(args, ","), m.Returns.Type)
+}
+
+func main() {
+	var (
+		structsPath = flag.String("structs", "", "json file defining the structs ABI tuples are generated as")
+		pkg         = flag.String("pkg", "contracts", "package name of the generated code")
+		outDir      = flag.String("out", ".", "directory to write the generated code to")
+	)
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("at least one arc32 application spec must be specified")
+	}
+
+	structs, err := loadStructs(*structsPath)
+	if err != nil {
+		log.Fatalf("loading structs: %v", err)
+	}
+	gen := &generator{pkg: *pkg, structs: structs}
+
+	var sources []string
+	for _, specPath := range flag.Args() {
+		spec, err := loadSpec(specPath, structs)
+		if err != nil {
+			log.Fatalf("loading %s: %v", specPath, err)
+		}
+		sources = append(sources, filepath.Base(specPath))
+		if err = gen.writeFile(*outDir, strings.ToLower(spec.Contract.Name)+".go", filepath.Base(specPath), gen.contract(spec)); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if err = gen.writeFile(*outDir, "structs.go", strings.Join(sources, ", "), gen.structsFile()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func loadStructs(path string) ([]*structDef, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file structsFile
+	if err = json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	byName := map[string]*structDef{}
+	for _, def := range file.Structs {
+		if _, found := byName[def.Name]; found {
+			return nil, fmt.Errorf("struct %s defined more than once", def.Name)
+		}
+		byName[def.Name] = def
+	}
+	// resolve each struct to its tuple type - fields can refer to other structs (in any order)
+	resolving := map[string]bool{}
+	var resolve func(name string) (*abiType, error)
+	resolve = func(name string) (*abiType, error) {
+		def, found := byName[name]
+		if !found {
+			return nil, fmt.Errorf("unknown type:%s", name)
+		}
+		if def.tuple != nil {
+			return def.tuple, nil
+		}
+		if resolving[name] {
+			return nil, fmt.Errorf("struct %s refers to itself", name)
+		}
+		resolving[name] = true
+		tuple := &abiType{kind: kindTuple, structName: name}
+		for _, field := range def.Fields {
+			fieldType, err := parseType(field.Type, resolve)
+			if err != nil {
+				return nil, fmt.Errorf("struct %s field %s: %w", name, field.Name, err)
+			}
+			tuple.fields = append(tuple.fields, fieldType)
+		}
+		def.tuple = tuple
+		return tuple, nil
+	}
+	for _, def := range file.Structs {
+		if _, err = resolve(def.Name); err != nil {
+			return nil, err
+		}
+	}
+	return file.Structs, nil
+}
+
+func loadSpec(path string, structs []*structDef) (*arc32Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec arc32Spec
+	if err = json.Unmarshal(data, &spec); err != nil {
+		return nil, err
+	}
+	if spec.Contract.Name == "" {
+		return nil, fmt.Errorf("spec has no contract name")
+	}
+	for i := range spec.Contract.Methods {
+		method := &spec.Contract.Methods[i]
+		for _, arg := range method.Args {
+			argType, err := parseType(arg.Type, nil)
+			if err != nil {
+				return nil, fmt.Errorf("method %s arg %s: %w", method.Name, arg.Name, err)
+			}
+			method.argTypes = append(method.argTypes, argType)
+		}
+		if method.Returns.Type != "void" {
+			if method.returnType, err = parseType(method.Returns.Type, nil); err != nil {
+				return nil, fmt.Errorf("method %s return: %w", method.Name, err)
+			}
+		}
+		for _, methodType := range append(method.argTypes, method.returnType) {
+			if methodType == nil {
+				continue
+			}
+			if err = methodType.walk(func(t *abiType) error { return nameTuple(t, structs) }); err != nil {
+				return nil, fmt.Errorf("method %s: %w", method.signature(), err)
+			}
+		}
+	}
+	return &spec, nil
+}
+
+// nameTuple sets the struct name of a tuple, from the (single) struct with the same ABI type
+func nameTuple(t *abiType, structs []*structDef) error {
+	if t.kind != kindTuple || t.structName != "" {
+		return nil
+	}
+	var matches []string
+	for _, def := range structs {
+		if def.tuple.String() == t.String() {
+			matches = append(matches, def.Name)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return fmt.Errorf("no struct defined for tuple %s", t)
+	case 1:
+		t.structName = matches[0]
+		return nil
+	}
+	return fmt.Errorf("tuple %s matches more than one struct: %s", t, strings.Join(matches, ", "))
+}
+
+func (g *generator) writeFile(dir, name, source string, body string) error {
+	code := fmt.Sprintf("// Code generated by arc32gen from %s. DO NOT EDIT.\n\npackage %s\n\n%s", source, g.pkg, body)
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return fmt.Errorf("formatting %s: %w\n%s", name, err, code)
+	}
+	return os.WriteFile(filepath.Join(dir, name), formatted, 0o644)
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type typeKind int
+
+const (
+	kindUint typeKind = iota
+	kindUfixed
+	kindBool
+	kindByte
+	kindAddress
+	kindString
+	kindStaticArray
+	kindDynamicArray
+	kindTuple
+	// transaction arguments (pay, axfer, ...)
+	kindTxn
+	// reference arguments (account, asset, application)
+	kindRef
+)
+
+// abiType is a parsed ABI type.  Unlike the sdk's abi.Type, it exposes its structure, which is what we need to
+// generate code for it.
+type abiType struct {
+	kind   typeKind
+	bits   int        // uint/ufixed
+	prec   int        // ufixed
+	elem   *abiType   // arrays
+	length int        // static arrays
+	fields []*abiType // tuples
+	name   string     // txn and reference types, ie: pay, account
+
+	// structName is the name of the struct a tuple is generated as
+	structName string
+}
+
+func (t *abiType) String() string {
+	switch t.kind {
+	case kindUint:
+		return fmt.Sprintf("uint%d", t.bits)
+	case kindUfixed:
+		return fmt.Sprintf("ufixed%dx%d", t.bits, t.prec)
+	case kindBool:
+		return "bool"
+	case kindByte:
+		return "byte"
+	case kindAddress:
+		return "address"
+	case kindString:
+		return "string"
+	case kindStaticArray:
+		return fmt.Sprintf("%s[%d]", t.elem, t.length)
+	case kindDynamicArray:
+		return fmt.Sprintf("%s[]", t.elem)
+	case kindTuple:
+		fields := make([]string, len(t.fields))
+		for i, field := range t.fields {
+			fields[i] = field.String()
+		}
+		return "(" + strings.Join(fields, ",") + ")"
+	}
+	return t.name
+}
+
+// hasStruct returns true if the type is (or contains) a tuple
+func (t *abiType) hasStruct() bool {
+	switch t.kind {
+	case kindTuple:
+		return true
+	case kindStaticArray, kindDynamicArray:
+		return t.elem.hasStruct()
+	}
+	return false
+}
+
+// walk calls fn for the type and every type within it, innermost first
+func (t *abiType) walk(fn func(t *abiType) error) error {
+	if t.elem != nil {
+		if err := t.elem.walk(fn); err != nil {
+			return err
+		}
+	}
+	for _, field := range t.fields {
+		if err := field.walk(fn); err != nil {
+			return err
+		}
+	}
+	return fn(t)
+}
+
+var (
+	txnTypes = []string{"txn", "pay", "keyreg", "acfg", "axfer", "afrz", "appl"}
+	refTypes = []string{"account", "asset", "application"}
+)
+
+// parseType parses an ABI type string.  lookupStruct resolves any other (non ABI) type names, which is used for
+// struct fields referring to other structs - it can be nil.
+func parseType(str string, lookupStruct func(name string) (*abiType, error)) (*abiType, error) {
+	str = strings.TrimSpace(str)
+	if str == "" {
+		return nil, fmt.Errorf("empty type")
+	}
+	// array suffixes apply to everything before them, so parse from the end
+	if strings.HasSuffix(str, "]") {
+		open := strings.LastIndex(str, "[")
+		if open < 0 {
+			return nil, fmt.Errorf("invalid array type:%s", str)
+		}
+		elem, err := parseType(str[:open], lookupStruct)
+		if err != nil {
+			return nil, err
+		}
+		lengthStr := str[open+1 : len(str)-1]
+		if lengthStr == "" {
+			return &abiType{kind: kindDynamicArray, elem: elem}, nil
+		}
+		length, err := strconv.Atoi(lengthStr)
+		if err != nil || length < 0 {
+			return nil, fmt.Errorf("invalid static array length in type:%s", str)
+		}
+		return &abiType{kind: kindStaticArray, elem: elem, length: length}, nil
+	}
+	if strings.HasPrefix(str, "(") {
+		if !strings.HasSuffix(str, ")") {
+			return nil, fmt.Errorf("invalid tuple type:%s", str)
+		}
+		tuple := &abiType{kind: kindTuple}
+		for _, fieldStr := range splitTuple(str[1 : len(str)-1]) {
+			field, err := parseType(fieldStr, lookupStruct)
+			if err != nil {
+				return nil, err
+			}
+			tuple.fields = append(tuple.fields, field)
+		}
+		return tuple, nil
+	}
+
+	switch {
+	case str == "bool":
+		return &abiType{kind: kindBool}, nil
+	case str == "byte":
+		return &abiType{kind: kindByte}, nil
+	case str == "address":
+		return &abiType{kind: kindAddress}, nil
+	case str == "string":
+		return &abiType{kind: kindString}, nil
+	case strings.HasPrefix(str, "uint"):
+		bits, err := strconv.Atoi(strings.TrimPrefix(str, "uint"))
+		if err != nil || bits < 8 || bits > 512 || bits%8 != 0 {
+			return nil, fmt.Errorf("invalid uint type:%s", str)
+		}
+		return &abiType{kind: kindUint, bits: bits}, nil
+	case strings.HasPrefix(str, "ufixed"):
+		bitsStr, precStr, found := strings.Cut(strings.TrimPrefix(str, "ufixed"), "x")
+		bits, err := strconv.Atoi(bitsStr)
+		prec, precErr := strconv.Atoi(precStr)
+		if !found || err != nil || precErr != nil || bits < 8 || bits > 512 || bits%8 != 0 {
+			return nil, fmt.Errorf("invalid ufixed type:%s", str)
+		}
+		return &abiType{kind: kindUfixed, bits: bits, prec: prec}, nil
+	}
+	for _, txnType := range txnTypes {
+		if str == txnType {
+			return &abiType{kind: kindTxn, name: str}, nil
+		}
+	}
+	for _, refType := range refTypes {
+		if str == refType {
+			return &abiType{kind: kindRef, name: str}, nil
+		}
+	}
+	if lookupStruct != nil {
+		return lookupStruct(str)
+	}
+	return nil, fmt.Errorf("unknown type:%s", str)
+}
+
+// splitTuple splits the contents of a tuple type into its (top level) field types
+func splitTuple(str string) []string {
+	if str == "" {
+		return nil
+	}
+	var (
+		fields []string
+		depth  int
+		start  int
+	)
+	for i, ch := range str {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				fields = append(fields, str[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(fields, str[start:])
+}
@@ -37,7 +37,7 @@ func GetKeyCmdOpts() *cli.Command {
 }
 
 func KeysList(ctx context.Context, command *cli.Command) error {
-	partKeys, err := algo.GetParticipationKeys(ctx, App.algoClient)
+	partKeys, err := algo.GetParticipationKeys(ctx, App.chain)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+)
+
+// KeyPolicy defines how participation keys are generated and renewed for a pool
+type KeyPolicy struct {
+	// Length is how long generated keys are valid for (converted to rounds using the current avg block time)
+	Length time.Duration
+	// RenewLead is how long before the active key expires that its replacement is created.  The new key overlaps
+	// the active key by this amount so the pool can switch over before expiration.
+	RenewLead time.Duration
+	// Dilution is the key dilution to use - 0 uses the algod default (sqrt of the validity window)
+	Dilution uint64
+	// MaxPending is the maximum number of not yet valid keys an account can have - no new keys are created for the
+	// account beyond this.
+	MaxPending int
+}
+
+func (p KeyPolicy) String() string {
+	return fmt.Sprintf("length:%v, renew:%v, dilution:%d, maxpending:%d", p.Length, p.RenewLead, p.Dilution, p.MaxPending)
+}
+
+func (p KeyPolicy) validate() error {
+	if p.Length <= 0 {
+		return fmt.Errorf("key length must be greater than 0")
+	}
+	if p.RenewLead <= 0 || p.RenewLead >= p.Length {
+		return fmt.Errorf("key renewal lead time must be greater than 0 and less than the key length (%v)", p.Length)
+	}
+	if p.MaxPending < 1 {
+		return fmt.Errorf("max pending keys must be at least 1")
+	}
+	return nil
+}
+
+// DefaultKeyPolicy is the key policy used when nothing is configured
+var DefaultKeyPolicy = KeyPolicy{
+	Length:     GeneratedKeyLengthInDays * 24 * time.Hour,
+	RenewLead:  DaysPriorToExpToRenew * 24 * time.Hour,
+	MaxPending: 1,
+}
+
+// KeyPolicies is the validator-wide key policy along with any per-pool overrides
+type KeyPolicies struct {
+	Default KeyPolicy
+	Pools   map[uint64]KeyPolicy // keyed by pool id
+}
+
+// ForPool returns the key policy for the specified pool id
+func (k KeyPolicies) ForPool(poolId uint64) KeyPolicy {
+	if policy, found := k.Pools[poolId]; found {
+		return policy
+	}
+	return k.Default
+}
+
+// newKeyPolicies returns the key policies for the validator - poolSpecs are ';' separated per-pool overrides of
+// the form: poolid:key=value[,key=value...] where key is one of length, renew, dilution or maxpending.  Any value
+// not specified in the override comes from the validator-wide policy.
+func newKeyPolicies(defaultPolicy KeyPolicy, poolSpecs string) (KeyPolicies, error) {
+	if err := defaultPolicy.validate(); err != nil {
+		return KeyPolicies{}, err
+	}
+	policies := KeyPolicies{Default: defaultPolicy, Pools: map[uint64]KeyPolicy{}}
+	for _, spec := range strings.Split(poolSpecs, ";") {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+		poolId, policy, err := parsePoolKeyPolicy(spec, defaultPolicy)
+		if err != nil {
+			return KeyPolicies{}, err
+		}
+		policies.Pools[poolId] = policy
+	}
+	return policies, nil
+}
+
+func parsePoolKeyPolicy(spec string, basePolicy KeyPolicy) (uint64, KeyPolicy, error) {
+	poolStr, settings, found := strings.Cut(spec, ":")
+	if !found {
+		return 0, KeyPolicy{}, fmt.Errorf("invalid pool key policy:%q, should be poolid:key=value[,key=value...]", spec)
+	}
+	poolId, err := strconv.ParseUint(strings.TrimSpace(poolStr), 10, 64)
+	if err != nil || poolId == 0 {
+		return 0, KeyPolicy{}, fmt.Errorf("invalid pool id in pool key policy:%q", spec)
+	}
+	policy := basePolicy
+	for _, setting := range strings.Split(settings, ",") {
+		key, value, found := strings.Cut(setting, "=")
+		if !found {
+			return 0, KeyPolicy{}, fmt.Errorf("invalid setting:%q in pool key policy:%q", setting, spec)
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "length":
+			policy.Length, err = parseDurationWithDays(value)
+		case "renew":
+			policy.RenewLead, err = parseDurationWithDays(value)
+		case "dilution":
+			policy.Dilution, err = strconv.ParseUint(value, 10, 64)
+		case "maxpending":
+			policy.MaxPending, err = strconv.Atoi(value)
+		default:
+			err = fmt.Errorf("unknown setting")
+		}
+		if err != nil {
+			return 0, KeyPolicy{}, fmt.Errorf("invalid setting:%q in pool key policy:%q, err:%w", setting, spec, err)
+		}
+	}
+	if err := policy.validate(); err != nil {
+		return 0, KeyPolicy{}, fmt.Errorf("invalid key policy for pool %d: %w", poolId, err)
+	}
+	return poolId, policy, nil
+}
+
+// parseDurationWithDays parses a standard go duration (ie: 36h, 90m), but also allows days (ie: 30d)
+func parseDurationWithDays(value string) (time.Duration, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		numDays, err := strconv.ParseFloat(days, 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(numDays * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(value)
+}
+
+// pendingKeys returns the number of keys that aren't valid yet as of curRound
+func pendingKeys(keys []algo.ParticipationKey, curRound uint64) int {
+	var numPending int
+	for _, key := range keys {
+		if key.Key.VoteFirstValid > curRound {
+			numPending++
+		}
+	}
+	return numPending
+}
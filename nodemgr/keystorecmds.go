@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/mnemonic"
+	"github.com/urfave/cli/v3"
+	"golang.org/x/term"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+)
+
+func GetKeystoreCmdOpts() *cli.Command {
+	return &cli.Command{
+		Name:     "keystore",
+		Usage:    "Manage the encrypted keystore (set via --keystore) holding manager/owner keys",
+		Metadata: standaloneCommand,
+		Before: func(ctx context.Context, command *cli.Command) error {
+			if command.String("keystore") == "" {
+				return errors.New("no keystore configured - set via --keystore or RETI_KEYSTORE")
+			}
+			return nil
+		},
+		Commands: []*cli.Command{
+			{
+				Name:   "init",
+				Usage:  "Create a new (empty) keystore, prompting for its passphrase (unless --keystorepassfile is set)",
+				Action: KeystoreInit,
+			},
+			{
+				Name:   "import",
+				Usage:  "Import an account's mnemonic into the keystore - the mnemonic is prompted for (or read from stdin)",
+				Action: KeystoreImport,
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "env",
+						Usage: "Import all the mnemonics defined in *_MNEMONIC environment variables instead",
+					},
+				},
+			},
+			{
+				Name:   "list",
+				Usage:  "List the accounts in the keystore",
+				Action: KeystoreList,
+			},
+			{
+				Name:   "remove",
+				Usage:  "Remove an account from the keystore",
+				Action: KeystoreRemove,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "account",
+						Usage:    "The account to remove",
+						Required: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func KeystoreInit(ctx context.Context, command *cli.Command) error {
+	passphrase, err := readKeystorePassphrase(command, true)
+	if err != nil {
+		return err
+	}
+	defer clear(passphrase)
+	keystore, err := algo.CreateKeystore(App.logger, command.String("keystore"), passphrase)
+	if err != nil {
+		return err
+	}
+	defer keystore.Close()
+	fmt.Printf("Keystore %s created\n", command.String("keystore"))
+	return nil
+}
+
+func KeystoreImport(ctx context.Context, command *cli.Command) error {
+	keystore, err := openKeystore(command)
+	if err != nil {
+		return err
+	}
+	defer keystore.Close()
+
+	var mnemonics []string
+	if command.Bool("env") {
+		for _, envVal := range os.Environ() {
+			name, value, _ := strings.Cut(envVal, "=")
+			if strings.HasSuffix(name, "_MNEMONIC") && value != "" {
+				mnemonics = append(mnemonics, value)
+			}
+		}
+		if len(mnemonics) == 0 {
+			return errors.New("no *_MNEMONIC environment variables defined")
+		}
+	} else {
+		phrase, err := readSecret("Mnemonic: ")
+		if err != nil {
+			return err
+		}
+		mnemonics = append(mnemonics, string(phrase))
+	}
+	for _, phrase := range mnemonics {
+		key, err := mnemonic.ToPrivateKey(strings.Join(strings.Fields(phrase), " "))
+		if err != nil {
+			return fmt.Errorf("invalid mnemonic: %w", err)
+		}
+		account, err := crypto.AccountFromPrivateKey(key)
+		if err != nil {
+			return err
+		}
+		if keystore.HasAccount(account.Address.String()) {
+			clear(key)
+			fmt.Printf("Account:%s is already in the keystore\n", account.Address)
+			continue
+		}
+		address, err := keystore.Import(key)
+		clear(key)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported account:%s\n", address)
+	}
+	return nil
+}
+
+func KeystoreList(ctx context.Context, command *cli.Command) error {
+	keystore, err := openKeystore(command)
+	if err != nil {
+		return err
+	}
+	defer keystore.Close()
+	for _, address := range keystore.Addresses() {
+		fmt.Println(address)
+	}
+	return nil
+}
+
+func KeystoreRemove(ctx context.Context, command *cli.Command) error {
+	keystore, err := openKeystore(command)
+	if err != nil {
+		return err
+	}
+	defer keystore.Close()
+	if err = keystore.Remove(command.String("account")); err != nil {
+		return err
+	}
+	fmt.Printf("Removed account:%s\n", command.String("account"))
+	return nil
+}
+
+// openKeystore opens the keystore defined via --keystore, unlocking it w/ the passphrase from --keystorepassfile or
+// prompted for.
+func openKeystore(command *cli.Command) (*algo.Keystore, error) {
+	passphrase, err := readKeystorePassphrase(command, false)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(passphrase)
+	return algo.OpenKeystore(App.logger, command.String("keystore"), passphrase)
+}
+
+// readKeystorePassphrase reads the keystore passphrase from the --keystorepassfile file if set, otherwise prompting
+// for it (twice if confirm is set)
+func readKeystorePassphrase(command *cli.Command, confirm bool) ([]byte, error) {
+	return readPassphrase(command, "keystorepassfile", "keystore", confirm)
+}
+
+// readPassphrase reads the passphrase of what (ie: keystore) from the file named by the passFileFlag flag if set,
+// otherwise prompting for it (twice if confirm is set)
+func readPassphrase(command *cli.Command, passFileFlag string, what string, confirm bool) ([]byte, error) {
+	if passFile := command.String(passFileFlag); passFile != "" {
+		passphrase, err := os.ReadFile(passFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read %s passphrase file: %w", what, err)
+		}
+		return bytes.TrimRight(passphrase, "\r\n"), nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, fmt.Errorf("%s passphrase file (--%s) must be set when not run interactively", what, passFileFlag)
+	}
+	passphrase, err := readSecret(fmt.Sprintf("%s%s passphrase: ", strings.ToUpper(what[:1]), what[1:]))
+	if err != nil || !confirm {
+		return passphrase, err
+	}
+	again, err := readSecret("Confirm passphrase: ")
+	defer clear(again)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(passphrase, again) {
+		clear(passphrase)
+		return nil, errors.New("passphrases don't match")
+	}
+	return passphrase, nil
+}
+
+// readSecret prompts for a secret without echoing it - or just reads a line from stdin if it's not a terminal
+func readSecret(prompt string) ([]byte, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		line, err := bufio.NewReader(os.Stdin).ReadBytes('\n')
+		if err != nil && len(line) == 0 {
+			return nil, fmt.Errorf("unable to read from stdin: %w", err)
+		}
+		return bytes.TrimRight(line, "\r\n"), nil
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/TxnLab/reti/internal/lib/lease"
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+var promIsLeader = promauto.NewGauge(prometheus.GaugeOpts{
+	Subsystem: "reti",
+	Name:      "daemon_leader",
+	Help:      "1 if this daemon instance holds the lease for its node and is running the mutating workers",
+})
+
+// leaderElection decides which of (possibly) several daemon replicas for the same validator/node number is
+// allowed to run the workers that sign and submit transactions.
+type leaderElection struct {
+	backend lease.Backend
+	name    string
+	holder  string
+	ttl     time.Duration
+}
+
+func newLeaderElection(backend lease.Backend, validatorID, nodeNum uint64, ttl time.Duration) *leaderElection {
+	hostname, _ := os.Hostname()
+	return &leaderElection{
+		backend: backend,
+		name:    fmt.Sprintf("reti-v%d-n%d", validatorID, nodeNum),
+		holder:  fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		ttl:     ttl,
+	}
+}
+
+// getLeaseBackend returns the lease backend requested by the daemon flags - nil if replicas aren't coordinated
+// at all (single instance - always 'leader').
+func getLeaseBackend(kind, dir, url string) (lease.Backend, error) {
+	switch kind {
+	case "", "none":
+		return nil, nil
+	case "file":
+		if dir == "" {
+			return nil, fmt.Errorf("lease directory must be specified for file leases")
+		}
+		return lease.NewFileBackend(dir)
+	case "http":
+		if url == "" {
+			return nil, fmt.Errorf("lease url must be specified for http leases")
+		}
+		return lease.NewHTTPBackend(url, misc.GetSecret("RETI_LEASE_TOKEN")), nil
+	default:
+		return nil, fmt.Errorf("unknown lease type:%s", kind)
+	}
+}
+
+// activeWorkers tracks the set of mutating workers started while we hold the lease.
+type activeWorkers struct {
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func (a *activeWorkers) start(ctx context.Context, startWorkers func(ctx context.Context, wg *sync.WaitGroup)) {
+	var workerCtx context.Context
+	workerCtx, a.cancel = context.WithCancel(ctx)
+	startWorkers(workerCtx, &a.wg)
+	promIsLeader.Set(1)
+}
+
+func (a *activeWorkers) stop() {
+	a.cancel()
+	a.wg.Wait()
+	a.cancel = nil
+	promIsLeader.Set(0)
+}
+
+func (a *activeWorkers) running() bool {
+	return a.cancel != nil
+}
+
+// runAsCandidate competes for the lease, running the mutating workers (via startWorkers) only while this instance
+// holds it.  If the lease is lost (or can't be renewed before it would expire) the workers are stopped and we go
+// back to standby, trying to reacquire.
+func (d *Daemon) runAsCandidate(ctx context.Context, startWorkers func(ctx context.Context, wg *sync.WaitGroup)) {
+	var (
+		election    = d.election
+		renewEvery  = election.ttl / 3
+		lastRenewed time.Time
+		workers     activeWorkers
+		tryAcquire  = time.NewTicker(renewEvery)
+	)
+	defer tryAcquire.Stop()
+	misc.Infof(d.logger, "lease candidate:%s for %s, ttl:%v", election.holder, election.name, election.ttl)
+
+	for {
+		// while leading, renewal can only take until the point we'd have to step down anyway (safely before the
+		// lease expires) - a hung backend mustn't keep the workers running past the lease.
+		acquireCtx, cancelAcquire := context.WithTimeout(ctx, election.ttl)
+		if workers.running() {
+			cancelAcquire()
+			acquireCtx, cancelAcquire = context.WithDeadline(ctx, lastRenewed.Add(election.ttl-renewEvery))
+		}
+		// the lease's ttl counts from (at the latest) when we asked for it, not when the answer came back
+		attempted := time.Now()
+		acquired, err := election.backend.Acquire(acquireCtx, election.name, election.holder, election.ttl)
+		cancelAcquire()
+		switch {
+		case err != nil:
+			misc.Warnf(d.logger, "unable to acquire/renew lease %s: %v", election.name, err)
+			// keep running only as long as our last successful renewal is still safely within the ttl
+			if workers.running() && time.Since(lastRenewed) >= election.ttl-renewEvery {
+				misc.Warnf(d.logger, "stepping down as leader for %s, lease couldn't be renewed before expiration", election.name)
+				workers.stop()
+				d.setActive(false)
+			}
+		case acquired:
+			lastRenewed = attempted
+			if !workers.running() {
+				misc.Infof(d.logger, "acquired lease %s, now active", election.name)
+				workers.start(ctx, startWorkers)
+				d.setActive(true)
+			}
+		default:
+			if workers.running() {
+				misc.Warnf(d.logger, "stepping down as leader for %s, lease now held by another instance", election.name)
+				workers.stop()
+				d.setActive(false)
+			} else {
+				misc.Debugf(d.logger, "lease %s held by another instance, standing by", election.name)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if workers.running() {
+				misc.Infof(d.logger, "shutting down, giving up lease %s", election.name)
+				workers.stop()
+				d.setActive(false)
+			}
+			// give the lease up right away so the standby can take over without waiting for the ttl
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := election.backend.Release(releaseCtx, election.name, election.holder); err != nil {
+				misc.Warnf(d.logger, "unable to release lease %s: %v", election.name, err)
+			}
+			cancel()
+			return
+		case <-tryAcquire.C:
+		}
+	}
+}
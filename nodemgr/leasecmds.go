@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/TxnLab/reti/internal/lib/lease"
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+func GetLeaseCmdOpts() *cli.Command {
+	return &cli.Command{
+		Name:     "lease",
+		Usage:    "Lease service used to coordinate active/standby daemons",
+		Metadata: standaloneCommand,
+		Commands: []*cli.Command{
+			{
+				Name:   "serve",
+				Usage:  "Run a simple in-memory lease service (stand-in for a real lease service) for use with --lease http",
+				Action: LeaseServe,
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "port",
+						Usage: "port to listen on",
+						Value: 6270,
+					},
+				},
+			},
+		},
+	}
+}
+
+func LeaseServe(ctx context.Context, command *cli.Command) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	host := fmt.Sprintf(":%d", command.Int("port"))
+	srv := &http.Server{Addr: host, Handler: lease.NewServer(misc.GetSecret("RETI_LEASE_TOKEN"))}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	misc.Infof(App.logger, "lease service listening on %q", host)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"log/slog"
 	"os"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
 )
 
 var App *RetiApp
@@ -11,6 +13,8 @@ var App *RetiApp
 func main() {
 	App = initApp()
 	err := App.cliCmd.Run(context.Background(), os.Args)
+	// zero any keys held in memory
+	_ = algo.CloseSigner(App.signer)
 	if err != nil {
 		slog.Error("Error in execution:", "msg", err)
 		os.Exit(1)
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/events"
+	"github.com/TxnLab/reti/internal/lib/history"
+	"github.com/TxnLab/reti/internal/lib/misc"
+	"github.com/TxnLab/reti/internal/lib/reti"
+)
+
+const (
+	// how far back manager spending is considered for the spend rate
+	spendRateWindow = 7 * 24 * time.Hour
+	// spend rate (and so runway) isn't reported until we've been tracking spending at least this long
+	minSpendRateTracking = 1 * time.Hour
+)
+
+var (
+	promManagerSpendable = promauto.NewGauge(prometheus.GaugeOpts{
+		Subsystem: "reti",
+		Name:      "manager_spendable_microalgos",
+		Help:      "Spendable balance of the validator manager account",
+	})
+	promManagerSpent = promauto.NewCounter(prometheus.CounterOpts{
+		Subsystem: "reti",
+		Name:      "manager_spent_microalgos_total",
+		Help:      "Fees and payments sent by the manager account in transactions made by this daemon",
+	})
+	promManagerSpendRate = promauto.NewGauge(prometheus.GaugeOpts{
+		Subsystem: "reti",
+		Name:      "manager_spend_rate_microalgos_per_day",
+		Help:      "Average daily spend of the manager account, over up to the last 7 days",
+	})
+	promManagerRunway = promauto.NewGauge(prometheus.GaugeOpts{
+		Subsystem: "reti",
+		Name:      "manager_runway_seconds",
+		Help:      "Forecast of how long until the manager account runs out of funds at the current spend rate",
+	})
+	promManagerTopUps = promauto.NewCounter(prometheus.CounterOpts{
+		Subsystem: "reti",
+		Name:      "manager_topups_total",
+		Help:      "Number of times the manager account was funded from the treasury account",
+	})
+	promManagerTopUpAmount = promauto.NewCounter(prometheus.CounterOpts{
+		Subsystem: "reti",
+		Name:      "manager_topup_microalgos_total",
+		Help:      "Total amount funded to the manager account from the treasury account",
+	})
+)
+
+// TopUpConfig configures automatic funding of the manager account from a treasury account.  Top-ups are tracked in
+// the history database, so the daily cap and minimum interval are per history database - with multiple replicas
+// (lease election) they're only enforced across failovers if the database is shared between them.
+type TopUpConfig struct {
+	// Treasury is the account funds come from - its key must be available locally
+	Treasury string
+	// Below is the spendable balance (in microAlgos) of the manager account that triggers a top-up
+	Below uint64
+	// Amount (in microAlgos) to send on each top-up
+	Amount uint64
+	// DailyCap is the maximum amount (in microAlgos) sent within any 24 hour period
+	DailyCap uint64
+	// MinInterval is the minimum time between top-ups
+	MinInterval time.Duration
+}
+
+func (c TopUpConfig) validate(signer algo.MultipleWalletSigner) error {
+	if c.Amount == 0 {
+		return fmt.Errorf("top-up amount must be greater than 0")
+	}
+	if c.DailyCap < c.Amount {
+		return fmt.Errorf("top-up daily cap must be at least the top-up amount")
+	}
+	if !signer.HasAccount(c.Treasury) {
+		return fmt.Errorf("treasury account:%s doesn't have a local key present", c.Treasury)
+	}
+	return nil
+}
+
+type fundsEntry struct {
+	time   time.Time
+	amount uint64
+}
+
+// managerFunds tracks what the manager account spends, forecasting how long its balance lasts, and optionally
+// tops it up from a treasury account.
+type managerFunds struct {
+	// topUp is nil if automatic top-ups aren't enabled
+	topUp *TopUpConfig
+
+	sync.Mutex
+	started time.Time
+	spends  []fundsEntry // most recent last, within spendRateWindow
+	topUps  []fundsEntry // most recent last, within the last 24 hours
+	// lastTopUp is kept separately from topUps, as MinInterval can be longer than 24 hours
+	lastTopUp time.Time
+}
+
+func newManagerFunds(topUp *TopUpConfig) *managerFunds {
+	return &managerFunds{topUp: topUp, started: time.Now()}
+}
+
+// observeTxn is a reti transaction observer, tracking what's spent by the manager account
+func (d *Daemon) observeTxn(result reti.TxnResult) {
+	d.funds.observeSpend(result, d.reti.Info().Config.Manager)
+}
+
+// observeSpend tracks what the manager spent in the transaction group
+func (m *managerFunds) observeSpend(result reti.TxnResult, manager string) {
+	if result.Err != nil {
+		return
+	}
+	spent := result.Spent[manager]
+	if spent == 0 {
+		return
+	}
+	promManagerSpent.Add(float64(spent))
+	m.Lock()
+	defer m.Unlock()
+	m.spends = append(m.spends, fundsEntry{time: time.Now(), amount: spent})
+}
+
+// spendRatePerDay returns the average microAlgos spent per day by the manager, and whether there's enough
+// history for it to be meaningful.
+func (m *managerFunds) spendRatePerDay(now time.Time) (float64, bool) {
+	m.Lock()
+	defer m.Unlock()
+	windowStart := now.Add(-spendRateWindow)
+	for len(m.spends) > 0 && m.spends[0].time.Before(windowStart) {
+		m.spends = m.spends[1:]
+	}
+	tracked := now.Sub(m.started)
+	if tracked < minSpendRateTracking {
+		return 0, false
+	}
+	tracked = min(tracked, spendRateWindow)
+	var total uint64
+	for _, spend := range m.spends {
+		total += spend.amount
+	}
+	return float64(total) / tracked.Hours() * 24, true
+}
+
+// toppedUpSince returns the amount topped up in the last 24 hours, and when the last top-up was
+func (m *managerFunds) toppedUpSince(now time.Time) (uint64, time.Time) {
+	m.Lock()
+	defer m.Unlock()
+	for len(m.topUps) > 0 && now.Sub(m.topUps[0].time) > 24*time.Hour {
+		m.topUps = m.topUps[1:]
+	}
+	var total uint64
+	for _, topUp := range m.topUps {
+		total += topUp.amount
+	}
+	return total, m.lastTopUp
+}
+
+// addTopUp tracks a top-up against the daily cap and minimum interval
+func (m *managerFunds) addTopUp(t time.Time, amount uint64) {
+	m.Lock()
+	defer m.Unlock()
+	m.topUps = append(m.topUps, fundsEntry{time: t, amount: amount})
+	if t.After(m.lastTopUp) {
+		m.lastTopUp = t
+	}
+}
+
+// topUpSent returns true if the top-up action may have moved funds - a payment that was sent but not confirmed
+// (ie: timed out waiting) could still have been applied, so it counts as well.
+func topUpSent(action history.Action) bool {
+	return action.Succeeded() || len(action.TxIDs) != 0
+}
+
+// loadTopUps seeds recent top-ups from the history database, so the daily cap and minimum interval still apply
+// across daemon restarts.
+func (m *managerFunds) loadTopUps(store *history.Store) error {
+	if m.topUp == nil {
+		return nil
+	}
+	if store == nil {
+		return fmt.Errorf("top-ups require the history database")
+	}
+	since := time.Now().Add(-max(24*time.Hour, m.topUp.MinInterval))
+	actions, err := store.Query(history.Query{Type: history.TypeManagerTopUp, Since: since})
+	if err != nil {
+		return err
+	}
+	// top-ups older than 24 hours only set lastTopUp - toppedUpSince drops them from the daily total
+	for _, action := range actions {
+		if topUpSent(action) {
+			m.addTopUp(action.Time, action.Amount)
+		}
+	}
+	return nil
+}
+
+// checkManagerFunds updates the manager balance/runway metrics, notifying if its balance is low and topping it up
+// from the treasury if configured.
+func (d *Daemon) checkManagerFunds(ctx context.Context) {
+	manager := d.reti.Info().Config.Manager
+	acctInfo, err := algo.GetBareAccount(ctx, d.chain, manager)
+	if err != nil {
+		misc.Warnf(d.logger, "unable to fetch manager account:%s, err:%v", manager, err)
+		return
+	}
+	var spendable uint64
+	if acctInfo.Amount > acctInfo.MinBalance {
+		spendable = acctInfo.Amount - acctInfo.MinBalance
+	}
+	promManagerSpendable.Set(float64(spendable))
+
+	now := time.Now()
+	var runway time.Duration
+	if ratePerDay, ok := d.funds.spendRatePerDay(now); ok {
+		promManagerSpendRate.Set(ratePerDay)
+		if ratePerDay > 0 {
+			runway = time.Duration(float64(spendable) / ratePerDay * float64(24*time.Hour))
+			promManagerRunway.Set(runway.Seconds())
+		}
+	}
+
+	if spendable < d.notifyMinBalance {
+		runwayStr := "unknown"
+		if runway != 0 {
+			runwayStr = runway.Round(time.Hour).String()
+		}
+		d.notify(events.TypeManagerBalanceLow, events.SeverityWarning, 0, manager,
+			"manager account:%s spendable balance is %s, below the threshold of %s, forecast runway:%s", manager,
+			algo.FormattedAlgoAmount(spendable), algo.FormattedAlgoAmount(d.notifyMinBalance), runwayStr)
+	}
+
+	if d.funds.topUp != nil && spendable < d.funds.topUp.Below {
+		d.topUpManager(ctx, manager, now)
+	}
+}
+
+// topUpManager funds the manager account from the treasury, subject to the daily cap and minimum interval
+func (d *Daemon) topUpManager(ctx context.Context, manager string, now time.Time) {
+	config := d.funds.topUp
+	toppedUp, lastTopUp := d.funds.toppedUpSince(now)
+	if !lastTopUp.IsZero() && now.Sub(lastTopUp) < config.MinInterval {
+		misc.Debugf(d.logger, "manager top-up needed, but last top-up was only %v ago", now.Sub(lastTopUp).Round(time.Second))
+		return
+	}
+	amount := config.Amount
+	if toppedUp+amount > config.DailyCap {
+		if toppedUp >= config.DailyCap {
+			misc.Warnf(d.logger, "manager top-up needed, but daily cap of %s already reached", algo.FormattedAlgoAmount(config.DailyCap))
+			d.notify(events.TypeManagerBalanceLow, events.SeverityCritical, 0, "topup-cap",
+				"manager account:%s needs funding but the daily top-up cap of %s has been reached", manager,
+				algo.FormattedAlgoAmount(config.DailyCap))
+			return
+		}
+		amount = config.DailyCap - toppedUp
+	}
+
+	if d.reti.DryRun() {
+		misc.Infof(d.logger, "[DRY-RUN] would have topped up manager account:%s with %s from treasury:%s", manager, algo.FormattedAlgoAmount(amount), config.Treasury)
+		return
+	}
+	misc.Infof(d.logger, "topping up manager account:%s with %s from treasury:%s", manager, algo.FormattedAlgoAmount(amount), config.Treasury)
+	txid, round, err := algo.SendPayment(ctx, d.logger, d.chain, d.signer, config.Treasury, manager, amount, "réti manager top-up")
+	action := history.Action{
+		Type:   history.TypeManagerTopUp,
+		Round:  round,
+		Fees:   transaction.MinTxnFee,
+		Amount: amount,
+		Detail: fmt.Sprintf("%s ALGO from:%s", algo.FormattedAlgoAmount(amount), config.Treasury),
+	}
+	if txid != "" {
+		action.TxIDs = []string{txid}
+	}
+	if err != nil {
+		action.Error = err.Error()
+		d.recordHistory(action)
+		if txid != "" {
+			// it was sent, so it may still be applied - count it so a retry can't exceed the cap
+			d.funds.addTopUp(now, amount)
+		}
+		misc.Errorf(d.logger, "unable to top-up manager account from treasury:%s, err:%v", config.Treasury, err)
+		d.notify(events.TypeManagerBalanceLow, events.SeverityCritical, 0, "topup-failed",
+			"unable to fund manager account:%s from treasury:%s, err:%v", manager, config.Treasury, err)
+		return
+	}
+	d.recordHistory(action)
+	d.funds.addTopUp(now, amount)
+	promManagerTopUps.Inc()
+	promManagerTopUpAmount.Add(float64(amount))
+	d.notify(events.TypeManagerToppedUp, events.SeverityInfo, 0, "",
+		"funded manager account:%s with %s from treasury:%s, txid:%s", manager, algo.FormattedAlgoAmount(amount), config.Treasury, txid)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TxnLab/reti/internal/lib/history"
+)
+
+func TestLoadTopUps(t *testing.T) {
+	store, err := history.NewStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for _, action := range []history.Action{
+		{Time: now.Add(-72 * time.Hour), Amount: 1e6, TxIDs: []string{"old"}},
+		{Time: now.Add(-30 * time.Hour), Amount: 2e6, TxIDs: []string{"outside-day"}},
+		{Time: now.Add(-3 * time.Hour), Amount: 4e6, TxIDs: []string{"sent"}, Error: "timed out waiting for confirmation"},
+		{Time: now.Add(-2 * time.Hour), Amount: 8e6, Error: "never sent"},
+		{Time: now.Add(-1 * time.Hour), Amount: 16e6, TxIDs: []string{"confirmed"}},
+	} {
+		action.Type = history.TypeManagerTopUp
+		if err = store.Record(action); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("unconfirmed top-ups count toward the daily cap", func(t *testing.T) {
+		funds := newManagerFunds(&TopUpConfig{MinInterval: time.Hour})
+		if err = funds.loadTopUps(store); err != nil {
+			t.Fatal(err)
+		}
+		total, last := funds.toppedUpSince(now)
+		if total != 20e6 {
+			t.Errorf("topped up %d in the last day, want %d", total, uint64(20e6))
+		}
+		if !last.Equal(now.Add(-time.Hour)) {
+			t.Errorf("last top-up at %v, want %v", last, now.Add(-time.Hour))
+		}
+	})
+
+	t.Run("top-ups require the history database", func(t *testing.T) {
+		if err := newManagerFunds(&TopUpConfig{}).loadTopUps(nil); err == nil {
+			t.Error("loaded top-ups w/out a history database")
+		}
+	})
+
+	t.Run("last top-up is kept beyond 24 hours", func(t *testing.T) {
+		older, err := history.NewStore(filepath.Join(t.TempDir(), "history.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = older.Record(history.Action{Type: history.TypeManagerTopUp, Time: now.Add(-30 * time.Hour), Amount: 2e6}); err != nil {
+			t.Fatal(err)
+		}
+		funds := newManagerFunds(&TopUpConfig{MinInterval: 48 * time.Hour})
+		if err = funds.loadTopUps(older); err != nil {
+			t.Fatal(err)
+		}
+		total, last := funds.toppedUpSince(now)
+		if total != 0 {
+			t.Errorf("topped up %d in the last day, want 0", total)
+		}
+		if !last.Equal(now.Add(-30 * time.Hour)) {
+			t.Errorf("last top-up at %v, want %v", last, now.Add(-30*time.Hour))
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/events"
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+const (
+	DefaultNotifyKeyExpiry  = 12 * time.Hour
+	DefaultNotifyMinBalance = 1.0 // in ALGO
+)
+
+// newEventBus returns the event bus w/ all the notification sinks configured in the daemon command flags.
+// Returns nil if no sinks are configured.
+func newEventBus(cmd *cli.Command) (*events.Bus, error) {
+	severities, err := parseNotifySeverities(cmd.String("notifyseverity"))
+	if err != nil {
+		return nil, err
+	}
+	var sinks []events.Sink
+	for _, format := range []events.WebhookFormat{events.FormatJSON, events.FormatSlack, events.FormatDiscord} {
+		if url := cmd.String("notify" + string(format)); url != "" {
+			sinks = append(sinks, events.NewWebhookSink(url, format))
+		}
+	}
+	if addr := cmd.String("notifysmtp"); addr != "" {
+		smtpSink := &events.SMTPSink{
+			Addr:     addr,
+			Username: cmd.String("notifysmtpuser"),
+			Password: misc.GetSecret("RETI_NOTIFY_SMTP_PASSWORD"),
+			From:     cmd.String("notifysmtpfrom"),
+		}
+		for _, to := range strings.Split(cmd.String("notifysmtpto"), ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				smtpSink.To = append(smtpSink.To, to)
+			}
+		}
+		if smtpSink.From == "" || len(smtpSink.To) == 0 {
+			return nil, fmt.Errorf("email notifications require both from and to addresses")
+		}
+		sinks = append(sinks, smtpSink)
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+
+	bus := events.NewBus(App.logger)
+	for _, sink := range sinks {
+		severity, found := severities[sink.Name()]
+		if !found {
+			severity = severities[""]
+		}
+		misc.Infof(App.logger, "sending %s (and above) notifications to %s", severity, sink.Name())
+		bus.AddSink(sink, severity, cmd.Duration("notifydedup"))
+	}
+	return bus, nil
+}
+
+// parseNotifySeverities parses the minimum notification severity - either a single severity for all sinks, or a
+// comma separated list with per-sink overrides, ie: warning,email=critical.  The default is keyed by "".
+func parseNotifySeverities(value string) (map[string]events.Severity, error) {
+	severities := map[string]events.Severity{"": events.SeverityWarning}
+	for _, setting := range strings.Split(value, ",") {
+		if strings.TrimSpace(setting) == "" {
+			continue
+		}
+		var sinkName string
+		if name, severityStr, found := strings.Cut(setting, "="); found {
+			sinkName, setting = strings.ToLower(strings.TrimSpace(name)), severityStr
+		}
+		severity, err := events.ParseSeverity(setting)
+		if err != nil {
+			return nil, err
+		}
+		severities[sinkName] = severity
+	}
+	return severities, nil
+}
+
+// notify publishes an event for this validator/node - does nothing if notifications aren't configured
+func (d *Daemon) notify(eventType events.Type, severity events.Severity, poolId uint64, key string, format string, args ...any) {
+	d.events.Publish(events.Event{
+		Type:        eventType,
+		Severity:    severity,
+		ValidatorId: d.reti.ValidatorId,
+		NodeNum:     d.reti.NodeNum,
+		PoolId:      poolId,
+		Key:         key,
+		Message:     fmt.Sprintf(format, args...),
+	})
+}
+
+// checkPoolsStillOnline notifies about any pools which were online the last time we checked, but now aren't - unless
+// we took them offline ourselves.
+func (d *Daemon) checkPoolsStillOnline(poolAccounts map[string]onlineInfo) {
+	for account, info := range poolAccounts {
+		if d.poolsOnline[info.poolId] && !info.isOnline {
+			if d.takenOffline[info.poolId] {
+				misc.Infof(d.logger, "pool app id:%d, account:%s is offline (taken offline as its key was missing)", info.poolAppId, account)
+			} else {
+				d.notify(events.TypePoolOffline, events.SeverityCritical, info.poolId, "",
+					"pool app id:%d, account:%s is no longer online", info.poolAppId, account)
+			}
+		}
+		d.poolsOnline[info.poolId] = info.isOnline
+		delete(d.takenOffline, info.poolId)
+	}
+}
+
+// checkKeyExpiry notifies about online pools whose participation key expires within the notification threshold
+// with no replacement key present.
+func (d *Daemon) checkKeyExpiry(poolAccounts map[string]onlineInfo, partKeys algo.PartKeysByAddress) {
+	curRound, avgBlockTime := d.blocks.LastRound(), d.AverageBlockTime()
+	if avgBlockTime == 0 {
+		return
+	}
+	for account, info := range poolAccounts {
+		if !info.isOnline {
+			continue
+		}
+		var activeKey *algo.ParticipationKey
+		for i, key := range partKeys[account] {
+			if bytes.Equal(key.Key.SelectionParticipationKey, info.selectionParticipationKey) {
+				activeKey = &partKeys[account][i]
+				break
+			}
+		}
+		if activeKey == nil || activeKey.Key.VoteLastValid < curRound {
+			continue
+		}
+		var hasReplacement bool
+		for _, key := range partKeys[account] {
+			if key.Key.VoteLastValid > activeKey.Key.VoteLastValid {
+				hasReplacement = true
+			}
+		}
+		expiresIn := time.Duration(activeKey.Key.VoteLastValid-curRound) * avgBlockTime
+		if !hasReplacement && expiresIn <= d.notifyKeyExpiry {
+			d.notify(events.TypeKeyExpiring, events.SeverityWarning, info.poolId, activeKey.Id,
+				"participation key:%s for account:%s expires in ~%v (round %d) and no replacement key exists",
+				activeKey.Id, account, expiresIn.Round(time.Minute), activeKey.Key.VoteLastValid)
+		}
+	}
+}
@@ -3,9 +3,11 @@ package main
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
+	"slices"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -17,6 +19,8 @@ import (
 
 	"github.com/TxnLab/reti/internal/lib/algo"
 	"github.com/TxnLab/reti/internal/lib/misc"
+	"github.com/TxnLab/reti/internal/lib/nfdonchain"
+	"github.com/TxnLab/reti/internal/lib/reti"
 )
 
 func GetPoolCmdOpts() *cli.Command {
@@ -118,6 +122,17 @@ func GetPoolCmdOpts() *cli.Command {
 				},
 				Action: OfflinePool,
 			},
+			{
+				Name:  "link-nfd",
+				Usage: "Link pools to the validator's NFD (as verified addresses of the NFD), if not already.  The NFD owner's keys must be present",
+				Flags: []cli.Flag{
+					&cli.UintFlag{
+						Name:  "pool",
+						Usage: "Pool id (the number in 'pool list') - all pools of the validator are linked if not specified",
+					},
+				},
+				Action: LinkPoolsNFD,
+			},
 		},
 	}
 }
@@ -139,10 +154,10 @@ func PoolsList(ctx context.Context, command *cli.Command) error {
 	}
 
 	// we just want the latest round so we can show last vote/proposal relative to current round
-	status, err := App.algoClient.Status().Do(ctx)
+	status, err := App.chain.Status(ctx)
 
 	if !offlineAlgod {
-		partKeys, err = algo.GetParticipationKeys(ctx, App.algoClient)
+		partKeys, err = algo.GetParticipationKeys(ctx, App.chain)
 		if err != nil {
 			return err
 		}
@@ -158,6 +173,15 @@ func PoolsList(ctx context.Context, command *cli.Command) error {
 		return 0, 0
 	}
 
+	poolAppIDs := make([]uint64, len(info.Pools))
+	for i, pool := range info.Pools {
+		poolAppIDs[i] = pool.PoolAppId
+	}
+	snapshots, err := App.retiClient.GetPoolSnapshots(ctx, poolAppIDs)
+	if err != nil {
+		return err
+	}
+
 	out := new(strings.Builder)
 	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
 	fmt.Fprintln(tw, "Viewing pools for our Node:", App.retiClient.NodeNum)
@@ -192,19 +216,15 @@ func PoolsList(ctx context.Context, command *cli.Command) error {
 		} else {
 			nodeStr = strconv.Itoa(nodeNum)
 		}
-		acctInfo, err := algo.GetBareAccount(context.Background(), App.algoClient, crypto.GetApplicationAddress(pool.PoolAppId).String())
-		if err != nil {
-			return fmt.Errorf("account fetch error, account:%s, err:%w", crypto.GetApplicationAddress(pool.PoolAppId).String(), err)
-		}
-		if acctInfo.Status == OnlineStatus {
+		snapshot := snapshots[i]
+		if snapshot.Account.Status == OnlineStatus {
 			onlineStr = "O"
 		}
 
-		rewardAvail := App.retiClient.PoolAvailableRewards(pool.PoolAppId, pool.TotalAlgoStaked)
-		apr, _ := App.retiClient.GetAvgApr(pool.PoolAppId)
+		rewardAvail := snapshot.AvailableRewards()
 		totalRewards += rewardAvail
 
-		lastVote, lastProposal := getParticipationData(crypto.GetApplicationAddress(pool.PoolAppId).String(), acctInfo.Participation.SelectionParticipationKey)
+		lastVote, lastProposal := getParticipationData(crypto.GetApplicationAddress(pool.PoolAppId).String(), snapshot.Account.Participation.SelectionParticipationKey)
 		var (
 			voteData string
 			partData string
@@ -224,8 +244,7 @@ func PoolsList(ctx context.Context, command *cli.Command) error {
 				partData = fmt.Sprintf("-%d", status.LastRound-lastProposal)
 			}
 		}
-		floatApr, _, _ := new(big.Float).Parse(apr.String(), 10)
-		floatApr.Quo(floatApr, big.NewFloat(10000.0))
+		floatApr := snapshot.APR()
 
 		if !showAll {
 			fmt.Fprintf(tw, "%d %s\t%d\t%d\t%s\t%s\t%s\t%s\t%s\t\n", i+1, onlineStr, pool.PoolAppId, pool.TotalStakers,
@@ -275,9 +294,13 @@ func PoolLedger(ctx context.Context, command *cli.Command) error {
 	if poolId > len(pools) {
 		return fmt.Errorf("pool with id %d does not exist. See the pool list -all output for list", poolId)
 	}
-	params, _ := App.algoClient.SuggestedParams().Do(ctx)
+	params, _ := App.chain.SuggestedParams(ctx)
 
-	lastPayout, err := App.retiClient.GetLastPayout(pools[poolId-1].PoolAppId)
+	snapshot, err := App.retiClient.GetPoolSnapshot(ctx, pools[poolId-1].PoolAppId)
+	if err != nil {
+		return err
+	}
+	lastPayout := snapshot.LastPayout
 	nextEpoch := lastPayout - (lastPayout % uint64(config.EpochRoundLength)) + uint64(config.EpochRoundLength)
 	adjustedEpoch := nextEpoch
 	if adjustedEpoch < uint64(params.FirstRoundValid) {
@@ -305,8 +328,6 @@ func PoolLedger(ctx context.Context, command *cli.Command) error {
 		return fmt.Errorf("unable to GetLedgerForPool: %w", err)
 	}
 
-	rewardAvail := App.retiClient.PoolAvailableRewards(pools[poolId-1].PoolAppId, pools[poolId-1].TotalAlgoStaked)
-
 	out := new(strings.Builder)
 	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
 	fmt.Fprintln(tw, "Account\tStaked\tTotal Rewarded\tRwd Tokens\tPct\tEntry Round\t")
@@ -327,15 +348,11 @@ func PoolLedger(ctx context.Context, command *cli.Command) error {
 		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t\n", stakerName, algo.FormattedAlgoAmount(stakerData.Balance), algo.FormattedAlgoAmount(stakerData.TotalRewarded),
 			stakerData.RewardTokenBalance, pctTimeInEpoch(stakerData.EntryRound), stakerData.EntryRound)
 	}
-	apr, _ := App.retiClient.GetAvgApr(pools[poolId-1].PoolAppId)
-	fmt.Fprintf(tw, "Reward Avail: %s\t\n", algo.FormattedAlgoAmount(rewardAvail))
-	stakeAccum, _ := App.retiClient.GetStakeAccum(pools[poolId-1].PoolAppId)
-	stakeAccum.Div(stakeAccum, big.NewInt(30857))
+	fmt.Fprintf(tw, "Reward Avail: %s\t\n", algo.FormattedAlgoAmount(snapshot.AvailableRewards()))
+	stakeAccum := new(big.Int).Div(snapshot.StakeAccum, big.NewInt(30857))
 	stakeAccum.Div(stakeAccum, big.NewInt(1e6))
 	fmt.Fprintf(tw, "Avg Stake: %s\t\n", stakeAccum.String())
-	floatApr, _, _ := new(big.Float).Parse(apr.String(), 10)
-	floatApr.Quo(floatApr, big.NewFloat(10000.0))
-	fmt.Fprintf(tw, "APR %%: %s\t\n", floatApr.String())
+	fmt.Fprintf(tw, "APR %%: %s\t\n", snapshot.APR().String())
 	fmt.Fprintf(tw, "Last Epoch Start: %d\t\n", lastPayout-(lastPayout%uint64(config.EpochRoundLength)))
 	fmt.Fprintf(tw, "Last Payout: %d\t\n", lastPayout-(lastPayout%uint64(config.EpochRoundLength)))
 	fmt.Fprintf(tw, "Current round: %d\t\n", params.FirstRoundValid)
@@ -343,7 +360,7 @@ func PoolLedger(ctx context.Context, command *cli.Command) error {
 	if adjustedEpoch != nextEpoch {
 		fmt.Fprintf(tw, "Next possible payout: %d\t\n", adjustedEpoch)
 	}
-	blockTime, _ := algo.CalcBlockTimes(ctx, App.algoClient, 10)
+	blockTime, _ := algo.CalcBlockTimes(ctx, App.chain, 10)
 	fmt.Fprintf(tw, "in approx: %s\t\n", (time.Duration(adjustedEpoch-uint64(params.FirstRoundValid)) * blockTime).Round(time.Second))
 	if nextEpoch < uint64(params.FirstRoundValid) {
 		fmt.Fprintf(tw, "Missed payout by: %d\t\n", uint64(params.FirstRoundValid)-nextEpoch)
@@ -429,3 +446,74 @@ func OfflinePool(ctx context.Context, command *cli.Command) error {
 	}
 	return err
 }
+
+func LinkPoolsNFD(ctx context.Context, command *cli.Command) error {
+	var (
+		info       = App.retiClient.Info()
+		poolAppIDs []uint64
+	)
+	if command.IsSet("pool") {
+		poolID := command.Uint("pool")
+		if poolID == 0 || int(poolID) > len(info.Pools) {
+			return fmt.Errorf("pool num:%d not valid for pool id", poolID)
+		}
+		poolAppIDs = append(poolAppIDs, info.Pools[poolID-1].PoolAppId)
+	} else {
+		for _, pool := range info.Pools {
+			poolAppIDs = append(poolAppIDs, pool.PoolAppId)
+		}
+	}
+	linked, err := linkPoolsToNFD(ctx, App.retiClient, App.nfdOnChain, App.signer, poolAppIDs)
+	if err != nil {
+		return err
+	}
+	if linked == 0 {
+		misc.Infof(App.logger, "all pools are already linked to the validator's NFD")
+	}
+	return nil
+}
+
+// errCantLinkNFD is returned by linkPoolsToNFD when pools can't be linked until the validator's configuration (or
+// keys) change - as opposed to a transient failure.
+var errCantLinkNFD = errors.New("pools can't be linked to the validator's nfd")
+
+// linkPoolsToNFD links each of the pools (by app id) which isn't already a verified address of the validator's
+// NFD, returning how many were linked.
+func linkPoolsToNFD(ctx context.Context, retiClient *reti.Reti, nfdOnChain *nfdonchain.NfdApi, signer algo.MultipleWalletSigner, poolAppIDs []uint64) (int, error) {
+	info := retiClient.Info()
+	if info.Config.NFDForInfo == 0 {
+		return 0, fmt.Errorf("%w: validator has no NFD to link pools to - set with 'validator change nfd'", errCantLinkNFD)
+	}
+	nfd, err := nfdOnChain.GetNFD(ctx, info.Config.NFDForInfo, true)
+	if err != nil {
+		return 0, fmt.Errorf("unable to fetch nfd app id:%d, err:%w", info.Config.NFDForInfo, err)
+	}
+	nfdName := nfd.Internal["name"]
+	nfdOwner, err := types.DecodeAddress(nfd.Internal["owner"])
+	if err != nil {
+		return 0, fmt.Errorf("%w: nfd:%s has no owner, err:%v", errCantLinkNFD, nfdName, err)
+	}
+	if !signer.HasAccount(nfdOwner.String()) {
+		return 0, fmt.Errorf("%w: keys for the owner:%s of nfd:%s aren't present", errCantLinkNFD, nfdOwner, nfdName)
+	}
+	callerAddr, err := signer.FindFirstSigner([]string{info.Config.Owner, info.Config.Manager})
+	if err != nil {
+		return 0, fmt.Errorf("%w: neither owner or manager address for your validator has local keys present", errCantLinkNFD)
+	}
+	caller, _ := types.DecodeAddress(callerAddr)
+
+	verified := strings.Split(nfd.Verified["caAlgo"], ",")
+	var linked int
+	for _, poolAppID := range poolAppIDs {
+		poolAddress := crypto.GetApplicationAddress(poolAppID).String()
+		if slices.Contains(verified, poolAddress) {
+			continue
+		}
+		if err = retiClient.LinkPoolToNFD(poolAppID, info.Config.NFDForInfo, nfdName, nfdOwner, caller); err != nil {
+			return linked, fmt.Errorf("unable to link pool app id:%d to nfd:%s, err:%w", poolAppID, nfdName, err)
+		}
+		misc.Infof(retiClient.Logger, "linked pool app id:%d (%s) to nfd:%s", poolAppID, poolAddress, nfdName)
+		linked++
+	}
+	return linked, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/urfave/cli/v3"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+func GetRewardsCmdOpts() *cli.Command {
+	return &cli.Command{
+		Name:   "rewards",
+		Usage:  "View/manage the reward token payouts of the validator (for validators with a reward token)",
+		Before: checkConfigured,
+		Commands: []*cli.Command{
+			{
+				Name:   "status",
+				Usage:  "Display the per-pool token payout ratio, reward tokens held in pool 1 vs what's owed to stakers, and the epochs of runway left",
+				Action: RewardsStatus,
+			},
+			{
+				Name: "refresh",
+				Usage: "Refresh the token payout ratio now, rather than waiting for the daemon's next epoch update.  The ratio can only be " +
+					"updated by pool 1, so this triggers pool 1's epoch update (payout), which the manager's keys must be present for",
+				Action: RewardsRefresh,
+			},
+		},
+	}
+}
+
+func RewardsStatus(ctx context.Context, command *cli.Command) error {
+	info := App.retiClient.Info()
+	if info.Config.RewardTokenId == 0 {
+		return errors.New("validator doesn't have a reward token")
+	}
+	if len(info.Pools) == 0 {
+		return errors.New("validator has no pools")
+	}
+	token, err := App.algoClient.GetAssetByID(info.Config.RewardTokenId).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to fetch reward token:%d, err:%w", info.Config.RewardTokenId, err)
+	}
+	formatToken := func(amount uint64) string {
+		return formattedTokenAmount(amount, token.Params.Decimals, token.Params.UnitName)
+	}
+	ratio, err := App.retiClient.GetTokenPayoutRatio(info.Config.ID)
+	if err != nil {
+		return err
+	}
+	state, err := App.retiClient.GetValidatorState(info.Config.ID)
+	if err != nil {
+		return err
+	}
+	// the reward tokens are all held in pool 1 - and paid out from there
+	pool1Address := crypto.GetApplicationAddress(info.Pools[0].PoolAppId).String()
+	holding, err := App.algoClient.AccountAssetInformation(pool1Address, info.Config.RewardTokenId).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to fetch reward token holding of pool 1, err:%w", err)
+	}
+	held := holding.AssetHolding.Amount
+
+	out := new(strings.Builder)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Pool (App id)\tStaked\tPayout Ratio\tOwed Tokens\t")
+	var totalOwed uint64
+	for i, pool := range info.Pools {
+		ledger, err := App.retiClient.GetLedgerForPool(pool.PoolAppId)
+		if err != nil {
+			return fmt.Errorf("unable to fetch ledger for pool app id:%d, err:%w", pool.PoolAppId, err)
+		}
+		var owed uint64
+		for _, stakerData := range ledger {
+			owed += stakerData.RewardTokenBalance
+		}
+		totalOwed += owed
+		fmt.Fprintf(tw, "%d (%d)\t%s\t%s%%\t%s\t\n", i+1, pool.PoolAppId, algo.FormattedAlgoAmount(pool.TotalAlgoStaked),
+			strconv.FormatFloat(float64(ratio.PoolPctOfWhole[i])/1e4, 'f', 4, 64), formatToken(owed))
+	}
+	fmt.Fprintf(tw, "TOTAL\t%s\t\t%s\t\n", algo.FormattedAlgoAmount(state.TotalAlgoStaked), formatToken(totalOwed))
+	tw.Flush()
+
+	fmt.Fprintf(out, "\nRatio updated for payout at round: %d\n", ratio.UpdatedForPayout)
+	fmt.Fprintf(out, "Reward token: %s (%d)\n", token.Params.Name, info.Config.RewardTokenId)
+	fmt.Fprintf(out, "Reward per payout: %s\n", formatToken(info.Config.RewardPerPayout))
+	fmt.Fprintf(out, "Held in pool 1: %s\n", formatToken(held))
+	fmt.Fprintf(out, "Held back for stakers: %s (ledgers owe: %s)\n", formatToken(state.RewardTokenHeldBack), formatToken(totalOwed))
+	if state.RewardTokenHeldBack != totalOwed {
+		fmt.Fprintf(out, "WARNING: the amount held back doesn't match what the ledgers owe\n")
+	}
+	var available uint64
+	if held > state.RewardTokenHeldBack {
+		available = held - state.RewardTokenHeldBack
+	}
+	fmt.Fprintf(out, "Available for payouts: %s\n", formatToken(available))
+	if info.Config.RewardPerPayout != 0 {
+		fmt.Fprintf(out, "Runway: %d epochs (of %d rounds)\n", available/info.Config.RewardPerPayout, info.Config.EpochRoundLength)
+	}
+	fmt.Print(out.String())
+	return nil
+}
+
+func RewardsRefresh(ctx context.Context, command *cli.Command) error {
+	info := App.retiClient.Info()
+	if info.Config.RewardTokenId == 0 {
+		return errors.New("validator doesn't have a reward token")
+	}
+	if len(info.Pools) == 0 {
+		return errors.New("validator has no pools")
+	}
+	if !App.signer.HasAccount(info.Config.Manager) {
+		return fmt.Errorf("keys for the manager:%s aren't present", info.Config.Manager)
+	}
+	// setTokenPayoutRatio only updates the ratio when called by pool 1 (and proxiedSetTokenPayoutRatio only when called
+	// by the other pools), which happens as part of pool 1's epoch update - once per epoch.
+	pool1AppID := info.Pools[0].PoolAppId
+	snapshot, err := App.retiClient.GetPoolSnapshot(ctx, pool1AppID)
+	if err != nil {
+		return err
+	}
+	status, err := App.algoClient.Status().Do(ctx)
+	if err != nil {
+		return err
+	}
+	epochRoundLength := uint64(info.Config.EpochRoundLength)
+	if snapshot.LastPayout != 0 && snapshot.LastPayout-snapshot.LastPayout%epochRoundLength == status.LastRound-status.LastRound%epochRoundLength {
+		ratio, err := App.retiClient.GetTokenPayoutRatio(info.Config.ID)
+		if err != nil {
+			return err
+		}
+		misc.Infof(App.logger, "pool 1 already paid out this epoch (at round:%d) - the ratio (updated for payout at round:%d) can't be refreshed until round:%d",
+			snapshot.LastPayout, ratio.UpdatedForPayout, nextEpoch(status.LastRound, epochRoundLength))
+		return nil
+	}
+	managerAddr, _ := types.DecodeAddress(info.Config.Manager)
+	if err = App.retiClient.EpochBalanceUpdate(1, pool1AppID, managerAddr); err != nil {
+		return fmt.Errorf("epoch update of pool 1 failed, err:%w", err)
+	}
+	ratio, err := App.retiClient.GetTokenPayoutRatio(info.Config.ID)
+	if err != nil {
+		return err
+	}
+	misc.Infof(App.logger, "token payout ratio refreshed for payout at round:%d", ratio.UpdatedForPayout)
+	return nil
+}
+
+// formattedTokenAmount returns a (base unit) asset amount formatted with the asset's decimals and unit name
+func formattedTokenAmount(amount uint64, decimals uint64, unitName string) string {
+	str := strconv.FormatUint(amount, 10)
+	if decimals > 0 {
+		if uint64(len(str)) <= decimals {
+			str = strings.Repeat("0", int(decimals)-len(str)+1) + str
+		}
+		str = str[:uint64(len(str))-decimals] + "." + str[uint64(len(str))-decimals:]
+	}
+	if unitName == "" {
+		return str
+	}
+	return str + " " + unitName
+}
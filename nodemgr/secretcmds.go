@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/urfave/cli/v3"
+
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+func GetSecretsCmdOpts() *cli.Command {
+	return &cli.Command{
+		Name:     "secrets",
+		Usage:    "Manage encrypted secrets files and show where secrets come from",
+		Metadata: standaloneCommand,
+		Commands: []*cli.Command{
+			{
+				Name:   "encrypt",
+				Usage:  "Encrypt the secrets in a .env file into an encrypted secrets file (see --secretsfile)",
+				Action: SecretsEncrypt,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "in",
+						Usage:    ".env file of the secrets to encrypt",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "out",
+						Usage:    "Encrypted secrets file to write",
+						Required: true,
+					},
+				},
+			},
+			{
+				Name:   "list",
+				Usage:  "List the secrets fetched from the secret providers and which provider each came from (values aren't shown)",
+				Action: SecretsList,
+			},
+		},
+	}
+}
+
+func SecretsEncrypt(ctx context.Context, command *cli.Command) error {
+	secrets, err := godotenv.Read(command.String("in"))
+	if err != nil {
+		return err
+	}
+	passphrase, err := readPassphrase(command, "secretspassfile", "secrets file", true)
+	if err != nil {
+		return err
+	}
+	defer clear(passphrase)
+	if err = misc.WriteEncryptedSecrets(command.String("out"), passphrase, secrets); err != nil {
+		return err
+	}
+	fmt.Printf("Encrypted %d secrets to %s\n", len(secrets), command.String("out"))
+	return nil
+}
+
+func SecretsList(ctx context.Context, command *cli.Command) error {
+	secrets := misc.Secrets()
+	var keys []string
+	for key := range secrets {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		source := secrets[key].Provider
+		if envSource := misc.SecretSource(key); envSource != source {
+			source = fmt.Sprintf("%s (overridden by %s)", source, envSource)
+		}
+		fmt.Printf("%s: %s, loaded %s\n", key, source, secrets[key].Loaded.Format(time.RFC3339))
+	}
+	if len(keys) == 0 {
+		fmt.Println("No secrets fetched - secrets are only taken from environment variables")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/misc"
+	"github.com/TxnLab/reti/internal/lib/remotesigner"
+)
+
+func GetSignerCmdOpts() *cli.Command {
+	return &cli.Command{
+		Name:     "signer",
+		Usage:    "Remote signing service, so keys can be kept off the node host",
+		Metadata: standaloneCommand,
+		Commands: []*cli.Command{
+			{
+				Name:   "serve",
+				Usage:  "Run the (mutual tls) signing service, signing with the keys of the --signer/--keystore set for this command - only what the --signingpolicy allows",
+				Action: SignerServe,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "listen",
+						Usage: "Address to listen on",
+						Value: ":6270",
+					},
+					&cli.StringFlag{
+						Name:     "cert",
+						Usage:    "Server certificate file",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "key",
+						Usage:    "Server certificate key file",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "clientca",
+						Usage:    "CA certificate file that client certificates must be signed by",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "accounts",
+						Usage:    "Comma separated list of the accounts to sign for",
+						Required: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func SignerServe(ctx context.Context, command *cli.Command) error {
+	if command.String("signer") == "remote" {
+		return errors.New("the signing service can't itself use a remote signer")
+	}
+	policyFile := command.String("signingpolicy")
+	if policyFile == "" {
+		return errors.New("the signing service requires a --signingpolicy restricting what it signs")
+	}
+	policy, err := algo.LoadSigningPolicy(policyFile)
+	if err != nil {
+		return err
+	}
+	// the policy's daily limits are kept in the history database, so they still apply after a restart
+	spends, err := getHistoryStore(command.String("history"))
+	if err != nil {
+		return err
+	}
+	if spends == nil {
+		return errors.New("the signing service requires --history, where the signing policy's daily totals are kept")
+	}
+
+	// client initialization is skipped for standalone commands, so we set up our own signer - and algod client
+	// (the policy may allow the apps created by an app)
+	network := command.String("network")
+	misc.LoadEnvForNetwork(App.logger, network)
+	cfg := algo.GetNetworkConfig(network)
+	algoClient, err := algo.GetAlgoClient(App.logger, cfg)
+	if err != nil {
+		return err
+	}
+	if err = App.initSigner(ctx, command, cfg); err != nil {
+		return err
+	}
+	server, err := remotesigner.NewServer(App.logger, App.signer, splitList(command.String("accounts")), policy, algo.NewAlgodChain(algoClient), spends)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return server.ServeTLS(ctx, command.String("listen"), command.String("cert"), command.String("key"), command.String("clientca"))
+}
+
+// splitList splits a comma separated list, ignoring empty entries
+func splitList(list string) []string {
+	var values []string
+	for _, value := range strings.Split(list, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
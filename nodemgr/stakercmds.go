@@ -0,0 +1,269 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/urfave/cli/v3"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/misc"
+	"github.com/TxnLab/reti/internal/lib/reti"
+)
+
+func GetStakerCmdOpts() *cli.Command {
+	return &cli.Command{
+		Name:  "staker",
+		Usage: "Stake to validators (and manage that stake) as a staker, using the staker's own keys",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "account",
+				Usage:    "The staker account - its keys must be available",
+				Sources:  cli.EnvVars("RETI_STAKER"),
+				Required: true,
+			},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:   "add",
+				Usage:  "Add stake to a validator - it's added to the first pool of the validator with room",
+				Action: StakerAdd,
+				Flags: []cli.Flag{
+					&cli.UintFlag{
+						Name:     "validator",
+						Usage:    "The id of the validator to stake to",
+						Required: true,
+					},
+					&cli.FloatFlag{
+						Name:     "amount",
+						Usage:    "The amount of ALGO to stake (the MBR of a first-time staker is added to this)",
+						Required: true,
+					},
+					&cli.UintFlag{
+						Name:  "gating-asset",
+						Usage: "For validators gating entry, the asset (or NFD app id) held by the staker which qualifies them",
+					},
+				},
+			},
+			{
+				Name:   "remove",
+				Usage:  "Remove stake from a pool - any reward tokens are also paid out",
+				Action: StakerRemove,
+				Flags: []cli.Flag{
+					&cli.UintFlag{
+						Name:     "pool",
+						Usage:    "The app id of the staking pool to remove stake from",
+						Required: true,
+					},
+					&cli.FloatFlag{
+						Name:  "amount",
+						Usage: "The amount of ALGO to remove.  If 0 (or not specified), all stake is removed",
+					},
+				},
+			},
+			{
+				Name:   "claim-tokens",
+				Usage:  "Claim the reward tokens accrued in the staker's pools",
+				Action: StakerClaimTokens,
+				Flags: []cli.Flag{
+					&cli.UintFlag{
+						Name:  "pool",
+						Usage: "The app id of the staking pool to claim from - all pools with a token balance if not specified",
+					},
+				},
+			},
+			{
+				Name:   "info",
+				Usage:  "Display the staker's balance, rewards, and reward tokens in every pool they're staked in",
+				Action: StakerInfo,
+			},
+		},
+	}
+}
+
+// stakerAccount returns the staker account specified for the staker commands - which has to be one we have keys
+// for, since the staker signs for everything.
+func stakerAccount(command *cli.Command) (types.Address, error) {
+	staker, err := types.DecodeAddress(command.String("account"))
+	if err != nil {
+		return types.Address{}, fmt.Errorf("invalid staker account:%s, err:%w", command.String("account"), err)
+	}
+	if !App.signer.HasAccount(staker.String()) {
+		return types.Address{}, fmt.Errorf("the keys for staker account:%s aren't available", staker)
+	}
+	return staker, nil
+}
+
+func StakerAdd(ctx context.Context, command *cli.Command) error {
+	staker, err := stakerAccount(command)
+	if err != nil {
+		return err
+	}
+	var (
+		validatorID = command.Uint("validator")
+		amount      = algo.AlgosToMicroAlgos(command.Float("amount"))
+		gatingAsset = command.Uint("gating-asset")
+	)
+	if amount == 0 {
+		return errors.New("an amount to stake must be specified")
+	}
+	config, err := App.retiClient.GetValidatorConfig(validatorID)
+	if err != nil {
+		return fmt.Errorf("get validator config err:%w", err)
+	}
+	if config.SunsettingOn != 0 {
+		return fmt.Errorf("validator id:%d is sunsetting, and can't be staked to", validatorID)
+	}
+	if config.EntryGatingType != reti.GatingTypeNone && gatingAsset == 0 {
+		if config.EntryGatingType != reti.GatingTypeAssetId {
+			return fmt.Errorf("validator id:%d gates entry, so the qualifying asset (or nfd) must be specified", validatorID)
+		}
+		// we can find which of the gating assets the staker holds ourselves
+		for _, assetID := range config.EntryGatingAssets {
+			if assetID == 0 {
+				continue
+			}
+			holding, err := App.algoClient.AccountAssetInformation(staker.String(), assetID).Do(ctx)
+			if err == nil && holding.AssetHolding.Amount > 0 {
+				gatingAsset = assetID
+				break
+			}
+		}
+		if gatingAsset == 0 {
+			return fmt.Errorf("staker doesn't hold any of the assets validator id:%d requires", validatorID)
+		}
+	}
+
+	// make sure there's a pool with room for the stake first - the error from the contract is far clearer this way
+	poolKey, err := App.retiClient.FindPoolForStaker(validatorID, staker, amount)
+	if err != nil {
+		return fmt.Errorf("unable to find pool for stake, err:%w", err)
+	}
+	misc.Infof(App.logger, "staking %s to validator:%d, pool:%d (app id:%d)", algo.FormattedAlgoAmount(amount), validatorID, poolKey.PoolId, poolKey.PoolAppId)
+
+	poolKey, err = App.retiClient.AddStake(validatorID, staker, amount, gatingAsset)
+	if err != nil {
+		return err
+	}
+	misc.Infof(App.logger, "stake added to validator:%d, pool:%d (app id:%d)", validatorID, poolKey.PoolId, poolKey.PoolAppId)
+	return nil
+}
+
+func StakerRemove(ctx context.Context, command *cli.Command) error {
+	staker, err := stakerAccount(command)
+	if err != nil {
+		return err
+	}
+	poolKey, err := findStakedPool(staker, command.Uint("pool"))
+	if err != nil {
+		return err
+	}
+	amount := algo.AlgosToMicroAlgos(command.Float("amount"))
+	stakerInfo, err := App.retiClient.GetStakerInfo(poolKey.PoolAppId, staker)
+	if err != nil {
+		return err
+	}
+	if amount > stakerInfo.Balance {
+		return fmt.Errorf("amount:%s is more than staked balance:%s", algo.FormattedAlgoAmount(amount), algo.FormattedAlgoAmount(stakerInfo.Balance))
+	}
+
+	err = App.retiClient.RemoveStake(*poolKey, staker, staker, amount)
+	if err != nil {
+		return err
+	}
+	if amount == 0 {
+		amount = stakerInfo.Balance
+	}
+	misc.Infof(App.logger, "removed %s stake from pool app id:%d", algo.FormattedAlgoAmount(amount), poolKey.PoolAppId)
+	return nil
+}
+
+func StakerClaimTokens(ctx context.Context, command *cli.Command) error {
+	staker, err := stakerAccount(command)
+	if err != nil {
+		return err
+	}
+	var poolKeys []*reti.ValidatorPoolKey
+	if command.IsSet("pool") {
+		poolKey, err := findStakedPool(staker, command.Uint("pool"))
+		if err != nil {
+			return err
+		}
+		poolKeys = append(poolKeys, poolKey)
+	} else if poolKeys, err = App.retiClient.GetStakedPoolsForAccount(staker); err != nil {
+		return err
+	}
+
+	var claimed int
+	for _, poolKey := range poolKeys {
+		stakerInfo, err := App.retiClient.GetStakerInfo(poolKey.PoolAppId, staker)
+		if err != nil {
+			return err
+		}
+		if stakerInfo.RewardTokenBalance == 0 {
+			continue
+		}
+		if err = App.retiClient.ClaimTokens(*poolKey, staker); err != nil {
+			return fmt.Errorf("error claiming tokens from pool app id:%d, err:%w", poolKey.PoolAppId, err)
+		}
+		misc.Infof(App.logger, "claimed %d reward tokens from pool app id:%d", stakerInfo.RewardTokenBalance, poolKey.PoolAppId)
+		claimed++
+	}
+	if claimed == 0 {
+		misc.Infof(App.logger, "no reward tokens to claim")
+	}
+	return nil
+}
+
+func StakerInfo(ctx context.Context, command *cli.Command) error {
+	staker, err := types.DecodeAddress(command.String("account"))
+	if err != nil {
+		return err
+	}
+	poolKeys, err := App.retiClient.GetStakedPoolsForAccount(staker)
+	if err != nil {
+		return err
+	}
+	if len(poolKeys) == 0 {
+		misc.Infof(App.logger, "account:%s isn't staked in any pools", staker)
+		return nil
+	}
+
+	out := new(strings.Builder)
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Validator ID\tPool ID\tApp ID\tStaked\tTotal Rewarded\tRwd Tokens\tEntry Round\t")
+	var totalStaked, totalRewarded uint64
+	for _, key := range poolKeys {
+		stakerInfo, err := App.retiClient.GetStakerInfo(key.PoolAppId, staker)
+		if err != nil {
+			return fmt.Errorf("unable to get staker info from pool app id:%d, err:%w", key.PoolAppId, err)
+		}
+		totalStaked += stakerInfo.Balance
+		totalRewarded += stakerInfo.TotalRewarded
+		fmt.Fprintf(tw, "%d\t%d\t%d\t%s\t%s\t%d\t%d\t\n", key.ID, key.PoolId, key.PoolAppId,
+			algo.FormattedAlgoAmount(stakerInfo.Balance), algo.FormattedAlgoAmount(stakerInfo.TotalRewarded),
+			stakerInfo.RewardTokenBalance, stakerInfo.EntryRound)
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t\t%s\t%s\t\t\t\n", algo.FormattedAlgoAmount(totalStaked), algo.FormattedAlgoAmount(totalRewarded))
+	tw.Flush()
+	fmt.Print(out.String())
+	return nil
+}
+
+// findStakedPool returns the key of the pool (by app id) the staker is staked in
+func findStakedPool(staker types.Address, poolAppID uint64) (*reti.ValidatorPoolKey, error) {
+	poolKeys, err := App.retiClient.GetStakedPoolsForAccount(staker)
+	if err != nil {
+		return nil, err
+	}
+	for _, poolKey := range poolKeys {
+		if poolKey.PoolAppId == poolAppID {
+			return poolKey, nil
+		}
+	}
+	return nil, fmt.Errorf("staker:%s isn't staked in pool app id:%d", staker, poolAppID)
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+// maxRecentEvictions is how many of the most recent eviction actions are kept for the status api
+const maxRecentEvictions = 100
+
+// epochResult is the outcome of the most recent epoch update attempt for a pool
+type epochResult struct {
+	Round   uint64    `json:"round"`
+	Time    time.Time `json:"time"`
+	Skipped bool      `json:"skipped,omitempty"` // epoch update had already been done for this epoch
+	Error   string    `json:"error,omitempty"`
+}
+
+// evictionAction is a single attempted removal of an ineligible staker
+type evictionAction struct {
+	Time      time.Time `json:"time"`
+	Staker    string    `json:"staker"`
+	PoolId    uint64    `json:"poolId"`
+	PoolAppId uint64    `json:"poolAppId"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type statusResponse struct {
+	Version          string        `json:"version"`
+	ValidatorID      uint64        `json:"validatorId"`
+	NodeNum          uint64        `json:"nodeNum"`
+	Active           bool          `json:"active"`
+	CurrentRound     uint64        `json:"currentRound"`
+	AvgBlockTime     time.Duration `json:"avgBlockTimeNs"`
+	EpochRoundLength uint64        `json:"epochRoundLength"`
+	NextEpochRound   uint64        `json:"nextEpochRound"`
+	Manager          accountStatus `json:"manager"`
+}
+
+type accountStatus struct {
+	Address   string `json:"address"`
+	Balance   uint64 `json:"balance"`
+	Spendable uint64 `json:"spendable"`
+}
+
+type poolStatus struct {
+	PoolId          uint64       `json:"poolId"`
+	PoolAppId       uint64       `json:"poolAppId"`
+	Address         string       `json:"address"`
+	Online          bool         `json:"online"`
+	TotalStakers    int          `json:"totalStakers"`
+	TotalAlgoStaked uint64       `json:"totalAlgoStaked"`
+	Balance         uint64       `json:"balance"`
+	NextEpochRound  uint64       `json:"nextEpochRound"`
+	LastEpochUpdate *epochResult `json:"lastEpochUpdate,omitempty"`
+	Keys            []keyStatus  `json:"keys"`
+}
+
+type keyStatus struct {
+	Id                  string    `json:"id"`
+	Active              bool      `json:"active"` // key the pool is currently online against
+	FirstValid          uint64    `json:"firstValid"`
+	LastValid           uint64    `json:"lastValid"`
+	EffectiveFirstValid uint64    `json:"effectiveFirstValid"`
+	EffectiveLastValid  uint64    `json:"effectiveLastValid"`
+	EstimatedExpiration time.Time `json:"estimatedExpiration"`
+	LastVote            uint64    `json:"lastVote"`
+	LastProposal        uint64    `json:"lastProposal"`
+}
+
+// registerStatusAPI adds the read-only JSON status endpoints to the daemon's http server
+func (d *Daemon) registerStatusAPI(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/status", d.apiStatus)
+	mux.HandleFunc("GET /api/pools", d.apiPools)
+	mux.HandleFunc("GET /api/evictions", d.apiEvictions)
+}
+
+func (d *Daemon) apiStatus(w http.ResponseWriter, r *http.Request) {
+	info := d.reti.Info()
+	status, err := d.chain.Status(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, err)
+		return
+	}
+	resp := statusResponse{
+		Version:          getVersionInfo(),
+		ValidatorID:      info.Config.ID,
+		NodeNum:          d.reti.NodeNum,
+		Active:           d.isActive(),
+		CurrentRound:     status.LastRound,
+		AvgBlockTime:     d.AverageBlockTime(),
+		EpochRoundLength: uint64(info.Config.EpochRoundLength),
+		Manager:          accountStatus{Address: info.Config.Manager},
+	}
+	d.RLock()
+	resp.NextEpochRound = d.nextEpochRound
+	d.RUnlock()
+
+	acctInfo, err := algo.GetBareAccount(r.Context(), d.chain, info.Config.Manager)
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, err)
+		return
+	}
+	resp.Manager.Balance = acctInfo.Amount
+	if acctInfo.Amount > acctInfo.MinBalance {
+		resp.Manager.Spendable = acctInfo.Amount - acctInfo.MinBalance
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (d *Daemon) apiPools(w http.ResponseWriter, r *http.Request) {
+	pools, err := d.getPoolStatuses(r.Context())
+	if err != nil {
+		writeJSONError(w, http.StatusBadGateway, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pools)
+}
+
+func (d *Daemon) apiEvictions(w http.ResponseWriter, _ *http.Request) {
+	d.RLock()
+	evictions := make([]evictionAction, len(d.evictions))
+	copy(evictions, d.evictions)
+	d.RUnlock()
+	writeJSON(w, http.StatusOK, evictions)
+}
+
+// getPoolStatuses returns the current state of all the pools assigned to this node, along with the participation
+// keys present for each.
+func (d *Daemon) getPoolStatuses(ctx context.Context) ([]poolStatus, error) {
+	info := d.reti.Info()
+	status, err := d.chain.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+	partKeys, err := algo.GetParticipationKeys(ctx, d.chain)
+	if err != nil {
+		return nil, err
+	}
+	avgBlockTime := d.AverageBlockTime()
+	epochRoundLength := uint64(info.Config.EpochRoundLength)
+
+	var pools = []poolStatus{}
+	for i, pool := range info.Pools {
+		poolId := uint64(i + 1)
+		if _, found := info.LocalPools[poolId]; !found {
+			continue
+		}
+		address := crypto.GetApplicationAddress(pool.PoolAppId).String()
+		snapshot, err := d.reti.GetPoolSnapshot(ctx, pool.PoolAppId)
+		if err != nil {
+			return nil, err
+		}
+		acctInfo := snapshot.Account
+		// each pool's next epoch follows its own last payout - but a pool which missed an epoch (or was never paid
+		// out) is next planned for the upcoming epoch, not a round in the past
+		nextEpochRound := max(nextEpoch(snapshot.LastPayout, epochRoundLength), nextEpoch(status.LastRound, epochRoundLength))
+		poolStat := poolStatus{
+			PoolId:          poolId,
+			PoolAppId:       pool.PoolAppId,
+			Address:         address,
+			Online:          acctInfo.Status == OnlineStatus,
+			TotalStakers:    pool.TotalStakers,
+			TotalAlgoStaked: pool.TotalAlgoStaked,
+			Balance:         acctInfo.Amount,
+			NextEpochRound:  nextEpochRound,
+			Keys:            []keyStatus{},
+		}
+		d.RLock()
+		if result, found := d.epochResults[poolId]; found {
+			poolStat.LastEpochUpdate = &result
+		}
+		d.RUnlock()
+
+		for _, key := range partKeys[address] {
+			keyStat := keyStatus{
+				Id:                  key.Id,
+				Active:              poolStat.Online && bytes.Equal(key.Key.SelectionParticipationKey, acctInfo.Participation.SelectionParticipationKey),
+				FirstValid:          key.Key.VoteFirstValid,
+				LastValid:           key.Key.VoteLastValid,
+				EffectiveFirstValid: key.EffectiveFirstValid,
+				EffectiveLastValid:  key.EffectiveLastValid,
+				LastVote:            key.LastVote,
+				LastProposal:        key.LastBlockProposal,
+			}
+			if key.Key.VoteLastValid > status.LastRound {
+				keyStat.EstimatedExpiration = time.Now().Add(time.Duration(key.Key.VoteLastValid-status.LastRound) * avgBlockTime).UTC()
+			} else {
+				keyStat.EstimatedExpiration = time.Now().UTC()
+			}
+			poolStat.Keys = append(poolStat.Keys, keyStat)
+		}
+		pools = append(pools, poolStat)
+	}
+	return pools, nil
+}
+
+func (d *Daemon) setNextEpochRound(round uint64) {
+	d.Lock()
+	defer d.Unlock()
+	d.nextEpochRound = round
+}
+
+func (d *Daemon) recordEpochResult(poolId uint64, result epochResult) {
+	d.Lock()
+	defer d.Unlock()
+	d.epochResults[poolId] = result
+}
+
+func (d *Daemon) recordEviction(action evictionAction) {
+	d.Lock()
+	defer d.Unlock()
+	d.evictions = append(d.evictions, action)
+	if len(d.evictions) > maxRecentEvictions {
+		d.evictions = d.evictions[len(d.evictions)-maxRecentEvictions:]
+	}
+}
+
+func (d *Daemon) setActive(active bool) {
+	d.Lock()
+	defer d.Unlock()
+	if active && !d.active {
+		// state wasn't being refreshed while on standby - give the KeyWatcher until maxStateAge to refetch it
+		d.lastStateLoad = time.Now()
+	}
+	d.active = active
+}
+
+func (d *Daemon) isActive() bool {
+	d.RLock()
+	defer d.RUnlock()
+	return d.active
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, val any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(val); err != nil {
+		misc.Debugf(App.logger, "error writing json response: %v", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, statusCode int, err error) {
+	writeJSON(w, statusCode, map[string]string{"error": err.Error()})
+}
@@ -7,9 +7,11 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/urfave/cli/v3"
 
+	"github.com/TxnLab/reti/internal/lib/algo"
 	"github.com/TxnLab/reti/internal/lib/misc"
 )
 
@@ -21,12 +23,166 @@ func GetDaemonCmdOpts() *cli.Command {
 		Before:  checkConfigured, // make sure validator is already configured
 		Action:  runAsDaemon,
 		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "dry-run",
+				Usage:   "Run all the daemon logic, but only simulate transactions - nothing is signed or sent, and participation keys aren't created or deleted",
+				Sources: cli.EnvVars("RETI_DRY_RUN"),
+			},
 			&cli.IntFlag{
 				Name:     "port",
-				Usage:    "port to expose prometheus /metrics and /ready endpoint",
+				Usage:    "port to expose prometheus /metrics, /live and /ready endpoints, and the /api status endpoints",
 				Value:    6260,
 				Required: false,
 			},
+			&cli.StringFlag{
+				Name:    "lease",
+				Usage:   "Lease type used to coordinate multiple daemons for the same node (none, file, http).  Only the lease holder signs transactions",
+				Value:   "none",
+				Sources: cli.EnvVars("RETI_LEASE"),
+			},
+			&cli.StringFlag{
+				Name:    "leasedir",
+				Usage:   "Directory (shared between replicas) holding lease files when using file leases",
+				Sources: cli.EnvVars("RETI_LEASE_DIR"),
+			},
+			&cli.StringFlag{
+				Name:    "leaseurl",
+				Usage:   "Base url of the lease service when using http leases",
+				Sources: cli.EnvVars("RETI_LEASE_URL"),
+			},
+			&cli.DurationFlag{
+				Name:    "leasettl",
+				Usage:   "How long a lease is valid for without renewal - a standby takes over within this time if the active daemon dies",
+				Value:   30 * time.Second,
+				Sources: cli.EnvVars("RETI_LEASE_TTL"),
+			},
+			&cli.DurationFlag{
+				Name:    "keylength",
+				Usage:   "How long generated participation keys are valid for",
+				Value:   DefaultKeyPolicy.Length,
+				Sources: cli.EnvVars("RETI_KEY_LENGTH"),
+			},
+			&cli.DurationFlag{
+				Name:    "keyrenew",
+				Usage:   "How long before a participation key expires that its replacement is created",
+				Value:   DefaultKeyPolicy.RenewLead,
+				Sources: cli.EnvVars("RETI_KEY_RENEW"),
+			},
+			&cli.UintFlag{
+				Name:    "keydilution",
+				Usage:   "Key dilution for generated participation keys (0 uses the algod default)",
+				Sources: cli.EnvVars("RETI_KEY_DILUTION"),
+			},
+			&cli.IntFlag{
+				Name:    "keymaxpending",
+				Usage:   "Maximum number of not yet valid participation keys allowed per pool",
+				Value:   int64(DefaultKeyPolicy.MaxPending),
+				Sources: cli.EnvVars("RETI_KEY_MAX_PENDING"),
+			},
+			&cli.StringFlag{
+				Name:    "poolkeypolicy",
+				Usage:   "Per-pool key policy overrides, separated by ';', ie: 2:length=30d,renew=2d,dilution=10000;3:length=2h,renew=30m",
+				Sources: cli.EnvVars("RETI_POOL_KEY_POLICY"),
+			},
+			&cli.BoolFlag{
+				Name:    "linknfd",
+				Usage:   "Link this node's pools to the validator's NFD (as verified addresses) when they aren't already.  The NFD owner's keys must be present",
+				Sources: cli.EnvVars("RETI_LINK_NFD"),
+			},
+			&cli.UintFlag{
+				Name:    "readymaxlag",
+				Usage:   "Maximum number of rounds algod can be behind for the daemon to report itself as ready",
+				Value:   DefaultReadyMaxRoundLag,
+				Sources: cli.EnvVars("RETI_READY_MAX_LAG"),
+			},
+			&cli.StringFlag{
+				Name:    "notifywebhook",
+				Usage:   "URL to POST notification events to (as json)",
+				Sources: cli.EnvVars("RETI_NOTIFY_WEBHOOK"),
+			},
+			&cli.StringFlag{
+				Name:    "notifyslack",
+				Usage:   "Slack incoming webhook URL to send notifications to",
+				Sources: cli.EnvVars("RETI_NOTIFY_SLACK"),
+			},
+			&cli.StringFlag{
+				Name:    "notifydiscord",
+				Usage:   "Discord webhook URL to send notifications to",
+				Sources: cli.EnvVars("RETI_NOTIFY_DISCORD"),
+			},
+			&cli.StringFlag{
+				Name:    "notifysmtp",
+				Usage:   "SMTP server (host:port) to send email notifications through",
+				Sources: cli.EnvVars("RETI_NOTIFY_SMTP"),
+			},
+			&cli.StringFlag{
+				Name:    "notifysmtpuser",
+				Usage:   "SMTP username (if the server requires authentication) - the password is read from the RETI_NOTIFY_SMTP_PASSWORD secret",
+				Sources: cli.EnvVars("RETI_NOTIFY_SMTP_USER"),
+			},
+			&cli.StringFlag{
+				Name:    "notifysmtpfrom",
+				Usage:   "From address of email notifications",
+				Sources: cli.EnvVars("RETI_NOTIFY_SMTP_FROM"),
+			},
+			&cli.StringFlag{
+				Name:    "notifysmtpto",
+				Usage:   "Comma separated list of addresses to email notifications to",
+				Sources: cli.EnvVars("RETI_NOTIFY_SMTP_TO"),
+			},
+			&cli.StringFlag{
+				Name:    "notifyseverity",
+				Usage:   "Minimum severity (info, warning, critical) of notifications sent - either for all, or with per-destination overrides, ie: warning,email=critical (destinations are webhook, slack, discord, email)",
+				Value:   "warning",
+				Sources: cli.EnvVars("RETI_NOTIFY_SEVERITY"),
+			},
+			&cli.DurationFlag{
+				Name:    "notifydedup",
+				Usage:   "Repeats of the same notification are only sent once within this period",
+				Value:   1 * time.Hour,
+				Sources: cli.EnvVars("RETI_NOTIFY_DEDUP"),
+			},
+			&cli.DurationFlag{
+				Name:    "notifykeyexpiry",
+				Usage:   "Notify when a pool's participation key expires within this time and no replacement key exists",
+				Value:   DefaultNotifyKeyExpiry,
+				Sources: cli.EnvVars("RETI_NOTIFY_KEY_EXPIRY"),
+			},
+			&cli.FloatFlag{
+				Name:    "notifyminbalance",
+				Usage:   "Notify when the manager account's spendable balance (in ALGO) falls below this",
+				Value:   DefaultNotifyMinBalance,
+				Sources: cli.EnvVars("RETI_NOTIFY_MIN_BALANCE"),
+			},
+			&cli.StringFlag{
+				Name:    "treasury",
+				Usage:   "Account to automatically fund the manager account from when its balance runs low (its key must be available locally).  Requires --history, where top-ups are recorded so the daily cap and interval survive restarts.  Top-ups are disabled if not set",
+				Sources: cli.EnvVars("RETI_TREASURY"),
+			},
+			&cli.FloatFlag{
+				Name:    "topupbelow",
+				Usage:   "Fund the manager account from the treasury when its spendable balance (in ALGO) falls below this",
+				Value:   5,
+				Sources: cli.EnvVars("RETI_TOPUP_BELOW"),
+			},
+			&cli.FloatFlag{
+				Name:    "topupamount",
+				Usage:   "Amount (in ALGO) sent from the treasury for each top-up",
+				Value:   10,
+				Sources: cli.EnvVars("RETI_TOPUP_AMOUNT"),
+			},
+			&cli.FloatFlag{
+				Name:    "topupdailycap",
+				Usage:   "Maximum amount (in ALGO) sent from the treasury within any 24 hour period.  The cap is per node (per history database) - when using --lease, put --history on storage shared between the replicas so a failover can't exceed it",
+				Value:   50,
+				Sources: cli.EnvVars("RETI_TOPUP_DAILY_CAP"),
+			},
+			&cli.DurationFlag{
+				Name:    "topupinterval",
+				Usage:   "Minimum time between top-ups from the treasury",
+				Value:   6 * time.Hour,
+				Sources: cli.EnvVars("RETI_TOPUP_INTERVAL"),
+			},
 		},
 	}
 }
@@ -48,9 +204,88 @@ func runAsDaemon(ctx context.Context, cmd *cli.Command) error {
 		signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
 		errc <- fmt.Errorf("%s", <-c)
 	}()
+	dryRun := cmd.Bool("dry-run")
+	if dryRun {
+		misc.Warnf(App.logger, "[DRY-RUN] transactions will only be simulated - nothing will be signed or sent")
+		App.signer = algo.NewDryRunSigner(App.signer)
+		App.retiClient.EnableDryRun()
+	}
+	var election *leaderElection
+	leaseBackend, err := getLeaseBackend(cmd.String("lease"), cmd.String("leasedir"), cmd.String("leaseurl"))
+	if err != nil {
+		return err
+	}
+	if leaseBackend != nil && dryRun {
+		// a dry-run daemon must never take the lease from the real (signing) daemon
+		misc.Infof(App.logger, "[DRY-RUN] not participating in %s lease election", cmd.String("lease"))
+	} else if leaseBackend != nil {
+		if cmd.Duration("leasettl") < 3*time.Second {
+			return fmt.Errorf("lease ttl must be at least 3 seconds")
+		}
+		election = newLeaderElection(leaseBackend, App.retiClient.ValidatorId, App.retiClient.NodeNum, cmd.Duration("leasettl"))
+	}
+
+	keyPolicies, err := newKeyPolicies(KeyPolicy{
+		Length:     cmd.Duration("keylength"),
+		RenewLead:  cmd.Duration("keyrenew"),
+		Dilution:   cmd.Uint("keydilution"),
+		MaxPending: int(cmd.Int("keymaxpending")),
+	}, cmd.String("poolkeypolicy"))
+	if err != nil {
+		return err
+	}
+
+	eventBus, err := newEventBus(cmd)
+	if err != nil {
+		return err
+	}
+	// give any final notifications (ie: manager changed) a chance to go out before exiting
+	defer eventBus.Close(30 * time.Second)
+
 	ctx, cancel := context.WithCancel(context.Background())
 
-	daemon := newDaemon(int(cmd.Int("port")))
+	daemon := newDaemon(App.chain, App.retiClient, App.signer)
+	daemon.listenPort = int(cmd.Int("port"))
+	daemon.election = election
+	daemon.history = App.history
+	daemon.nfdOnChain, daemon.nfdApi = App.nfdOnChain, App.nfdApi
+	daemon.readyMaxRoundLag = cmd.Uint("readymaxlag")
+	daemon.linkNFD = cmd.Bool("linknfd")
+	daemon.events = eventBus
+	daemon.notifyKeyExpiry = cmd.Duration("notifykeyexpiry")
+	daemon.notifyMinBalance = algo.AlgosToMicroAlgos(cmd.Float("notifyminbalance"))
+	if treasury := cmd.String("treasury"); treasury != "" {
+		topUp := &TopUpConfig{
+			Treasury:    treasury,
+			Below:       algo.AlgosToMicroAlgos(cmd.Float("topupbelow")),
+			Amount:      algo.AlgosToMicroAlgos(cmd.Float("topupamount")),
+			DailyCap:    algo.AlgosToMicroAlgos(cmd.Float("topupdailycap")),
+			MinInterval: cmd.Duration("topupinterval"),
+		}
+		if err = topUp.validate(daemon.signer); err != nil {
+			cancel()
+			return err
+		}
+		if daemon.history == nil {
+			cancel()
+			return fmt.Errorf("--treasury requires --history, so prior top-ups still count toward the daily cap after a restart")
+		}
+		daemon.funds = newManagerFunds(topUp)
+		if err = daemon.funds.loadTopUps(daemon.history); err != nil {
+			cancel()
+			return fmt.Errorf("unable to load prior manager top-ups from history, err:%w", err)
+		}
+		if leaseBackend != nil {
+			misc.Warnf(App.logger, "the top-up daily cap is tracked in the history database:%s - unless it's shared between replicas, each node has its own cap", cmd.String("history"))
+		}
+		misc.Infof(App.logger, "manager top-ups enabled from treasury:%s", treasury)
+	}
+	App.retiClient.AddTxnObserver(daemon.observeTxn)
+	daemon.keyPolicies = keyPolicies
+	misc.Infof(App.logger, "key policy: %s", keyPolicies.Default)
+	for poolId, policy := range keyPolicies.Pools {
+		misc.Infof(App.logger, "key policy for pool %d: %s", poolId, policy)
+	}
 	daemon.start(ctx, &wg, cancel)
 
 	select {
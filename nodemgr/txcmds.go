@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/urfave/cli/v3"
+
+	"github.com/TxnLab/reti/internal/lib/algo"
+	"github.com/TxnLab/reti/internal/lib/misc"
+)
+
+func GetTxCmdOpts() *cli.Command {
+	return &cli.Command{
+		Name:     "tx",
+		Usage:    "Sign and submit transaction groups exported w/ --export-unsigned (ie: for a multisig owner)",
+		Metadata: standaloneCommand,
+		Commands: []*cli.Command{
+			{
+				Name:   "sign",
+				Usage:  "Add the signatures of the --signer/--keystore accounts to the transactions in a file (including partial multisig signatures)",
+				Action: TxSign,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "in",
+						Usage:    "File of the transactions to sign",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "out",
+						Usage: "File to write the signed transactions to - the input file is replaced if not set",
+					},
+					&cli.StringFlag{
+						Name:  "multisig",
+						Usage: "Comma separated (and ordered) member addresses of the multisig account the transactions are from - only needed for the first signer",
+					},
+					&cli.UintFlag{
+						Name:  "threshold",
+						Usage: "Number of signatures the multisig account requires",
+					},
+				},
+			},
+			{
+				Name:   "submit",
+				Usage:  "Merge the signatures of one or more signed copies of a transaction group and send it",
+				Action: TxSubmit,
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:     "in",
+						Usage:    "File(s) of the signed transactions - the signatures in all of them are merged",
+						Required: true,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TxSign(ctx context.Context, command *cli.Command) error {
+	var msig *crypto.MultisigAccount
+	if members := splitList(command.String("multisig")); len(members) > 0 {
+		threshold := command.Uint("threshold")
+		if threshold == 0 || threshold > uint64(len(members)) {
+			return fmt.Errorf("--threshold must be between 1 and %d (the number of multisig members)", len(members))
+		}
+		var addrs []types.Address
+		for _, member := range members {
+			addr, err := types.DecodeAddress(member)
+			if err != nil {
+				return fmt.Errorf("invalid multisig member:%s, err:%w", member, err)
+			}
+			addrs = append(addrs, addr)
+		}
+		account, err := crypto.MultisigAccountWithParams(1, uint8(threshold), addrs)
+		if err != nil {
+			return err
+		}
+		msig = &account
+	}
+	stxns, err := readTxnFile(command.String("in"))
+	if err != nil {
+		return err
+	}
+
+	// client initialization is skipped for standalone commands (transactions can be signed on an offline host), so
+	// we set up our own signer
+	if err = App.initSigner(ctx, command, algo.GetNetworkConfig(command.String("network"))); err != nil {
+		return err
+	}
+	numSigned, err := algo.SignGroup(ctx, App.signer, stxns, msig)
+	if err != nil {
+		return err
+	}
+	outFile := command.String("out")
+	if outFile == "" {
+		outFile = command.String("in")
+	}
+	if err = os.WriteFile(outFile, algo.EncodeSignedGroup(stxns), 0o644); err != nil {
+		return err
+	}
+	printTxnStatus(stxns)
+	fmt.Printf("Added %d signature(s), written to %s\n", numSigned, outFile)
+	return nil
+}
+
+func TxSubmit(ctx context.Context, command *cli.Command) error {
+	var groups [][]types.SignedTxn
+	for _, inFile := range command.StringSlice("in") {
+		stxns, err := readTxnFile(inFile)
+		if err != nil {
+			return err
+		}
+		groups = append(groups, stxns)
+	}
+	stxns, err := algo.MergeSignedGroups(groups...)
+	if err != nil {
+		return err
+	}
+	printTxnStatus(stxns)
+	for i, stxn := range stxns {
+		if status, signed := algo.SignatureStatus(stxn); !signed {
+			return fmt.Errorf("transaction %d isn't fully signed (%s)", i, status)
+		}
+	}
+
+	// client initialization is skipped for standalone commands (submitting needs no keys), so we set up our own
+	// algod client
+	network := command.String("network")
+	misc.LoadEnvForNetwork(App.logger, network)
+	algoClient, err := algo.GetAlgoClient(App.logger, algo.GetNetworkConfig(network))
+	if err != nil {
+		return err
+	}
+	chain := algo.NewAlgodChain(algoClient)
+	status, err := chain.Status(ctx)
+	if err != nil {
+		return err
+	}
+	if lastValid := stxns[0].Txn.LastValid; uint64(lastValid) < status.LastRound {
+		return fmt.Errorf("transactions expired at round %d (current round:%d) - they have to be exported and signed again", lastValid, status.LastRound)
+	}
+	txid, err := chain.SendRawTransaction(ctx, algo.EncodeSignedGroup(stxns))
+	if err != nil {
+		return err
+	}
+	confirmed, err := algo.WaitForConfirmation(ctx, chain, txid, 4)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transaction group confirmed in round %d\n", confirmed.ConfirmedRound)
+	return nil
+}
+
+func readTxnFile(path string) ([]types.SignedTxn, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	stxns, err := algo.DecodeSignedGroup(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(stxns) > 1 {
+		for _, stxn := range stxns {
+			if stxn.Txn.Group != stxns[0].Txn.Group || stxn.Txn.Group == (types.Digest{}) {
+				return nil, errors.New(path + ": transactions aren't a single group")
+			}
+		}
+	}
+	return stxns, nil
+}
+
+func printTxnStatus(stxns []types.SignedTxn) {
+	for i, stxn := range stxns {
+		status, _ := algo.SignatureStatus(stxn)
+		detail := fmt.Sprintf("type:%s, sender:%s", stxn.Txn.Type, stxn.Txn.Sender)
+		if stxn.Txn.Type == types.ApplicationCallTx {
+			detail += fmt.Sprintf(", app id:%d", stxn.Txn.ApplicationID)
+		}
+		fmt.Printf("%d: %s %s - %s\n", i, crypto.GetTxID(stxn.Txn), detail, status)
+	}
+}
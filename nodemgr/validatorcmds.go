@@ -7,10 +7,14 @@ import (
 	"log/slog"
 	"os"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 	"github.com/mailgun/holster/v4/syncutil"
 	"github.com/manifoldco/promptui"
@@ -67,6 +71,7 @@ func GetValidatorCmdOpts() *cli.Command {
 								Usage:    "The algorand address to be the new manager address.",
 								Required: true,
 							},
+							exportUnsignedFlag(),
 						},
 						Action: ChangeManager,
 					},
@@ -79,9 +84,74 @@ func GetValidatorCmdOpts() *cli.Command {
 								Usage:    "The algorand address to send commissions to.",
 								Required: true,
 							},
+							exportUnsignedFlag(),
 						},
 						Action: ChangeCommission,
 					},
+					{
+						Name:  "sunset",
+						Usage: "Set (or clear) the date the validator is sunsetting on, and optionally the validator stakers should move to",
+						Flags: []cli.Flag{
+							&cli.TimestampFlag{
+								Name:  "on",
+								Usage: "The date/time the validator sunsets on, ie: 2025-06-30 or 2025-06-30T12:00:00Z",
+								Config: cli.TimestampConfig{
+									Layouts: []string{time.DateOnly, time.RFC3339},
+								},
+							},
+							&cli.UintFlag{
+								Name:  "to",
+								Usage: "The id of the validator stakers are being moved to (if any)",
+							},
+							&cli.BoolFlag{
+								Name:  "clear",
+								Usage: "Clear any sunset information",
+							},
+							exportUnsignedFlag(),
+						},
+						Action: ChangeSunset,
+					},
+					{
+						Name:  "nfd",
+						Usage: "Change the NFD associated with the validator",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "name",
+								Usage:    "The NFD name (ie: myvalidator.algo) - it must be owned by the validator owner",
+								Required: true,
+							},
+							exportUnsignedFlag(),
+						},
+						Action: ChangeNFD,
+					},
+					{
+						Name:  "rewards",
+						Usage: "Change the entry gating and reward token payout - anything not specified is left as currently configured",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "gating",
+								Usage: "Entry gating type: none, creator, asset, nfd-creator, nfd-segment",
+							},
+							&cli.StringFlag{
+								Name:  "gating-address",
+								Usage: "Creator address of assets stakers must hold (for 'creator' gating)",
+							},
+							&cli.UintSliceFlag{
+								Name:  "gating-assets",
+								Usage: "Asset ids stakers must hold (up to 4, for 'asset' gating), or the NFD app id (for nfd gating)",
+							},
+							&cli.UintFlag{
+								Name:  "gating-min-balance",
+								Usage: "Minimum balance (in base units) of gating assets stakers must hold",
+							},
+							&cli.UintFlag{
+								Name:  "reward-per-payout",
+								Usage: "Amount of the reward token (in base units) paid out each epoch",
+							},
+							exportUnsignedFlag(),
+						},
+						Action: ChangeRewards,
+					},
 				},
 			},
 			{
@@ -115,6 +185,7 @@ func GetValidatorCmdOpts() *cli.Command {
 						Usage:    "The address to send the excess reward tokens to",
 						Required: true,
 					},
+					exportUnsignedFlag(),
 				},
 				Action: emptyTokenRewards,
 			},
@@ -122,6 +193,15 @@ func GetValidatorCmdOpts() *cli.Command {
 	}
 }
 
+// exportUnsignedFlag is the flag of owner-level commands to export the transactions unsigned rather than sending
+// them, so they can be signed elsewhere (ie: by the members of a cold multisig owner)
+func exportUnsignedFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "export-unsigned",
+		Usage: "Write the (unsigned) transaction group to this file rather than sending it - sign it with 'tx sign' and send it with 'tx submit'",
+	}
+}
+
 func InitValidator(ctx context.Context, cmd *cli.Command) error {
 	if App.retiClient.IsConfigured() {
 		result, _ := yesNo("A validator configuration already appears to exist, do you REALLY want to add an entirely new validator configuration")
@@ -188,11 +268,10 @@ func ChangeManager(ctx context.Context, command *cli.Command) error {
 	}
 	var info = App.retiClient.Info()
 
-	signer, err := App.signer.FindFirstSigner([]string{info.Config.Owner, info.Config.Manager})
+	signerAddr, err := ownerSigner(command, info)
 	if err != nil {
-		return fmt.Errorf("neither owner or manager address for your validator has local keys present")
+		return err
 	}
-	signerAddr, _ := types.DecodeAddress(signer)
 
 	managerAddress, err := types.DecodeAddress(command.String("address"))
 	if err != nil {
@@ -212,11 +291,10 @@ func ChangeCommission(ctx context.Context, command *cli.Command) error {
 	}
 	var info = App.retiClient.Info()
 
-	signer, err := App.signer.FindFirstSigner([]string{info.Config.Owner, info.Config.Manager})
+	signerAddr, err := ownerSigner(command, info)
 	if err != nil {
-		return fmt.Errorf("neither owner or manager address for your validator has local keys present")
+		return err
 	}
-	signerAddr, _ := types.DecodeAddress(signer)
 
 	commissionAddress, err := types.DecodeAddress(command.String("address"))
 	if err != nil {
@@ -230,6 +308,273 @@ func ChangeCommission(ctx context.Context, command *cli.Command) error {
 	return App.retiClient.LoadState(ctx)
 }
 
+func ChangeSunset(ctx context.Context, command *cli.Command) error {
+	if !App.retiClient.IsConfigured() {
+		return fmt.Errorf("validator not configured")
+	}
+	var (
+		info         = App.retiClient.Info()
+		sunsettingOn uint64
+		sunsettingTo uint64
+	)
+	signerAddr, err := ownerSigner(command, info)
+	if err != nil {
+		return err
+	}
+
+	if !command.Bool("clear") {
+		if !command.IsSet("on") {
+			return fmt.Errorf("the sunset date must be specified (or --clear to clear it)")
+		}
+		sunsetTime := command.Timestamp("on")
+		if !sunsetTime.After(time.Now()) {
+			return fmt.Errorf("sunset date:%s must be in the future", sunsetTime.Format(time.RFC3339))
+		}
+		sunsettingOn = uint64(sunsetTime.Unix())
+		sunsettingTo = command.Uint("to")
+		if sunsettingTo != 0 {
+			if sunsettingTo == info.Config.ID {
+				return fmt.Errorf("validator can't sunset to itself")
+			}
+			numValidators, err := App.retiClient.GetNumValidators()
+			if err != nil {
+				return err
+			}
+			if sunsettingTo > numValidators {
+				return fmt.Errorf("validator id:%d is invalid, maximum is %d", sunsettingTo, numValidators)
+			}
+			toConfig, err := App.retiClient.GetValidatorConfig(sunsettingTo)
+			if err != nil {
+				return err
+			}
+			if toConfig.SunsettingOn != 0 {
+				return fmt.Errorf("validator id:%d is itself sunsetting", sunsettingTo)
+			}
+			if err = checkAcceptsStake(info, sunsettingTo, toConfig); err != nil {
+				return err
+			}
+		}
+	} else if command.IsSet("on") || command.IsSet("to") {
+		return fmt.Errorf("--clear can't be combined with --on or --to")
+	}
+
+	err = App.retiClient.ChangeValidatorSunsetInfo(info.Config.ID, signerAddr, sunsettingOn, sunsettingTo)
+	if err != nil {
+		return err
+	}
+	return App.retiClient.LoadState(ctx)
+}
+
+func ChangeNFD(ctx context.Context, command *cli.Command) error {
+	if !App.retiClient.IsConfigured() {
+		return fmt.Errorf("validator not configured")
+	}
+	var (
+		info    = App.retiClient.Info()
+		nfdName = strings.ToLower(command.String("name"))
+	)
+	signerAddr, err := ownerSigner(command, info)
+	if err != nil {
+		return err
+	}
+	if err = IsNFDNameValid(nfdName); err != nil {
+		return err
+	}
+	nfdAppID, err := App.nfdOnChain.FindByName(ctx, nfdName)
+	if err != nil {
+		return fmt.Errorf("unable to find nfd:%s, err:%w", nfdName, err)
+	}
+	if nfdAppID == info.Config.NFDForInfo {
+		return fmt.Errorf("nfd:%s is already the validator's nfd", nfdName)
+	}
+	nfd, err := App.nfdOnChain.GetNFD(ctx, nfdAppID, false)
+	if err != nil {
+		return err
+	}
+	// the contract requires the validator owner to own the nfd
+	if nfd.Internal["owner"] != info.Config.Owner {
+		return fmt.Errorf("nfd owner:%s is not the validator owner:%s", nfd.Internal["owner"], info.Config.Owner)
+	}
+
+	err = App.retiClient.ChangeValidatorNFD(info.Config.ID, signerAddr, nfdAppID, nfdName)
+	if err != nil {
+		return err
+	}
+	return App.retiClient.LoadState(ctx)
+}
+
+var gatingTypes = map[string]uint8{
+	"none":        reti.GatingTypeNone,
+	"creator":     reti.GatingTypeAssetsCreatedBy,
+	"asset":       reti.GatingTypeAssetId,
+	"nfd-creator": reti.GatingTypeCreatedByNFDAddresses,
+	"nfd-segment": reti.GatingTypeSegmentOfNFD,
+}
+
+func ChangeRewards(ctx context.Context, command *cli.Command) error {
+	if !App.retiClient.IsConfigured() {
+		return fmt.Errorf("validator not configured")
+	}
+	var info = App.retiClient.Info()
+	signerAddr, err := ownerSigner(command, info)
+	if err != nil {
+		return err
+	}
+
+	// start from the current configuration
+	var (
+		gatingType       = info.Config.EntryGatingType
+		gatingAddress, _ = types.DecodeAddress(info.Config.EntryGatingAddress)
+		gatingAssets     = info.Config.EntryGatingAssets
+		gatingMinBalance = info.Config.GatingAssetMinBalance
+		rewardPerPayout  = info.Config.RewardPerPayout
+	)
+	if command.IsSet("gating") {
+		var found bool
+		if gatingType, found = gatingTypes[command.String("gating")]; !found {
+			return fmt.Errorf("unknown gating type:%s", command.String("gating"))
+		}
+		if gatingType != info.Config.EntryGatingType {
+			// the gating criteria of the old type don't apply to the new one
+			gatingAddress, gatingAssets, gatingMinBalance = types.ZeroAddress, nil, 0
+		}
+	}
+	if command.IsSet("gating-address") {
+		if gatingAddress, err = types.DecodeAddress(command.String("gating-address")); err != nil {
+			return err
+		}
+	}
+	if command.IsSet("gating-assets") {
+		gatingAssets = command.UintSlice("gating-assets")
+	}
+	if command.IsSet("gating-min-balance") {
+		gatingMinBalance = command.Uint("gating-min-balance")
+	}
+	if command.IsSet("reward-per-payout") {
+		rewardPerPayout = command.Uint("reward-per-payout")
+	}
+
+	// validate the combination - the contract only checks some of this
+	if len(gatingAssets) > 4 {
+		return fmt.Errorf("at most 4 gating assets can be specified")
+	}
+	hasGatingAssets := slices.ContainsFunc(gatingAssets, func(asset uint64) bool { return asset != 0 })
+	switch gatingType {
+	case reti.GatingTypeNone:
+		if gatingAddress != types.ZeroAddress || hasGatingAssets {
+			return fmt.Errorf("gating address/assets can't be specified without a gating type")
+		}
+	case reti.GatingTypeAssetsCreatedBy:
+		if gatingAddress == types.ZeroAddress {
+			return fmt.Errorf("creator gating requires a gating address")
+		}
+	case reti.GatingTypeAssetId:
+		if !hasGatingAssets {
+			return fmt.Errorf("asset gating requires at least one gating asset")
+		}
+	case reti.GatingTypeCreatedByNFDAddresses, reti.GatingTypeSegmentOfNFD:
+		if len(gatingAssets) == 0 || gatingAssets[0] == 0 {
+			return fmt.Errorf("nfd gating requires the nfd app id as the gating asset")
+		}
+		if _, err = App.nfdOnChain.GetNFD(ctx, gatingAssets[0], false); err != nil {
+			return fmt.Errorf("gating nfd app id:%d isn't a valid nfd, err:%w", gatingAssets[0], err)
+		}
+	}
+	if gatingType == reti.GatingTypeSegmentOfNFD {
+		// the registry only accepts reward info changes w/ the gating types before it
+		return fmt.Errorf("the validator registry doesn't accept nfd segment gating when changing reward info - it can only be set when the validator is added")
+	}
+	if gatingMinBalance != 0 && gatingType != reti.GatingTypeAssetsCreatedBy && gatingType != reti.GatingTypeAssetId &&
+		gatingType != reti.GatingTypeCreatedByNFDAddresses {
+		return fmt.Errorf("gating min balance only applies to asset based gating")
+	}
+	if gatingType == reti.GatingTypeAssetsCreatedBy {
+		creator, err := App.chain.AccountInformation(ctx, gatingAddress.String())
+		if err != nil {
+			return err
+		}
+		if len(creator.CreatedAssets) == 0 {
+			return fmt.Errorf("gating address:%s hasn't created any assets, so no one could stake", gatingAddress)
+		}
+	}
+	if rewardPerPayout != 0 && info.Config.RewardTokenId == 0 {
+		return fmt.Errorf("validator has no reward token, so reward per payout must be 0")
+	}
+	if rewardPerPayout != 0 && len(info.Pools) > 0 {
+		// reward tokens are paid out from pool 1
+		pool1, err := App.chain.AccountInformation(ctx, crypto.GetApplicationAddress(info.Pools[0].PoolAppId).String())
+		if err != nil {
+			return err
+		}
+		idx := slices.IndexFunc(pool1.Assets, func(holding models.AssetHolding) bool { return holding.AssetId == info.Config.RewardTokenId })
+		if idx == -1 || pool1.Assets[idx].Amount < rewardPerPayout {
+			fmt.Printf("WARNING: pool 1 holds less than the reward per payout of reward token:%d - no token rewards are paid until it's funded\n", info.Config.RewardTokenId)
+		}
+	}
+
+	err = App.retiClient.ChangeValidatorRewardInfo(info.Config.ID, signerAddr, gatingType, gatingAddress, gatingAssets, gatingMinBalance, rewardPerPayout)
+	if err != nil {
+		return err
+	}
+	return App.retiClient.LoadState(ctx)
+}
+
+// checkAcceptsStake returns an error if the validator with id toID (w/ config toConfig) can't accept the stake
+// moving to it from our validator - it has no pools or has reached the protocol's maximum stake.
+func checkAcceptsStake(info reti.ValidatorInfo, toID uint64, toConfig *reti.ValidatorConfig) error {
+	constraints, err := App.retiClient.GetProtocolConstraints()
+	if err != nil {
+		return err
+	}
+	toState, err := App.retiClient.GetValidatorState(toID)
+	if err != nil {
+		return err
+	}
+	if toState.NumPools == 0 {
+		return fmt.Errorf("validator id:%d has no pools, so can't accept stake", toID)
+	}
+	if toState.TotalAlgoStaked >= constraints.MaxAlgoPerValidator {
+		return fmt.Errorf("validator id:%d is at the maximum stake per validator of %s ALGO, so can't accept stake", toID, algo.FormattedAlgoAmount(constraints.MaxAlgoPerValidator))
+	}
+	var ourStaked uint64
+	for _, pool := range info.Pools {
+		ourStaked += pool.TotalAlgoStaked
+	}
+	if toState.TotalAlgoStaked+ourStaked >= constraints.MaxAlgoPerValidator {
+		fmt.Printf("WARNING: validator id:%d can only accept %s ALGO more stake (of our %s ALGO staked)\n", toID,
+			algo.FormattedAlgoAmount(constraints.MaxAlgoPerValidator-toState.TotalAlgoStaked), algo.FormattedAlgoAmount(ourStaked))
+	}
+	if toConfig.EntryGatingType != reti.GatingTypeNone {
+		fmt.Printf("WARNING: validator id:%d has entry gating - stakers not meeting it won't be able to move to it\n", toID)
+	}
+	return nil
+}
+
+// ownerSigner returns the validator owner, which has to sign most configuration changes
+func ownerSigner(command *cli.Command, info reti.ValidatorInfo) (types.Address, error) {
+	signerAddr, err := validatorSigner(command, info, info.Config.Owner)
+	if err != nil {
+		return types.Address{}, fmt.Errorf("owner address for your validator doesn't have local keys present")
+	}
+	return signerAddr, nil
+}
+
+// validatorSigner returns the first of addresses we can sign with.  If the command is exporting its transactions
+// unsigned (--export-unsigned), the client is put into export mode and the owner is treated as signed for
+// externally, so its keys needn't be present.
+func validatorSigner(command *cli.Command, info reti.ValidatorInfo, addresses ...string) (types.Address, error) {
+	signer := App.signer
+	if exportPath := command.String("export-unsigned"); exportPath != "" {
+		signer = algo.NewExternalSigner(signer, info.Config.Owner)
+		App.retiClient.ExportUnsigned(exportPath)
+	}
+	address, err := signer.FindFirstSigner(addresses)
+	if err != nil {
+		return types.Address{}, err
+	}
+	return types.DecodeAddress(address)
+}
+
 func DefineValidator() error {
 	var (
 		err      error
@@ -452,16 +797,15 @@ func refundAllStakers(ctx context.Context, command *cli.Command) error {
 }
 
 func emptyTokenRewards(ctx context.Context, command *cli.Command) error {
-	signer, err := App.signer.FindFirstSigner([]string{App.retiClient.Info().Config.Owner})
+	info := App.retiClient.Info()
+	signerAddr, err := ownerSigner(command, info)
 	if err != nil {
-		return fmt.Errorf("owner address for your validator doesn't have local keys present")
+		return err
 	}
 	receiverAddr, err := types.DecodeAddress(command.String("account"))
 	if err != nil {
 		return err
 	}
-	signerAddr, _ := types.DecodeAddress(signer)
-	info := App.retiClient.Info()
 
 	err = App.retiClient.EmptyTokenRewards(info.Config.ID, signerAddr, receiverAddr)
 	if err != nil {